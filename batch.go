@@ -0,0 +1,176 @@
+package geonames
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultConcurrency is the number of queries a batch
+// runs at the same time unless told otherwise.
+const defaultConcurrency = 4
+
+// BatchOptions configures a batch run.
+type BatchOptions struct {
+	// Concurrency is the maximum number of queries in flight.
+	// Zero means 4.
+	Concurrency int
+	// Progress, if set, is called after each query completes with the
+	// number of completed queries and the total, which is -1 when
+	// the queries come from a channel. Calls are not concurrent.
+	Progress func(done, total int)
+}
+
+func (o BatchOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return defaultConcurrency
+	}
+	return o.Concurrency
+}
+
+// BatchResult holds the outcome of a single query in a batch.
+type BatchResult[Q, R any] struct {
+	// Index is the position of the query in the input.
+	Index int
+	Query Q
+	Value R
+	Err   error
+}
+
+// Batch runs fn for every query with bounded concurrency and returns
+// the results in input order. A query that fails does not stop the
+// others; its error is reported in its result. Queries not yet started
+// when ctx is done fail with the context's error.
+//
+// The client's rate limiter and retry policy apply to each call, so
+// fn is typically a Client method such as GetPostCode.
+func Batch[Q, R any](ctx context.Context, queries []Q, fn func(context.Context, Q) (R, error), opts BatchOptions) []BatchResult[Q, R] {
+	results := make([]BatchResult[Q, R], len(queries))
+	indexes := make(chan int)
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		done int
+	)
+	for range min(opts.concurrency(), len(queries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = run(ctx, i, queries[i], fn)
+				if opts.Progress != nil {
+					mu.Lock()
+					done++
+					opts.Progress(done, len(queries))
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range queries {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
+
+// BatchStream runs fn for every query received from queries with
+// bounded concurrency and sends the results, in input order, on the
+// returned channel. The channel is closed once queries is closed and
+// all results are sent, or once ctx is done. The caller must drain it.
+func BatchStream[Q, R any](ctx context.Context, queries <-chan Q, fn func(context.Context, Q) (R, error), opts BatchOptions) <-chan BatchResult[Q, R] {
+	n := opts.concurrency()
+	out := make(chan BatchResult[Q, R])
+	// pending holds one future per started query in input order,
+	// so that results can be collected in order as they complete.
+	pending := make(chan chan BatchResult[Q, R], n)
+	sem := make(chan struct{}, n)
+
+	go func() {
+		defer close(pending)
+		for i := 0; ; i++ {
+			var q Q
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return
+			case q, ok = <-queries:
+				if !ok {
+					return
+				}
+			}
+			f := make(chan BatchResult[Q, R], 1)
+			pending <- f
+			sem <- struct{}{}
+			go func() {
+				defer func() { <-sem }()
+				f <- run(ctx, i, q, fn)
+			}()
+		}
+	}()
+
+	go func() {
+		defer close(out)
+		done := 0
+		for f := range pending {
+			r := <-f
+			done++
+			if opts.Progress != nil {
+				opts.Progress(done, -1)
+			}
+			out <- r
+		}
+	}()
+	return out
+}
+
+func run[Q, R any](ctx context.Context, i int, q Q, fn func(context.Context, Q) (R, error)) BatchResult[Q, R] {
+	r := BatchResult[Q, R]{Index: i, Query: q}
+	if err := ctx.Err(); err != nil {
+		r.Err = err
+		return r
+	}
+	r.Value, r.Err = fn(ctx, q)
+	return r
+}
+
+// PostalQuery is a place name and country code
+// to look up with GetPostCode.
+type PostalQuery struct {
+	Place   string
+	Country string
+}
+
+// BatchPostCodes looks up postal codes for all queries.
+func (c Client) BatchPostCodes(ctx context.Context, queries []PostalQuery, opts BatchOptions) []BatchResult[PostalQuery, []PostalCode] {
+	return Batch(ctx, queries, func(ctx context.Context, q PostalQuery) ([]PostalCode, error) {
+		return c.GetPostCode(ctx, q.Place, q.Country)
+	}, opts)
+}
+
+// PlaceQuery is a place name, country code and
+// result limit to look up with GetPlace.
+type PlaceQuery struct {
+	Name       string
+	Country    string
+	MaxResults int
+}
+
+// BatchPlaces looks up Wikipedia geonames for all queries.
+func (c Client) BatchPlaces(ctx context.Context, queries []PlaceQuery, opts BatchOptions) []BatchResult[PlaceQuery, []Geoname] {
+	return Batch(ctx, queries, func(ctx context.Context, q PlaceQuery) ([]Geoname, error) {
+		return c.GetPlace(ctx, q.Name, q.Country, q.MaxResults)
+	}, opts)
+}
+
+// BatchElevations looks up the elevation at all positions.
+func (c *Client) BatchElevations(ctx context.Context, positions []Position, opts BatchOptions) []BatchResult[Position, Elevation] {
+	return Batch(ctx, positions, c.Elevation, opts)
+}
+
+// BatchFindNearby looks up the places near all positions.
+func (c Client) BatchFindNearby(ctx context.Context, positions []Position, q NearbyQuery, opts BatchOptions) []BatchResult[Position, []Place] {
+	return Batch(ctx, positions, func(ctx context.Context, pos Position) ([]Place, error) {
+		return c.FindNearby(ctx, pos, q)
+	}, opts)
+}
@@ -0,0 +1,167 @@
+package geonames_test
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qba73/geonames"
+)
+
+// slowSquare squares n after a short random delay, failing for negative n.
+// It records the highest number of concurrent calls in maxInFlight.
+func slowSquare(inFlight, maxInFlight *atomic.Int32) func(context.Context, int) (int, error) {
+	return func(ctx context.Context, n int) (int, error) {
+		cur := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			m := maxInFlight.Load()
+			if cur <= m || maxInFlight.CompareAndSwap(m, cur) {
+				break
+			}
+		}
+		time.Sleep(time.Duration(rand.IntN(3)) * time.Millisecond)
+		if n < 0 {
+			return 0, errors.New("negative input")
+		}
+		return n * n, nil
+	}
+}
+
+func TestBatch_ReturnsResultsInInputOrderWithPerItemErrors(t *testing.T) {
+	t.Parallel()
+
+	queries := []int{1, 2, -3, 4, 5, 6, 7, -8, 9, 10}
+	var inFlight, maxInFlight atomic.Int32
+	var progress []int
+	results := geonames.Batch(context.Background(), queries, slowSquare(&inFlight, &maxInFlight), geonames.BatchOptions{
+		Concurrency: 3,
+		Progress: func(done, total int) {
+			if total != len(queries) {
+				t.Errorf("want total %d, got %d", len(queries), total)
+			}
+			progress = append(progress, done)
+		},
+	})
+
+	for i, r := range results {
+		if r.Index != i || r.Query != queries[i] {
+			t.Fatalf("result %d out of order: %+v", i, r)
+		}
+		if queries[i] < 0 {
+			if r.Err == nil {
+				t.Errorf("result %d: want error", i)
+			}
+			continue
+		}
+		if r.Err != nil || r.Value != queries[i]*queries[i] {
+			t.Errorf("result %d: want %d, got %d, %v", i, queries[i]*queries[i], r.Value, r.Err)
+		}
+	}
+	if m := maxInFlight.Load(); m > 3 {
+		t.Errorf("want at most 3 queries in flight, got %d", m)
+	}
+	if len(progress) != len(queries) || progress[len(progress)-1] != len(queries) {
+		t.Errorf("want progress up to %d, got %v", len(queries), progress)
+	}
+}
+
+func TestBatch_FailsQueriesAfterContextIsCancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := geonames.Batch(ctx, []int{1, 2, 3}, func(ctx context.Context, n int) (int, error) {
+		return n, nil
+	}, geonames.BatchOptions{})
+	for _, r := range results {
+		if !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("want context.Canceled, got %v", r.Err)
+		}
+	}
+}
+
+func TestBatchStream_SendsResultsInInputOrder(t *testing.T) {
+	t.Parallel()
+
+	queries := make(chan int)
+	go func() {
+		defer close(queries)
+		for i := range 50 {
+			queries <- i
+		}
+	}()
+
+	var inFlight, maxInFlight atomic.Int32
+	var mu sync.Mutex
+	var lastDone int
+	results := geonames.BatchStream(context.Background(), queries, slowSquare(&inFlight, &maxInFlight), geonames.BatchOptions{
+		Concurrency: 5,
+		Progress: func(done, total int) {
+			mu.Lock()
+			defer mu.Unlock()
+			lastDone = done
+		},
+	})
+
+	var i int
+	for r := range results {
+		if r.Index != i || r.Value != i*i || r.Err != nil {
+			t.Fatalf("want result %d = %d, got %+v", i, i*i, r)
+		}
+		i++
+	}
+	if i != 50 {
+		t.Errorf("want 50 results, got %d", i)
+	}
+	if m := maxInFlight.Load(); m > 5 {
+		t.Errorf("want at most 5 queries in flight, got %d", m)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if lastDone != 50 {
+		t.Errorf("want progress 50, got %d", lastDone)
+	}
+}
+
+func TestBatchPostCodes_LooksUpEveryQuery(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("placename") == "Nowhere" {
+			rw.Write([]byte(`{"status":{"message":"no result found","value":15}}`))
+			return
+		}
+		http.ServeFile(rw, r, "geonamestest/fixtures/postal-single.json")
+	}))
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	results := client.BatchPostCodes(context.Background(), []geonames.PostalQuery{
+		{Place: "Castlebar", Country: "IE"},
+		{Place: "Nowhere", Country: "IE"},
+		{Place: "Castlebar", Country: "IE"},
+	}, geonames.BatchOptions{Concurrency: 2})
+
+	if len(results) != 3 {
+		t.Fatalf("want 3 results, got %d", len(results))
+	}
+	if results[0].Err != nil || len(results[0].Value) != 1 {
+		t.Errorf("want one postal code for first query, got %+v", results[0])
+	}
+	var ae *geonames.APIError
+	if !errors.As(results[1].Err, &ae) || ae.Code != geonames.CodeNoResultFound {
+		t.Errorf("want no result error for second query, got %v", results[1].Err)
+	}
+	if results[2].Err != nil || len(results[2].Value) != 1 {
+		t.Errorf("want one postal code for third query, got %+v", results[2])
+	}
+}
@@ -0,0 +1,61 @@
+package geonames
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache stores raw Web Service responses keyed by request URL.
+//
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, value []byte)
+}
+
+// MemoryCache is an in-memory Cache with a fixed time to live.
+type MemoryCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache creates a MemoryCache that keeps entries for ttl.
+// A ttl of zero keeps entries forever.
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// Get returns the value stored under key if it has not expired.
+func (m *MemoryCache) Get(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !e.expires.IsZero() && time.Now().After(e.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// Set stores value under key.
+func (m *MemoryCache) Set(key string, value []byte) {
+	e := cacheEntry{value: value}
+	if m.ttl > 0 {
+		e.expires = time.Now().Add(m.ttl)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = e
+}
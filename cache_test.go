@@ -0,0 +1,56 @@
+package geonames_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qba73/geonames"
+)
+
+func TestMemoryCache_ExpiresEntriesAfterTTL(t *testing.T) {
+	t.Parallel()
+
+	c := geonames.NewMemoryCache(10 * time.Millisecond)
+	c.Set("key", []byte("value"))
+	if got, ok := c.Get("key"); !ok || string(got) != "value" {
+		t.Fatalf("want cached value, got %q, %v", got, ok)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := c.Get("key"); ok {
+		t.Error("want entry expired")
+	}
+}
+
+func TestClient_ServesRepeatedRequestsFromCache(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		http.ServeFile(rw, r, "geonamestest/fixtures/postal-single.json")
+	}))
+	defer ts.Close()
+
+	c, err := geonames.New("DummyUser",
+		geonames.WithBaseURL(ts.URL),
+		geonames.WithCache(geonames.NewMemoryCache(time.Minute)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		got, err := c.GetPostCode(context.Background(), "Castlebar", "IE")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 {
+			t.Fatalf("want one postal code, got %v", got)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("want 1 call to the server, got %d", calls)
+	}
+}
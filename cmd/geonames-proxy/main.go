@@ -0,0 +1,106 @@
+// Command geonames-proxy serves the GeoNames JSON Web Service paths,
+// such as /searchJSON and /postalCodeSearchJSON, forwarding requests
+// through a single client so that every caller shares one account,
+// cache and rate limit.
+//
+// Usage:
+//
+//	geonames-proxy [flags]
+//
+// The GeoNames username and token come from the -username and -token
+// flags or the GEONAMES_USER and GEONAMES_TOKEN environment variables.
+// Credentials sent by callers are removed before forwarding.
+//
+// Callers are told apart by the X-Geonames-Caller header, else by the
+// username they send, else by their address. Per-caller counters of
+// requests, errors, cache hits and credits are published with the
+// client metrics at /debug/vars.
+package main
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/proxy"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "geonames-proxy: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("geonames-proxy", flag.ExitOnError)
+	listen := fs.String("listen", ":8080", "`address` to listen on")
+	username := fs.String("username", os.Getenv("GEONAMES_USER"), "GeoNames `username`, or $GEONAMES_USER")
+	token := fs.String("token", os.Getenv("GEONAMES_TOKEN"), "premium web service `token`, or $GEONAMES_TOKEN")
+	baseURL := fs.String("base-url", os.Getenv("GEONAMES_BASE_URL"), "web service `URL`, or $GEONAMES_BASE_URL")
+	cacheTTL := fs.Duration("cache-ttl", 24*time.Hour, "how long responses are cached; 0 disables the cache")
+	rate := fs.Int("rate", 0, "maximum `requests` sent to GeoNames per -rate-per; 0 means no limit")
+	ratePer := fs.Duration("rate-per", time.Hour, "period of the -rate limit")
+	retries := fs.Int("retries", 3, "attempts per request for temporary failures")
+	timeout := fs.Duration("timeout", 10*time.Second, "timeout of requests to GeoNames")
+	fs.Parse(args)
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	opts := []geonames.Option{
+		geonames.WithTimeout(*timeout),
+		geonames.WithRetry(*retries, time.Second),
+		geonames.WithObserver(geonames.NewExpvarObserver(expvar.NewMap("geonames"))),
+	}
+	if *token != "" {
+		opts = append(opts, geonames.WithToken(*token))
+	}
+	if *baseURL != "" {
+		opts = append(opts, geonames.WithBaseURL(*baseURL))
+	}
+	if *cacheTTL > 0 {
+		opts = append(opts, geonames.WithCache(geonames.NewMemoryCache(*cacheTTL)))
+	}
+	if *rate > 0 {
+		opts = append(opts, geonames.WithRateLimit(*rate, *ratePer))
+	}
+	client, err := geonames.New(*username, opts...)
+	if err != nil {
+		return err
+	}
+
+	p := proxy.New(client)
+	p.Logger = logger
+	expvar.Publish("geonames_proxy_callers", expvar.Func(func() any { return p.Stats() }))
+	mux := http.NewServeMux()
+	mux.Handle("/debug/vars", expvar.Handler())
+	mux.Handle("/", p)
+
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	logger.Info("geonames proxy listening", slog.String("address", *listen))
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Command geonames-server serves GeoNames JSON Web Service paths, such
+// as /searchJSON and /postalCodeSearchJSON, from local dump files, for
+// environments that cannot reach api.geonames.org.
+//
+// Usage:
+//
+//	geonames-server -places allCountries.zip [flags]
+//
+// Clients use the server by setting their base URL to its address;
+// the username they send is ignored. Endpoints whose dump files were
+// not given report GeoNames error 23 (service not implemented).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/qba73/geonames/dump"
+	"github.com/qba73/geonames/gazetteer"
+	"github.com/qba73/geonames/server"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "geonames-server: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	fs := flag.NewFlagSet("geonames-server", flag.ExitOnError)
+	listen := fs.String("listen", ":8080", "`address` to listen on")
+	places := fs.String("places", "", "places dump `file`, e.g. allCountries.zip")
+	altNames := fs.String("alternate-names", "", "alternateNamesV2 dump `file`")
+	postal := fs.String("postal", "", "comma-separated postal code dump `files`")
+	tables := fs.String("tables", "", "`directory` holding countryInfo.txt, admin1CodesASCII.txt and the other tables")
+	hierarchy := fs.String("hierarchy", "", "hierarchy dump `file`, used instead of hierarchy.txt in -tables")
+	fs.Parse(args)
+
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	start := time.Now()
+	s, err := load(*places, *altNames, *postal, *tables, *hierarchy)
+	if err != nil {
+		return err
+	}
+	s.Logger = logger
+	logger.Info("geonames dumps loaded", slog.Duration("took", time.Since(start)))
+
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	logger.Info("geonames server listening", slog.String("address", *listen))
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// load builds a server from the given dump files. Empty paths
+// leave the corresponding endpoints unavailable.
+func load(places, altNames, postal, tables, hierarchy string) (*server.Server, error) {
+	s := &server.Server{Data: &gazetteer.Offline{}}
+	if places != "" {
+		alts := func(yield func(dump.AlternateName, error) bool) {}
+		if altNames != "" {
+			f, err := dump.Open(altNames)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			alts = dump.AlternateNames(f)
+		}
+		var err error
+		if s.Data.Names, err = gazetteer.NewLocalGazetteer(dump.Places(places), alts); err != nil {
+			return nil, fmt.Errorf("loading %s: %w", places, err)
+		}
+		if s.Data.Spatial, err = gazetteer.NewSpatialIndex(dump.Places(places)); err != nil {
+			return nil, fmt.Errorf("indexing %s: %w", places, err)
+		}
+	}
+	if postal != "" {
+		var err error
+		if s.Data.Postal, err = gazetteer.LoadPostalIndex(strings.Split(postal, ",")...); err != nil {
+			return nil, fmt.Errorf("loading postal codes: %w", err)
+		}
+	}
+	if tables != "" {
+		var err error
+		if s.Data.Tables, err = dump.LoadTables(tables); err != nil {
+			return nil, fmt.Errorf("loading tables: %w", err)
+		}
+	}
+	if hierarchy != "" {
+		f, err := dump.Open(hierarchy)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		if s.Data.Tables == nil {
+			s.Data.Tables = &dump.Tables{}
+		}
+		if s.Data.Tables.Parents, err = dump.Parents(dump.Hierarchy(f)); err != nil {
+			return nil, fmt.Errorf("loading %s: %w", hierarchy, err)
+		}
+	}
+	return s, nil
+}
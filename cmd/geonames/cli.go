@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/qba73/geonames"
+)
+
+// command is a geonames subcommand.
+type command struct {
+	name    string
+	args    string
+	summary string
+	// minArgs and maxArgs bound the number of arguments of a
+	// query. A negative maxArgs means no limit.
+	minArgs, maxArgs int
+	// setup registers the flags of the command and returns its query.
+	setup func(fs *flag.FlagSet) query
+	// setupAction is used instead of setup by commands that do not
+	// print query results. It returns the action to run.
+	setupAction func(fs *flag.FlagSet) action
+}
+
+// query runs a single query with the given arguments.
+type query func(ctx context.Context, c *geonames.Client, args []string) (*table, error)
+
+// action runs a command that handles its own input and output.
+type action func(ctx context.Context, c *geonames.Client, opts *options, stderr io.Writer) error
+
+// usageError reports invalid arguments.
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...any) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// options holds the flags shared by every command.
+type options struct {
+	username    string
+	token       string
+	baseURL     string
+	lang        string
+	format      string
+	timeout     time.Duration
+	concurrency int
+}
+
+func commonFlags(fs *flag.FlagSet, getenv func(string) string) *options {
+	o := new(options)
+	fs.StringVar(&o.username, "username", getenv("GEONAMES_USER"), "GeoNames `username`, or $GEONAMES_USER")
+	fs.StringVar(&o.token, "token", getenv("GEONAMES_TOKEN"), "premium web service `token`, or $GEONAMES_TOKEN")
+	fs.StringVar(&o.baseURL, "base-url", getenv("GEONAMES_BASE_URL"), "web service `URL`, or $GEONAMES_BASE_URL")
+	fs.StringVar(&o.lang, "lang", "", "ISO-639 `language` of place names")
+	fs.StringVar(&o.format, "o", "table", "output `format`: table, json, ndjson or csv")
+	fs.DurationVar(&o.timeout, "timeout", 10*time.Second, "request timeout")
+	fs.IntVar(&o.concurrency, "concurrency", 4, "number of concurrent requests for input read from stdin")
+	return o
+}
+
+func (o *options) client() (*geonames.Client, error) {
+	if o.username == "" {
+		return nil, usagef("no username: use -username or set GEONAMES_USER")
+	}
+	opts := []geonames.Option{geonames.WithTimeout(o.timeout)}
+	if o.token != "" {
+		opts = append(opts, geonames.WithToken(o.token))
+	}
+	if o.baseURL != "" {
+		opts = append(opts, geonames.WithBaseURL(o.baseURL))
+	}
+	if o.lang != "" {
+		opts = append(opts, geonames.WithLanguage(o.lang))
+	}
+	c, err := geonames.New(o.username, opts...)
+	if err != nil {
+		return nil, &usageError{msg: err.Error()}
+	}
+	return c, nil
+}
+
+func lookup(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: geonames <command> [flags] [arguments]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-10s %-18s %s\n", c.name, c.args, c.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "geonames <command> -h" for the flags of a command.`)
+}
+
+// run runs the command line args and returns the exit status.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer, getenv func(string) string) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitUsage
+	}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage(stdout)
+		return exitOK
+	}
+	cmd, ok := lookup(args[0])
+	if !ok {
+		fmt.Fprintf(stderr, "geonames: unknown command %q\n\n", args[0])
+		usage(stderr)
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet("geonames "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "usage: geonames %s [flags] %s\n\n%s.\n\nFlags:\n", cmd.name, cmd.args, cmd.summary)
+		fs.PrintDefaults()
+	}
+	opts := commonFlags(fs, getenv)
+	var q query
+	var act action
+	if cmd.setupAction != nil {
+		act = cmd.setupAction(fs)
+	} else {
+		q = cmd.setup(fs)
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	write, ok := writers[opts.format]
+	if !ok {
+		fmt.Fprintf(stderr, "geonames: unknown output format %q\n", opts.format)
+		return exitUsage
+	}
+	client, err := opts.client()
+	if err != nil {
+		fmt.Fprintf(stderr, "geonames: %v\n", err)
+		return exitCode(err)
+	}
+	if act != nil {
+		if fs.NArg() > 0 {
+			fs.Usage()
+			return exitUsage
+		}
+		if err := act(ctx, client, opts, stderr); err != nil {
+			fmt.Fprintf(stderr, "geonames: %v\n", err)
+			return exitCode(err)
+		}
+		return exitOK
+	}
+
+	batch := fs.NArg() == 1 && fs.Arg(0) == "-"
+	queries := [][]string{fs.Args()}
+	lines := []int{0}
+	if batch {
+		if queries, lines, err = readQueries(stdin); err != nil {
+			fmt.Fprintf(stderr, "geonames: reading stdin: %v\n", err)
+			return exitError
+		}
+	}
+
+	results := geonames.Batch(ctx, queries, func(ctx context.Context, args []string) (*table, error) {
+		if len(args) < cmd.minArgs || (cmd.maxArgs >= 0 && len(args) > cmd.maxArgs) {
+			return nil, usagef("usage: geonames %s %s", cmd.name, cmd.args)
+		}
+		return q(ctx, client, args)
+	}, geonames.BatchOptions{Concurrency: opts.concurrency})
+
+	out := new(table)
+	code := exitOK
+	for _, r := range results {
+		if r.Err != nil {
+			if batch {
+				fmt.Fprintf(stderr, "geonames: line %d: %v\n", lines[r.Index], r.Err)
+			} else {
+				fmt.Fprintf(stderr, "geonames: %v\n", r.Err)
+			}
+			if code == exitOK {
+				code = exitCode(r.Err)
+			}
+			continue
+		}
+		out.append(r.Value)
+	}
+	if err := write(stdout, out); err != nil {
+		fmt.Fprintf(stderr, "geonames: %v\n", err)
+		return exitError
+	}
+	if code == exitOK && len(out.records) == 0 {
+		return exitNotFound
+	}
+	return code
+}
+
+// readQueries reads one query per non-empty line of r, with its
+// arguments separated by tabs. It returns the line number of each query.
+func readQueries(r io.Reader) ([][]string, []int, error) {
+	var queries [][]string
+	var lines []int
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimRight(s.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		queries = append(queries, strings.Split(line, "\t"))
+		lines = append(lines, n)
+	}
+	return queries, lines, s.Err()
+}
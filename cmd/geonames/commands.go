@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strconv"
+	"strings"
+
+	"github.com/qba73/geonames"
+)
+
+var commands = []command{
+	{
+		name: "search", args: "<query>", summary: "Search places by name",
+		minArgs: 1, maxArgs: 1, setup: searchCommand,
+	},
+	{
+		name: "postal", args: "<place> [country]", summary: "Look up the postal codes of a place",
+		minArgs: 1, maxArgs: 2, setup: postalCommand,
+	},
+	{
+		name: "wiki", args: "<name> [country]", summary: "Search Wikipedia articles about places",
+		minArgs: 1, maxArgs: 2, setup: wikiCommand,
+	},
+	{
+		name: "elevation", args: "<lat> <lng>", summary: "Print the elevation in meters",
+		minArgs: 1, maxArgs: 2, setup: elevationCommand,
+	},
+	{
+		name: "timezone", args: "<lat> <lng>", summary: "Print the time zone, sunrise and sunset",
+		minArgs: 1, maxArgs: 2, setup: timezoneCommand,
+	},
+	{
+		name: "nearby", args: "<lat> <lng>", summary: "Find places near a position",
+		minArgs: 1, maxArgs: 2, setup: nearbyCommand,
+	},
+	{
+		name: "country", args: "[code...]", summary: "Print country information",
+		minArgs: 0, maxArgs: -1, setup: countryCommand,
+	},
+	{
+		name: "hierarchy", args: "<geonameId>", summary: "Print the places a place belongs to",
+		minArgs: 1, maxArgs: 1, setup: hierarchyCommand,
+	},
+	{
+		name: "geocode", args: "-in <csv> -out <csv>", summary: "Add coordinates and postal codes to the rows of a CSV file",
+		setupAction: geocodeCommand,
+	},
+}
+
+// list is a flag holding comma-separated values.
+type list []string
+
+func (l *list) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *list) Set(s string) error {
+	*l = append(*l, strings.Split(s, ",")...)
+	return nil
+}
+
+func searchCommand(fs *flag.FlagSet) query {
+	var countries, classes, codes list
+	fs.Var(&countries, "country", "comma-separated ISO-3166 `codes` of countries to search in")
+	fs.Var(&classes, "feature-class", "comma-separated feature `classes`, e.g. P")
+	fs.Var(&codes, "feature-code", "comma-separated feature `codes`, e.g. PPLC")
+	maxRows := fs.Int("max", 10, "maximum number of results")
+	fuzzy := fs.Float64("fuzzy", 0, "fuzziness between 0 and 1, lower is fuzzier; 0 disables fuzzy matching")
+	return func(ctx context.Context, c *geonames.Client, args []string) (*table, error) {
+		res, err := c.Search(ctx, geonames.SearchQuery{
+			Q:            args[0],
+			Country:      countries,
+			FeatureClass: classes,
+			FeatureCode:  codes,
+			Fuzzy:        *fuzzy,
+			MaxRows:      *maxRows,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return placesTable(res.Places, false), nil
+	}
+}
+
+func postalCommand(fs *flag.FlagSet) query {
+	return func(ctx context.Context, c *geonames.Client, args []string) (*table, error) {
+		country := ""
+		if len(args) > 1 {
+			country = args[1]
+		}
+		codes, err := c.GetPostCode(ctx, args[0], country)
+		if err != nil {
+			return nil, err
+		}
+		t := &table{header: []string{"postalCode", "placeName", "adminName1", "countryCode", "lat", "lng"}}
+		for _, pc := range codes {
+			t.add(pc, pc.PostalCode, pc.PlaceName, pc.AdminName1, pc.CountryCode, float(pc.Position.Lat), float(pc.Position.Lng))
+		}
+		return t, nil
+	}
+}
+
+func wikiCommand(fs *flag.FlagSet) query {
+	maxRows := fs.Int("max", 5, "maximum number of results")
+	return func(ctx context.Context, c *geonames.Client, args []string) (*table, error) {
+		country := ""
+		if len(args) > 1 {
+			country = args[1]
+		}
+		names, err := c.GetPlace(ctx, args[0], country, *maxRows)
+		if err != nil {
+			return nil, err
+		}
+		t := &table{header: []string{"title", "countryCode", "feature", "lat", "lng", "url"}}
+		for _, g := range names {
+			t.add(g, g.Title, g.CountryCode, g.Feature, float(g.Position.Lat), float(g.Position.Lng), g.URL)
+		}
+		return t, nil
+	}
+}
+
+func elevationCommand(fs *flag.FlagSet) query {
+	model := fs.String("model", "", "elevation `model`: srtm1, srtm3, astergdem or gtopo30 (default srtm3, falling back to gtopo30)")
+	return func(ctx context.Context, c *geonames.Client, args []string) (*table, error) {
+		pos, err := parsePosition(args)
+		if err != nil {
+			return nil, err
+		}
+		var e geonames.Elevation
+		switch *model {
+		case "":
+			e, err = c.Elevation(ctx, pos)
+		case "srtm1":
+			e, err = c.GetElevationSRTM1(ctx, pos.Lat, pos.Lng)
+		case "srtm3":
+			e, err = c.GetElevationSRTM3(ctx, pos.Lat, pos.Lng)
+		case "astergdem":
+			e, err = c.GetElevationAstergdem(ctx, pos.Lat, pos.Lng)
+		case "gtopo30":
+			e, err = c.GetElevationGTOPO30(ctx, pos.Lat, pos.Lng)
+		default:
+			return nil, usagef("unknown elevation model %q", *model)
+		}
+		if err != nil {
+			return nil, err
+		}
+		t := &table{header: []string{"type", "lat", "lng", "value"}}
+		t.add(e, e.Type, float(e.Lat), float(e.Lng), strconv.Itoa(e.Value))
+		return t, nil
+	}
+}
+
+func timezoneCommand(fs *flag.FlagSet) query {
+	return func(ctx context.Context, c *geonames.Client, args []string) (*table, error) {
+		pos, err := parsePosition(args)
+		if err != nil {
+			return nil, err
+		}
+		tz, err := c.GetTimezone(ctx, pos)
+		if err != nil {
+			return nil, err
+		}
+		t := &table{header: []string{"timezoneId", "countryCode", "gmtOffset", "dstOffset", "rawOffset", "time", "sunrise", "sunset"}}
+		t.add(tz, tz.TimezoneID, tz.CountryCode, float(tz.GMTOffset), float(tz.DSTOffset), float(tz.RawOffset), tz.Time, tz.Sunrise, tz.Sunset)
+		return t, nil
+	}
+}
+
+func nearbyCommand(fs *flag.FlagSet) query {
+	var classes list
+	fs.Var(&classes, "feature-class", "comma-separated feature `classes`, e.g. P")
+	radius := fs.Float64("radius", 0, "search radius in `km` (default the service default)")
+	maxRows := fs.Int("max", 0, "maximum number of results (default the service default)")
+	return func(ctx context.Context, c *geonames.Client, args []string) (*table, error) {
+		pos, err := parsePosition(args)
+		if err != nil {
+			return nil, err
+		}
+		places, err := c.FindNearby(ctx, pos, geonames.NearbyQuery{
+			Radius:       *radius,
+			FeatureClass: classes,
+			MaxRows:      *maxRows,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return placesTable(places, true), nil
+	}
+}
+
+func countryCommand(fs *flag.FlagSet) query {
+	return func(ctx context.Context, c *geonames.Client, args []string) (*table, error) {
+		countries, err := c.GetCountryInfo(ctx, args...)
+		if err != nil {
+			return nil, err
+		}
+		t := &table{header: []string{"countryCode", "countryName", "capital", "continent", "population", "areaInSqKm", "currencyCode", "languages"}}
+		for _, cn := range countries {
+			t.add(cn, cn.CountryCode, cn.CountryName, cn.Capital, cn.Continent,
+				strconv.Itoa(cn.Population), float(cn.AreaInSqKm), cn.CurrencyCode, strings.Join(cn.Languages, ","))
+		}
+		return t, nil
+	}
+}
+
+func hierarchyCommand(fs *flag.FlagSet) query {
+	return func(ctx context.Context, c *geonames.Client, args []string) (*table, error) {
+		id, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, usagef("invalid geonameId %q", args[0])
+		}
+		places, err := c.GetHierarchy(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return placesTable(places, false), nil
+	}
+}
+
+func placesTable(places []geonames.Place, distance bool) *table {
+	t := &table{header: []string{"geonameId", "name", "countryCode", "adminName1", "featureCode", "lat", "lng", "population"}}
+	if distance {
+		t.header = append(t.header, "distance")
+	}
+	for _, p := range places {
+		row := []string{
+			strconv.Itoa(p.GeoNameID), p.Name, p.CountryCode, p.AdminName1, p.FeatureCode,
+			float(p.Position.Lat), float(p.Position.Lng), strconv.Itoa(p.Population),
+		}
+		if distance {
+			row = append(row, float(p.Distance))
+		}
+		t.add(p, row...)
+	}
+	return t
+}
+
+// parsePosition parses a position given as one or more arguments in
+// any notation geonames.ParsePosition accepts, such as "<lat> <lng>",
+// "<lat>,<lng>" or "33°52′S 151°13′E".
+func parsePosition(args []string) (geonames.Position, error) {
+	in := strings.Join(args, " ")
+	pos, err := geonames.ParsePosition(in)
+	if err != nil {
+		return geonames.Position{}, usagef("invalid position %q: %v", in, err)
+	}
+	return pos, nil
+}
+
+func float(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/qba73/geonames"
+)
+
+// Exit statuses.
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitNotFound    = 3
+	exitAuth        = 4
+	exitLimit       = 5
+	exitInvalid     = 6
+	exitUnavailable = 7
+)
+
+// exitCode maps an error to the exit status for its class.
+func exitCode(err error) int {
+	var ue *usageError
+	if errors.As(err, &ue) {
+		return exitUsage
+	}
+	if errors.Is(err, geonames.ErrNotFound) {
+		return exitNotFound
+	}
+	var ae *geonames.APIError
+	if errors.As(err, &ae) {
+		switch ae.Code {
+		case geonames.CodeAuthorizationException:
+			return exitAuth
+		case geonames.CodeDailyLimitExceeded, geonames.CodeHourlyLimitExceeded, geonames.CodeWeeklyLimitExceeded:
+			return exitLimit
+		case geonames.CodeInvalidParameter, geonames.CodeInvalidInput, geonames.CodeRadiusTooLarge, geonames.CodeMaxRowsTooLarge:
+			return exitInvalid
+		case geonames.CodeDatabaseTimeout, geonames.CodeServerOverloaded, geonames.CodeStatusUnavailable:
+			return exitUnavailable
+		}
+		return exitError
+	}
+	var he *geonames.HTTPError
+	if errors.As(err, &he) {
+		switch {
+		case he.StatusCode == http.StatusUnauthorized || he.StatusCode == http.StatusForbidden:
+			return exitAuth
+		case he.StatusCode == http.StatusTooManyRequests:
+			return exitLimit
+		case he.StatusCode >= 500:
+			return exitUnavailable
+		}
+		return exitError
+	}
+	var ne *url.Error
+	if errors.As(err, &ne) {
+		return exitUnavailable
+	}
+	return exitError
+}
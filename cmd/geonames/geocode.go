@@ -0,0 +1,345 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/qba73/geonames"
+)
+
+// ambiguityRatio is how many times more inhabitants the most populous
+// place of a name needs than the next one to be taken as the match.
+const ambiguityRatio = 10
+
+// geocodeColumns are the columns appended to every geocoded row.
+var geocodeColumns = []string{"lat", "lng", "geonameId", "admin1", "postalCode"}
+
+// geocodeConfig holds the flags of the geocode command.
+type geocodeConfig struct {
+	in, out, rejects, checkpoint string
+	placeCol, countryCol         string
+	featureClass                 string
+	postal                       bool
+	chunk                        int
+	concurrency                  int
+}
+
+func geocodeCommand(fs *flag.FlagSet) action {
+	cfg := new(geocodeConfig)
+	fs.StringVar(&cfg.in, "in", "", "input CSV `file` with a header row")
+	fs.StringVar(&cfg.out, "out", "", "output CSV `file` with the geocoded rows")
+	fs.StringVar(&cfg.rejects, "rejects", "", "CSV `file` for rows without a single match (default <out>.rejects.csv)")
+	fs.StringVar(&cfg.checkpoint, "checkpoint", "", "progress `file` used to resume an interrupted run (default <out>.checkpoint)")
+	fs.StringVar(&cfg.placeCol, "place-col", "", "`column` holding the place name")
+	fs.StringVar(&cfg.countryCol, "country-col", "", "`column` holding the ISO-3166 country code, if any")
+	fs.StringVar(&cfg.featureClass, "feature-class", "P", "feature `class` of the places to match")
+	fs.BoolVar(&cfg.postal, "postal", true, "look up a postal code for each place")
+	fs.IntVar(&cfg.chunk, "chunk", 100, "number of `rows` geocoded between checkpoints")
+	return func(ctx context.Context, c *geonames.Client, opts *options, stderr io.Writer) error {
+		cfg.concurrency = opts.concurrency
+		return cfg.run(ctx, c, stderr)
+	}
+}
+
+// checkpoint records the progress of a geocode run: the number of
+// input rows done and the size of the output files at that point.
+type checkpoint struct {
+	Rows        int   `json:"rows"`
+	OutSize     int64 `json:"outSize"`
+	RejectsSize int64 `json:"rejectsSize"`
+}
+
+func (cfg *geocodeConfig) run(ctx context.Context, c *geonames.Client, stderr io.Writer) error {
+	if cfg.in == "" || cfg.out == "" || cfg.placeCol == "" {
+		return usagef("geocode needs -in, -out and -place-col")
+	}
+	if cfg.chunk < 1 {
+		return usagef("invalid -chunk %d", cfg.chunk)
+	}
+	if cfg.rejects == "" {
+		cfg.rejects = cfg.out + ".rejects.csv"
+	}
+	if cfg.checkpoint == "" {
+		cfg.checkpoint = cfg.out + ".checkpoint"
+	}
+
+	in, err := os.Open(cfg.in)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	r := csv.NewReader(in)
+	header, err := r.Read()
+	if err != nil {
+		return fmt.Errorf("reading header of %s: %w", cfg.in, err)
+	}
+	placeIdx := slices.Index(header, cfg.placeCol)
+	if placeIdx < 0 {
+		return usagef("no column %q in %s", cfg.placeCol, cfg.in)
+	}
+	countryIdx := -1
+	if cfg.countryCol != "" {
+		if countryIdx = slices.Index(header, cfg.countryCol); countryIdx < 0 {
+			return usagef("no column %q in %s", cfg.countryCol, cfg.in)
+		}
+	}
+
+	cp, resume, err := readCheckpoint(cfg.checkpoint)
+	if err != nil {
+		return err
+	}
+	out, err := openOutput(cfg.out, cp.OutSize, resume)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	rejects, err := openOutput(cfg.rejects, cp.RejectsSize, resume)
+	if err != nil {
+		return err
+	}
+	defer rejects.Close()
+	ow, rw := csv.NewWriter(out), csv.NewWriter(rejects)
+	if !resume {
+		ow.Write(append(slices.Clip(header), geocodeColumns...))
+		rw.Write(append(slices.Clip(header), "reason"))
+	}
+	for range cp.Rows {
+		if _, err := r.Read(); err != nil {
+			return fmt.Errorf("skipping rows done before the checkpoint: %w", err)
+		}
+	}
+
+	g := &geocoder{client: c, cfg: cfg, cache: make(map[geoKey]geoResult)}
+	var geocoded, rejected int
+	for {
+		rows, err := readRows(r, cfg.chunk)
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		keys := make([]geoKey, len(rows))
+		for i, row := range rows {
+			keys[i] = geoKey{place: normalizeKey(row[placeIdx])}
+			if countryIdx >= 0 {
+				keys[i].country = strings.ToUpper(strings.TrimSpace(row[countryIdx]))
+			}
+		}
+		if err := g.resolve(ctx, keys); err != nil {
+			fmt.Fprintf(stderr, "geonames: stopped after %d rows; run again to resume\n", cp.Rows)
+			return err
+		}
+		for i, row := range rows {
+			res := g.cache[keys[i]]
+			if res.reason != "" {
+				rw.Write(append(row, res.reason))
+				rejected++
+				continue
+			}
+			p := res.place
+			ow.Write(append(row,
+				float(p.Position.Lat), float(p.Position.Lng), strconv.Itoa(p.GeoNameID), p.AdminName1, res.postalCode))
+			geocoded++
+		}
+		cp.Rows += len(rows)
+		if cp.OutSize, err = flush(ow, out); err != nil {
+			return err
+		}
+		if cp.RejectsSize, err = flush(rw, rejects); err != nil {
+			return err
+		}
+		if err := writeCheckpoint(cfg.checkpoint, cp); err != nil {
+			return err
+		}
+	}
+	if _, err := flush(ow, out); err != nil {
+		return err
+	}
+	if _, err := flush(rw, rejects); err != nil {
+		return err
+	}
+	fmt.Fprintf(stderr, "geonames: geocoded %d rows, rejected %d, %d distinct places\n", geocoded, rejected, len(g.cache))
+	return os.Remove(cfg.checkpoint)
+}
+
+// geoKey identifies a distinct query, so that repeated
+// places are looked up only once.
+type geoKey struct {
+	place, country string
+}
+
+// geoResult is the answer to a geoKey: a place or
+// the reason the row was rejected.
+type geoResult struct {
+	place      geonames.Place
+	postalCode string
+	reason     string
+}
+
+type geocoder struct {
+	client *geonames.Client
+	cfg    *geocodeConfig
+	cache  map[geoKey]geoResult
+}
+
+// resolve looks up the keys not answered yet.
+func (g *geocoder) resolve(ctx context.Context, keys []geoKey) error {
+	var todo []geoKey
+	for _, k := range keys {
+		if _, ok := g.cache[k]; !ok && !slices.Contains(todo, k) {
+			todo = append(todo, k)
+		}
+	}
+	results := geonames.Batch(ctx, todo, g.lookup, geonames.BatchOptions{Concurrency: g.cfg.concurrency})
+	for _, r := range results {
+		if r.Err != nil {
+			return fmt.Errorf("geocoding %q: %w", r.Query.place, r.Err)
+		}
+		g.cache[r.Query] = r.Value
+	}
+	return nil
+}
+
+func (g *geocoder) lookup(ctx context.Context, k geoKey) (geoResult, error) {
+	if k.place == "" {
+		return geoResult{reason: "no place name"}, nil
+	}
+	q := geonames.SearchQuery{
+		NameEquals: k.place,
+		OrderBy:    "population",
+		MaxRows:    2,
+	}
+	if k.country != "" {
+		q.Country = []string{k.country}
+	}
+	if g.cfg.featureClass != "" {
+		q.FeatureClass = []string{g.cfg.featureClass}
+	}
+	res, err := g.client.Search(ctx, q)
+	if errors.Is(err, geonames.ErrNotFound) || (err == nil && len(res.Places) == 0) {
+		return geoResult{reason: "no match"}, nil
+	}
+	if err != nil {
+		return geoResult{}, err
+	}
+	if len(res.Places) > 1 && res.Places[0].Population < ambiguityRatio*max(res.Places[1].Population, 1) {
+		return geoResult{reason: fmt.Sprintf("ambiguous: %d places", res.TotalResultsCount)}, nil
+	}
+	r := geoResult{place: res.Places[0]}
+	if !g.cfg.postal {
+		return r, nil
+	}
+	codes, err := g.client.GetPostCode(ctx, k.place, r.place.CountryCode)
+	if err != nil && !errors.Is(err, geonames.ErrNotFound) {
+		return geoResult{}, err
+	}
+	r.postalCode = postalCodeIn(codes, r.place)
+	return r, nil
+}
+
+// postalCodeIn returns the first postal code in the region of p,
+// or else the first in its country.
+func postalCodeIn(codes []geonames.PostalCode, p geonames.Place) string {
+	first := ""
+	for _, pc := range codes {
+		if pc.CountryCode != p.CountryCode {
+			continue
+		}
+		if pc.AdminCode1 == p.AdminCode1 {
+			return pc.PostalCode
+		}
+		if first == "" {
+			first = pc.PostalCode
+		}
+	}
+	return first
+}
+
+// normalizeKey folds case and spacing so that
+// spellings of the same query share a lookup.
+func normalizeKey(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// readRows reads up to n records.
+func readRows(r *csv.Reader, n int) ([][]string, error) {
+	var rows [][]string
+	for len(rows) < n {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func readCheckpoint(path string) (checkpoint, bool, error) {
+	var cp checkpoint
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cp, false, nil
+	}
+	if err != nil {
+		return cp, false, err
+	}
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return cp, false, fmt.Errorf("reading checkpoint %s: %w", path, err)
+	}
+	return cp, true, nil
+}
+
+func writeCheckpoint(path string, cp checkpoint) error {
+	b, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// openOutput opens an output file. When resuming, it drops anything
+// written after the checkpoint and appends; otherwise it starts afresh.
+func openOutput(path string, size int64, resume bool) (*os.File, error) {
+	if !resume {
+		return os.Create(path)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, fmt.Errorf("resuming from checkpoint: %w", err)
+	}
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// flush flushes w to f and returns the size of f.
+func flush(w *csv.Writer, f *os.File) (int64, error) {
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return 0, err
+	}
+	return f.Seek(0, io.SeekCurrent)
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const geocodeInput = `id,city,cc
+1,Castlebar,IE
+2,Westport,IE
+3, castlebar ,ie
+4,Springfield,US
+5,Nowhere,IE
+6,Galway,IE
+`
+
+func place(id int, name, admin1 string, lat, lng float64, population int) string {
+	return fmt.Sprintf(`{"geonameId":%d,"name":%q,"countryCode":"IE","adminCode1":"C","adminName1":%q,"lat":"%g","lng":"%g","population":%d,"fcl":"P"}`,
+		id, name, admin1, lat, lng, population)
+}
+
+// geocodeServer is a test helper answering searches for the places
+// of geocodeInput and counting the searches for each name. Searches
+// for names in failing get the daily limit error.
+type geocodeServer struct {
+	mu       sync.Mutex
+	searches map[string]int
+	failing  map[string]bool
+}
+
+func (s *geocodeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if r.URL.Path == "/postalCodeSearchJSON" {
+		fmt.Fprintf(w, `{"postalCodes":[{"postalCode":"X99","countryCode":"GB","adminCode1":"C"},{"postalCode":"F23 %s","countryCode":"IE","adminCode1":"C"}]}`, q.Get("placename"))
+		return
+	}
+	name := q.Get("name_equals")
+	s.mu.Lock()
+	s.searches[name]++
+	fail := s.failing[name]
+	s.mu.Unlock()
+	if fail {
+		fmt.Fprint(w, `{"status":{"message":"the daily limit of 20000 credits has been exceeded","value":18}}`)
+		return
+	}
+	switch name {
+	case "castlebar":
+		fmt.Fprintf(w, `{"totalResultsCount":1,"geonames":[%s]}`, place(2965654, "Castlebar", "Connacht", 53.85583, -9.29778, 12068))
+	case "westport":
+		fmt.Fprintf(w, `{"totalResultsCount":1,"geonames":[%s]}`, place(2963403, "Westport", "Connacht", 53.8, -9.51667, 6198))
+	case "galway":
+		fmt.Fprintf(w, `{"totalResultsCount":2,"geonames":[%s,%s]}`,
+			place(2964180, "Galway", "Connacht", 53.27194, -9.04889, 79934), place(1, "Galway", "Connacht", 53, -9, 120))
+	case "springfield":
+		fmt.Fprintf(w, `{"totalResultsCount":33,"geonames":[%s,%s]}`,
+			place(4409896, "Springfield", "Missouri", 37.2, -93.3, 169176), place(4951788, "Springfield", "Massachusetts", 42.1, -72.6, 155929))
+	default:
+		fmt.Fprint(w, `{"totalResultsCount":0,"geonames":[]}`)
+	}
+}
+
+func TestGeocode_AppendsColumnsAndRejectsUnmatchedRows(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	in := filepath.Join(dir, "addresses.csv")
+	out := filepath.Join(dir, "enriched.csv")
+	if err := os.WriteFile(in, []byte(geocodeInput), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	srv := &geocodeServer{searches: make(map[string]int), failing: map[string]bool{"galway": true}}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	args := []string{"geocode", "-in", in, "-out", out, "-place-col", "city", "-country-col", "cc", "-chunk", "2"}
+	code, _, stderr := runCLI(t, ts, "", args...)
+	if code != exitLimit {
+		t.Fatalf("want exit %d on daily limit, got %d: %s", exitLimit, code, stderr)
+	}
+	if _, err := os.Stat(out + ".checkpoint"); err != nil {
+		t.Fatalf("want checkpoint after interruption: %v", err)
+	}
+
+	srv.failing = nil
+	code, _, stderr = runCLI(t, ts, "", args...)
+	if code != exitOK {
+		t.Fatalf("want exit 0 on resume, got %d: %s", code, stderr)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `id,city,cc,lat,lng,geonameId,admin1,postalCode
+1,Castlebar,IE,53.85583,-9.29778,2965654,Connacht,F23 castlebar
+2,Westport,IE,53.8,-9.51667,2963403,Connacht,F23 westport
+3," castlebar ",ie,53.85583,-9.29778,2965654,Connacht,F23 castlebar
+6,Galway,IE,53.27194,-9.04889,2964180,Connacht,F23 galway
+`
+	if !cmp.Equal(want, string(got)) {
+		t.Error(cmp.Diff(want, string(got)))
+	}
+
+	rejects, err := os.ReadFile(out + ".rejects.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `id,city,cc,reason
+4,Springfield,US,ambiguous: 33 places
+5,Nowhere,IE,no match
+`
+	if !cmp.Equal(want, string(rejects)) {
+		t.Error(cmp.Diff(want, string(rejects)))
+	}
+
+	if srv.searches["castlebar"] != 1 {
+		t.Errorf("want duplicate place searched once, got %d searches", srv.searches["castlebar"])
+	}
+	if _, err := os.Stat(out + ".checkpoint"); !os.IsNotExist(err) {
+		t.Errorf("want checkpoint removed after completion, got %v", err)
+	}
+	if !strings.Contains(stderr, "geocoded 1 rows, rejected 1") {
+		t.Errorf("want summary of resumed run, got %q", stderr)
+	}
+}
+
+func TestGeocode_RequiresInputOutputAndPlaceColumn(t *testing.T) {
+	t.Parallel()
+
+	ts := newServer(t, nil)
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.csv")
+	if err := os.WriteFile(in, []byte("id,city\n1,Castlebar\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"geocode", "-in", in, "-out", filepath.Join(dir, "out.csv")},
+		{"geocode", "-in", in, "-out", filepath.Join(dir, "out.csv"), "-place-col", "town"},
+		{"geocode", "-out", filepath.Join(dir, "out.csv"), "-place-col", "city"},
+	} {
+		if code, _, _ := runCLI(t, ts, "", args...); code != exitUsage {
+			t.Errorf("%q: want exit %d, got %d", args, exitUsage, code)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+// Command geonames queries the GeoNames Web Services.
+//
+// Usage:
+//
+//	geonames <command> [flags] [arguments]
+//
+// The commands are:
+//
+//	search     <query>                  search places by name
+//	postal     <place> [country]        look up postal codes of a place
+//	wiki       <name> [country]         search Wikipedia articles about places
+//	elevation  <lat> <lng>              elevation in meters
+//	timezone   <lat> <lng>              time zone, sunrise and sunset
+//	nearby     <lat> <lng>              places near a position
+//	country    [code...]                country information
+//	hierarchy  <geonameId>              places a place belongs to
+//	geocode    -in <csv> -out <csv>     add coordinates and postal codes to CSV rows
+//
+// Credentials are read from the -username and -token flags or the
+// GEONAMES_USER and GEONAMES_TOKEN environment variables.
+//
+// Results are printed as a table, or with -o as json, ndjson or csv.
+//
+// An argument of "-" reads one query per line from standard input,
+// with the arguments of each query separated by tabs, and runs the
+// queries concurrently. Queries that fail are reported on standard
+// error without stopping the others.
+//
+// The geocode command looks up the place named in a column of each row
+// of a CSV file and writes the rows with lat, lng, geonameId, admin1 and
+// postalCode columns added. Each distinct place is looked up once. Rows
+// without a match, or whose name is shared by places of similar size,
+// go to a rejects file instead. Progress is saved in a checkpoint file
+// so that an interrupted run, e.g. on reaching the daily credit limit,
+// resumes where it stopped when run again.
+//
+// Positions are given as two arguments, as one "lat,lng" argument or
+// in degrees and minutes, e.g. geonames nearby 33°52′S 151°13′E.
+// Use -- before a negative latitude, e.g. geonames nearby -- -33.87 151.21.
+//
+// The exit status is 0 on success, 2 for usage errors, 3 if nothing
+// was found, 4 for authorization errors, 5 when a credit or rate limit
+// is exceeded, 6 for invalid parameters, 7 when the service is
+// unavailable and 1 for other errors.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr, os.Getenv)
+	stop()
+	os.Exit(code)
+}
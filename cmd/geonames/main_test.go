@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newServer is a test helper serving fixed responses by request path.
+func newServer(t *testing.T, responses map[string]string) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path
+		if q := r.URL.Query(); q.Has("placename") {
+			key += "?" + q.Get("placename")
+		}
+		body, ok := responses[key]
+		if !ok {
+			body = `{"postalCodes":[]}`
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// runCLI is a test helper running the command with the given
+// arguments and stdin against the test server.
+func runCLI(t *testing.T, ts *httptest.Server, stdin string, args ...string) (code int, stdout, stderr string) {
+	t.Helper()
+	var out, errOut strings.Builder
+	env := map[string]string{
+		"GEONAMES_USER":     "DummyUser",
+		"GEONAMES_BASE_URL": ts.URL,
+	}
+	code = run(context.Background(), args, strings.NewReader(stdin), &out, &errOut, func(k string) string { return env[k] })
+	return code, out.String(), errOut.String()
+}
+
+const castlebarPostal = `{"postalCodes":[{"adminCode1":"C","lng":-9.29778,"countryCode":"IE","postalCode":"F23","adminName1":"Connacht","placeName":"Castlebar","lat":53.85583}]}`
+
+const searchResponse = `{"totalResultsCount":1,"geonames":[{"adminCode1":"C","lng":"-9.29778","geonameId":2965654,"toponymName":"Castlebar","fcl":"P","population":12068,"countryCode":"IE","name":"Castlebar","countryName":"Ireland","adminName1":"Connacht","lat":"53.85583","fcode":"PPLA2"}]}`
+
+func TestRun_PrintsSearchResultsAsTable(t *testing.T) {
+	t.Parallel()
+
+	ts := newServer(t, map[string]string{"/searchJSON": searchResponse})
+	code, stdout, stderr := runCLI(t, ts, "", "search", "-country", "IE", "Castlebar")
+	if code != exitOK {
+		t.Fatalf("want exit 0, got %d: %s", code, stderr)
+	}
+	want := "" +
+		"GEONAMEID  NAME       COUNTRYCODE  ADMINNAME1  FEATURECODE  LAT       LNG       POPULATION\n" +
+		"2965654    Castlebar  IE           Connacht    PPLA2        53.85583  -9.29778  12068\n"
+	if !cmp.Equal(want, stdout) {
+		t.Error(cmp.Diff(want, stdout))
+	}
+}
+
+func TestRun_PrintsPostalCodesAsCSVAndNDJSON(t *testing.T) {
+	t.Parallel()
+
+	ts := newServer(t, map[string]string{"/postalCodeSearchJSON?Castlebar": castlebarPostal})
+	_, stdout, _ := runCLI(t, ts, "", "postal", "-o", "csv", "Castlebar", "IE")
+	want := "postalCode,placeName,adminName1,countryCode,lat,lng\nF23,Castlebar,Connacht,IE,53.85583,-9.29778\n"
+	if !cmp.Equal(want, stdout) {
+		t.Error(cmp.Diff(want, stdout))
+	}
+
+	_, stdout, _ = runCLI(t, ts, "", "postal", "-o", "ndjson", "Castlebar", "IE")
+	want = `{"PlaceName":"Castlebar","AdminName1":"Connacht","Position":{"Lat":53.85583,"Lng":-9.29778},"CountryCode":"IE","PostalCode":"F23","AdminCode1":"C"}` + "\n"
+	if !cmp.Equal(want, stdout) {
+		t.Error(cmp.Diff(want, stdout))
+	}
+}
+
+func TestRun_ReadsBatchQueriesFromStdin(t *testing.T) {
+	t.Parallel()
+
+	ts := newServer(t, map[string]string{
+		"/postalCodeSearchJSON?Castlebar": castlebarPostal,
+		"/postalCodeSearchJSON?Westport":  strings.ReplaceAll(strings.ReplaceAll(castlebarPostal, "Castlebar", "Westport"), "F23", "F28"),
+		"/postalCodeSearchJSON?Nowhere":   `{"status":{"message":"invalid parameter","value":14}}`,
+	})
+	stdin := "Castlebar\tIE\n\nNowhere\tIE\nWestport\tIE\n"
+	code, stdout, stderr := runCLI(t, ts, stdin, "postal", "-o", "csv", "-")
+	want := "postalCode,placeName,adminName1,countryCode,lat,lng\n" +
+		"F23,Castlebar,Connacht,IE,53.85583,-9.29778\n" +
+		"F28,Westport,Connacht,IE,53.85583,-9.29778\n"
+	if !cmp.Equal(want, stdout) {
+		t.Error(cmp.Diff(want, stdout))
+	}
+	if !strings.Contains(stderr, "line 3: geonames: invalid parameter (code 14)") {
+		t.Errorf("want failed line reported, got %q", stderr)
+	}
+	if code != exitInvalid {
+		t.Errorf("want exit %d, got %d", exitInvalid, code)
+	}
+}
+
+func TestRun_ReturnsExitCodePerErrorClass(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		status string
+		want   int
+	}{
+		"authorization": {`{"status":{"message":"user does not exist.","value":10}}`, exitAuth},
+		"daily limit":   {`{"status":{"message":"daily limit exceeded","value":18}}`, exitLimit},
+		"overloaded":    {`{"status":{"message":"server overloaded","value":22}}`, exitUnavailable},
+		"no result":     {`{"status":{"message":"no result found","value":15}}`, exitNotFound},
+		"empty":         {`{"postalCodes":[]}`, exitNotFound},
+	}
+	for name, tt := range tests {
+		ts := newServer(t, map[string]string{"/postalCodeSearchJSON?Castlebar": tt.status})
+		if code, _, _ := runCLI(t, ts, "", "postal", "Castlebar"); code != tt.want {
+			t.Errorf("%s: want exit %d, got %d", name, tt.want, code)
+		}
+	}
+}
+
+func TestRun_ReportsUsageErrors(t *testing.T) {
+	t.Parallel()
+
+	ts := newServer(t, nil)
+	tests := [][]string{
+		{},
+		{"unknown"},
+		{"postal"},
+		{"elevation", "north", "west"},
+		{"search", "-o", "xml", "Castlebar"},
+		{"hierarchy", "Castlebar"},
+	}
+	for _, args := range tests {
+		if code, _, _ := runCLI(t, ts, "", args...); code != exitUsage {
+			t.Errorf("%q: want exit %d, got %d", args, exitUsage, code)
+		}
+	}
+
+	var stderr strings.Builder
+	code := run(context.Background(), []string{"postal", "Castlebar"}, strings.NewReader(""), new(strings.Builder), &stderr, func(string) string { return "" })
+	if code != exitUsage || !strings.Contains(stderr.String(), "no username") {
+		t.Errorf("want usage error for missing username, got %d: %s", code, stderr.String())
+	}
+}
+
+func TestRun_AcceptsPositionsInEveryNotation(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.URL.Query().Get("lat")+","+r.URL.Query().Get("lng"))
+		fmt.Fprint(w, `{"timezoneId":"Australia/Sydney","countryCode":"AU","lat":-33.87,"lng":151.21}`)
+	}))
+	defer ts.Close()
+
+	for _, args := range [][]string{
+		{"timezone", "--", "-33.87", "151.21"},
+		{"timezone", "--", "-33.87,151.21"},
+		{"timezone", "33.87S", "151.21E"},
+		{"timezone", "33.87°S 151.21°E"},
+	} {
+		if code, _, stderr := runCLI(t, ts, "", args...); code != exitOK {
+			t.Errorf("%q: want exit 0, got %d: %s", args, code, stderr)
+		}
+	}
+	if want := []string{"-33.87,151.21", "-33.87,151.21", "-33.87,151.21", "-33.87,151.21"}; !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
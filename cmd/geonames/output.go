@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// table is the output of one or more queries: a header, a row of
+// text per record, and the records themselves for JSON output.
+type table struct {
+	header  []string
+	rows    [][]string
+	records []any
+}
+
+func (t *table) add(record any, row ...string) {
+	t.records = append(t.records, record)
+	t.rows = append(t.rows, row)
+}
+
+func (t *table) append(o *table) {
+	if t.header == nil {
+		t.header = o.header
+	}
+	t.rows = append(t.rows, o.rows...)
+	t.records = append(t.records, o.records...)
+}
+
+// writers holds the output formats by name.
+var writers = map[string]func(io.Writer, *table) error{
+	"table":  writeTable,
+	"json":   writeJSON,
+	"ndjson": writeNDJSON,
+	"csv":    writeCSV,
+}
+
+func writeTable(w io.Writer, t *table) error {
+	if len(t.rows) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := make([]string, len(t.header))
+	for i, h := range t.header {
+		header[i] = strings.ToUpper(h)
+	}
+	io.WriteString(tw, strings.Join(header, "\t")+"\n")
+	for _, row := range t.rows {
+		io.WriteString(tw, strings.Join(row, "\t")+"\n")
+	}
+	return tw.Flush()
+}
+
+func writeJSON(w io.Writer, t *table) error {
+	records := t.records
+	if records == nil {
+		records = []any{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+func writeNDJSON(w io.Writer, t *table) error {
+	enc := json.NewEncoder(w)
+	for _, r := range t.records {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, t *table) error {
+	if t.header == nil {
+		return nil
+	}
+	cw := csv.NewWriter(w)
+	cw.Write(t.header)
+	cw.WriteAll(t.rows)
+	return cw.Error()
+}
@@ -0,0 +1,260 @@
+package geonames
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CoordinateFormat selects how Position.Format writes coordinates.
+type CoordinateFormat int
+
+const (
+	// DecimalDegrees writes signed decimal degrees, e.g. "53.85583, -9.29778".
+	DecimalDegrees CoordinateFormat = iota
+	// DegreesMinutesSeconds writes e.g. "53°51′21.0″N 9°17′52.0″W".
+	DegreesMinutesSeconds
+	// DegreesDecimalMinutes writes e.g. "53°51.350′N 9°17.867′W".
+	DegreesDecimalMinutes
+)
+
+// Format returns p written in the given format, latitude first.
+func (p Position) Format(f CoordinateFormat) string {
+	switch f {
+	case DegreesMinutesSeconds:
+		return formatDMS(p.Lat, "N", "S") + " " + formatDMS(p.Lng, "E", "W")
+	case DegreesDecimalMinutes:
+		return formatDDM(p.Lat, "N", "S") + " " + formatDDM(p.Lng, "E", "W")
+	}
+	return strconv.FormatFloat(p.Lat, 'f', -1, 64) + ", " + strconv.FormatFloat(p.Lng, 'f', -1, 64)
+}
+
+func hemisphere(v float64, pos, neg string) string {
+	if v < 0 {
+		return neg
+	}
+	return pos
+}
+
+// formatDMS writes v with seconds rounded to a tenth.
+func formatDMS(v float64, pos, neg string) string {
+	tenths := int64(math.Round(math.Abs(v) * 36000))
+	deg, rest := tenths/36000, tenths%36000
+	return fmt.Sprintf("%d°%02d′%04.1f″%s", deg, rest/600, float64(rest%600)/10, hemisphere(v, pos, neg))
+}
+
+// formatDDM writes v with minutes rounded to a thousandth.
+func formatDDM(v float64, pos, neg string) string {
+	thousandths := int64(math.Round(math.Abs(v) * 60000))
+	deg, rest := thousandths/60000, thousandths%60000
+	return fmt.Sprintf("%d°%06.3f′%s", deg, float64(rest)/1000, hemisphere(v, pos, neg))
+}
+
+// coordToken is a lexical element of a coordinate string.
+type coordToken struct {
+	kind  byte // 'n' number, 'u' unit, 'h' hemisphere, ',' separator
+	text  string
+	unit  int // 0 degrees, 1 minutes, 2 seconds
+	value float64
+}
+
+// ParsePosition parses a latitude and longitude typed by a user. It
+// accepts signed decimal degrees ("53.85583, -9.29778"), hemisphere
+// letters before or after each coordinate ("53.85583N 9.29778W"),
+// degrees, minutes and seconds ("56°49′30″N 5°06′W", "56 49 30 N 5 6 W")
+// and degrees and decimal minutes ("53°51.35′N 9°17.867′W").
+//
+// Latitude comes first unless hemisphere letters show otherwise. The
+// coordinates may be separated by a comma, a semicolon or spaces.
+func ParsePosition(s string) (Position, error) {
+	tokens, err := lexCoordinates(s)
+	if err != nil {
+		return Position{}, fmt.Errorf("parsing position %q: %w", s, err)
+	}
+	first, second, err := splitCoordinates(tokens)
+	if err != nil {
+		return Position{}, fmt.Errorf("parsing position %q: %w", s, err)
+	}
+	a, hemiA, err := parseCoordinate(first)
+	if err != nil {
+		return Position{}, fmt.Errorf("parsing position %q: %w", s, err)
+	}
+	b, hemiB, err := parseCoordinate(second)
+	if err != nil {
+		return Position{}, fmt.Errorf("parsing position %q: %w", s, err)
+	}
+	isLng := func(h string) bool { return h == "E" || h == "W" }
+	isLat := func(h string) bool { return h == "N" || h == "S" }
+	var p Position
+	switch {
+	case isLng(hemiA) && !isLng(hemiB), isLat(hemiB) && !isLat(hemiA):
+		p = Position{Lat: b, Lng: a}
+	case isLat(hemiA) && isLat(hemiB), isLng(hemiA) && isLng(hemiB):
+		return Position{}, fmt.Errorf("parsing position %q: two coordinates in the same axis", s)
+	default:
+		p = Position{Lat: a, Lng: b}
+	}
+	if err := p.Validate(); err != nil {
+		return Position{}, fmt.Errorf("parsing position %q: %w", s, err)
+	}
+	return p, nil
+}
+
+func lexCoordinates(s string) ([]coordToken, error) {
+	var tokens []coordToken
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+		case r == ',' || r == ';':
+			tokens = append(tokens, coordToken{kind: ','})
+		case r == '°' || r == 'º':
+			tokens = append(tokens, coordToken{kind: 'u', unit: 0})
+		case r == '\'' && i+1 < len(rs) && rs[i+1] == '\'':
+			tokens = append(tokens, coordToken{kind: 'u', unit: 2})
+			i++
+		case r == '\'' || r == '′' || r == '’':
+			tokens = append(tokens, coordToken{kind: 'u', unit: 1})
+		case r == '"' || r == '″' || r == '”':
+			tokens = append(tokens, coordToken{kind: 'u', unit: 2})
+		case strings.ContainsRune("NSEWnsew", r):
+			// A letter inside a number, as in "1e3", is no hemisphere.
+			if i > 0 && i+1 < len(rs) && inNumber(rs[i-1]) && inNumber(rs[i+1]) {
+				return nil, fmt.Errorf("unexpected character %q in number", r)
+			}
+			tokens = append(tokens, coordToken{kind: 'h', text: strings.ToUpper(string(r))})
+		case r == '-' || r == '+' || r == '−' || inNumber(r):
+			j := i + 1
+			for j < len(rs) && inNumber(rs[j]) {
+				j++
+			}
+			text := strings.Replace(string(rs[i:j]), "−", "-", 1)
+			v, err := strconv.ParseFloat(text, 64)
+			if err != nil || math.IsInf(v, 0) {
+				return nil, fmt.Errorf("invalid number %q", text)
+			}
+			tokens = append(tokens, coordToken{kind: 'n', text: text, value: v})
+			i = j - 1
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+func inNumber(r rune) bool {
+	return r == '.' || unicode.IsDigit(r)
+}
+
+// splitCoordinates divides tokens into the two coordinates, at a
+// separator if there is one, else after a hemisphere suffix, before a
+// second hemisphere prefix or before a second degree sign.
+func splitCoordinates(tokens []coordToken) ([]coordToken, []coordToken, error) {
+	for i, t := range tokens {
+		if t.kind == ',' {
+			if i == 0 || i == len(tokens)-1 {
+				return nil, nil, errors.New("misplaced separator")
+			}
+			if rest := tokens[i+1:]; hasKind(rest, ',') {
+				return nil, nil, errors.New("more than two coordinates")
+			}
+			return tokens[:i], tokens[i+1:], nil
+		}
+	}
+	numbers, degrees := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.kind == 'h' && i > 0 && numbers > 0:
+			// A suffix ends the first coordinate, unless the
+			// coordinates use prefixes and this is the second one.
+			if tokens[0].kind != 'h' {
+				return tokens[:i+1], tokens[i+1:], nil
+			}
+			return tokens[:i], tokens[i:], nil
+		case t.kind == 'n':
+			numbers++
+			if numbers == 2 && !hasKind(tokens, 'u') && !hasKind(tokens, 'h') {
+				return tokens[:i], tokens[i:], nil
+			}
+		case t.kind == 'u' && t.unit == 0:
+			degrees++
+			if degrees == 2 {
+				// Split before the number of the second degree sign.
+				return tokens[:i-1], tokens[i-1:], nil
+			}
+		}
+	}
+	return nil, nil, errors.New("cannot tell the two coordinates apart")
+}
+
+func hasKind(tokens []coordToken, kind byte) bool {
+	for _, t := range tokens {
+		if t.kind == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCoordinate returns the signed value of a single coordinate and
+// its hemisphere letter, if any.
+func parseCoordinate(tokens []coordToken) (float64, string, error) {
+	var hemi string
+	var parts [3]float64
+	var texts [3]string
+	next := 0 // the unit of the next number without a unit symbol
+	last := -1
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch t.kind {
+		case 'h':
+			if hemi != "" {
+				return 0, "", errors.New("two hemisphere letters in one coordinate")
+			}
+			hemi = t.text
+		case 'u':
+			return 0, "", errors.New("unit without a number")
+		case 'n':
+			unit := next
+			if i+1 < len(tokens) && tokens[i+1].kind == 'u' {
+				unit = tokens[i+1].unit
+				i++
+			}
+			if unit <= last || unit > 2 {
+				return 0, "", fmt.Errorf("unexpected number %q", t.text)
+			}
+			parts[unit], texts[unit] = t.value, t.text
+			last, next = unit, unit+1
+		}
+	}
+	if last < 0 {
+		return 0, "", errors.New("missing number")
+	}
+	for unit := 1; unit <= last; unit++ {
+		if texts[unit] == "" {
+			continue
+		}
+		if strings.ContainsAny(texts[unit-1], ".") {
+			return 0, "", errors.New("only the last component may have decimals")
+		}
+		if strings.ContainsAny(texts[unit], "-+") {
+			return 0, "", fmt.Errorf("unexpected sign in %q", texts[unit])
+		}
+		if parts[unit] >= 60 {
+			return 0, "", fmt.Errorf("%q minutes or seconds out of range", texts[unit])
+		}
+	}
+	negative := strings.HasPrefix(texts[0], "-")
+	if negative && hemi != "" {
+		return 0, "", errors.New("both a sign and a hemisphere letter")
+	}
+	v := math.Abs(parts[0]) + parts[1]/60 + parts[2]/3600
+	if negative || hemi == "S" || hemi == "W" {
+		v = -v
+	}
+	return v, hemi, nil
+}
@@ -0,0 +1,128 @@
+package geonames_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/qba73/geonames"
+)
+
+func TestParsePosition_AcceptsCommonNotations(t *testing.T) {
+	t.Parallel()
+
+	ballachulish := geonames.Position{Lat: 56 + 49.0/60, Lng: -(5 + 6.0/60)}
+	tests := []struct {
+		in   string
+		want geonames.Position
+	}{
+		{"53.85583, -9.29778", castlebar},
+		{"53.85583 -9.29778", castlebar},
+		{"53.85583;-9.29778", castlebar},
+		{"+53.85583 −9.29778", castlebar},
+		{"53.85583N 9.29778W", castlebar},
+		{"53.85583° N, 9.29778° W", castlebar},
+		{"N53.85583 W9.29778", castlebar},
+		{"9.29778W 53.85583N", castlebar},
+		{"56°49′N 5°06′W", ballachulish},
+		{"56°49'N, 5°6'W", ballachulish},
+		{"56º 49' 00\" N 5º 06' 00\" W", ballachulish},
+		{"56 49 0 N 5 6 0 W", ballachulish},
+		{"56°49′ -5°06′", ballachulish},
+		{"56°49.0′N 5°6.0′W", ballachulish},
+		{"53°51′21″N 9°17′52″W", geonames.Position{Lat: 53 + 51.0/60 + 21.0/3600, Lng: -(9 + 17.0/60 + 52.0/3600)}},
+		{"53°51'21''N 9°17'52''W", geonames.Position{Lat: 53 + 51.0/60 + 21.0/3600, Lng: -(9 + 17.0/60 + 52.0/3600)}},
+		{"-33.8568 151.2153", geonames.Position{Lat: -33.8568, Lng: 151.2153}},
+		{"33°51.408′S 151°12.918′E", geonames.Position{Lat: -(33 + 51.408/60), Lng: 151 + 12.918/60}},
+	}
+	for _, tc := range tests {
+		got, err := geonames.ParsePosition(tc.in)
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if !nearPosition(tc.want, got) {
+			t.Errorf("%q: want %v, got %v", tc.in, tc.want, got)
+		}
+	}
+}
+
+func TestParsePosition_RejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{
+		"",
+		"53.85583",
+		"53.8 -9.3 12",
+		"53.8N 9.3N",
+		"-53.8N 9.3W",
+		"53°75′N 9°W",
+		"53.5°30′N 9°W",
+		"Castlebar",
+		"1, 2, 3",
+		"1e3 5",
+		"12.5e",
+		"53.8 9.3e2",
+	} {
+		if got, err := geonames.ParsePosition(in); err == nil {
+			t.Errorf("%q: want error, got %v", in, got)
+		}
+	}
+	if _, err := geonames.ParsePosition("91N 9W"); !errors.Is(err, geonames.ErrInvalidPosition) {
+		t.Errorf("want ErrInvalidPosition, got %v", err)
+	}
+}
+
+func TestPositionFormat_WritesEachFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		format geonames.CoordinateFormat
+		want   string
+	}{
+		{geonames.DecimalDegrees, "53.85583, -9.29778"},
+		{geonames.DegreesMinutesSeconds, "53°51′21.0″N 9°17′52.0″W"},
+		{geonames.DegreesDecimalMinutes, "53°51.350′N 9°17.867′W"},
+	}
+	for _, tc := range tests {
+		got := castlebar.Format(tc.format)
+		if got != tc.want {
+			t.Errorf("want %q, got %q", tc.want, got)
+		}
+		back, err := geonames.ParsePosition(got)
+		if err != nil {
+			t.Errorf("%q does not parse: %v", got, err)
+		} else if !near(back.Lat, castlebar.Lat, 1e-4) || !near(back.Lng, castlebar.Lng, 1e-4) {
+			t.Errorf("%q parses to %v", got, back)
+		}
+	}
+	// Rounding carries into the minutes and degrees.
+	if got := (geonames.Position{Lat: 9.99999, Lng: 0}).Format(geonames.DegreesMinutesSeconds); got != "10°00′00.0″N 0°00′00.0″E" {
+		t.Errorf("want carried rounding, got %q", got)
+	}
+}
+
+func TestPositionGeohash_EncodesAndDecodes(t *testing.T) {
+	t.Parallel()
+
+	p := geonames.Position{Lat: 57.64911, Lng: 10.40744}
+	if got := p.Geohash(11); got != "u4pruydqqvj" {
+		t.Errorf("want u4pruydqqvj, got %q", got)
+	}
+	if got := p.Geohash(99); len(got) != geonames.MaxGeohashPrecision {
+		t.Errorf("want precision clamped to %d, got %q", geonames.MaxGeohashPrecision, got)
+	}
+
+	center, box, err := geonames.ParseGeohash("EZS42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !near(center.Lat, 42.605, 0.001) || !near(center.Lng, -5.603, 0.001) {
+		t.Errorf("want center near 42.605,-5.603, got %v", center)
+	}
+	if !box.Contains(center) || box.North-box.South > 0.05 {
+		t.Errorf("unexpected cell %+v", box)
+	}
+	if _, _, err := geonames.ParseGeohash("u4pa"); err == nil {
+		t.Error("want error for invalid character")
+	}
+}
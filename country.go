@@ -0,0 +1,118 @@
+package geonames
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type countryResponse struct {
+	apiStatus
+	Geonames []countryResult `json:"geonames"`
+}
+
+type countryResult struct {
+	CountryCode   string  `json:"countryCode"`
+	CountryName   string  `json:"countryName"`
+	ISOAlpha3     string  `json:"isoAlpha3"`
+	ISONumeric    string  `json:"isoNumeric"`
+	FIPSCode      string  `json:"fipsCode"`
+	Capital       string  `json:"capital"`
+	Continent     string  `json:"continent"`
+	ContinentName string  `json:"continentName"`
+	AreaInSqKm    float64 `json:"areaInSqKm,string"`
+	Population    int     `json:"population,string"`
+	CurrencyCode  string  `json:"currencyCode"`
+	Languages     string  `json:"languages"`
+	GeoNameID     int     `json:"geonameId"`
+	North         float64 `json:"north"`
+	South         float64 `json:"south"`
+	East          float64 `json:"east"`
+	West          float64 `json:"west"`
+}
+
+func (r *countryResponse) decodeField(dec *json.Decoder, key string) (bool, error) {
+	if key == "geonames" {
+		return true, decodeArray(dec, func(c countryResult) {
+			r.Geonames = append(r.Geonames, c)
+		})
+	}
+	return r.decodeStatus(dec, key)
+}
+
+// Country holds information about a country.
+type Country struct {
+	CountryCode   string
+	CountryName   string
+	ISOAlpha3     string
+	ISONumeric    string
+	FIPSCode      string
+	Capital       string
+	Continent     string
+	ContinentName string
+	// AreaInSqKm is the area in square kilometers.
+	AreaInSqKm   float64
+	Population   int
+	CurrencyCode string
+	// Languages lists language tags ordered by number of speakers.
+	Languages []string
+	GeoNameID int
+	// North, South, East and West bound the country.
+	North, South, East, West float64
+}
+
+func (c Client) buildCountryURL(countries []string) (string, error) {
+	params := url.Values{
+		"username": {c.UserName},
+	}
+	for _, cc := range countries {
+		params.Add("country", cc)
+	}
+	u, err := url.Parse(fmt.Sprintf("%s/countryInfoJSON", c.BaseURL))
+	if err != nil {
+		return "", fmt.Errorf("parsing country info base url: %w", err)
+	}
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// GetCountryInfo returns information about the countries with
+// the given ISO-3166 codes, or about every country if none is given.
+func (c Client) GetCountryInfo(ctx context.Context, countries ...string) ([]Country, error) {
+	u, err := c.buildCountryURL(countries)
+	if err != nil {
+		return nil, err
+	}
+	var cr countryResponse
+	if err := c.get(ctx, u, &cr); err != nil {
+		return nil, err
+	}
+	res := make([]Country, 0, len(cr.Geonames))
+	for _, r := range cr.Geonames {
+		country := Country{
+			CountryCode:   r.CountryCode,
+			CountryName:   r.CountryName,
+			ISOAlpha3:     r.ISOAlpha3,
+			ISONumeric:    r.ISONumeric,
+			FIPSCode:      r.FIPSCode,
+			Capital:       r.Capital,
+			Continent:     r.Continent,
+			ContinentName: r.ContinentName,
+			AreaInSqKm:    r.AreaInSqKm,
+			Population:    r.Population,
+			CurrencyCode:  r.CurrencyCode,
+			GeoNameID:     r.GeoNameID,
+			North:         r.North,
+			South:         r.South,
+			East:          r.East,
+			West:          r.West,
+		}
+		if r.Languages != "" {
+			country.Languages = strings.Split(r.Languages, ",")
+		}
+		res = append(res, country)
+	}
+	return res, nil
+}
@@ -0,0 +1,50 @@
+package geonames_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+)
+
+func TestGetCountryInfo_ReturnsCountriesOnValidInput(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(
+		"geonamestest/fixtures/country.json",
+		"/countryInfoJSON?country=IE&username=DummyUser",
+		t,
+	)
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	got, err := client.GetCountryInfo(context.Background(), "IE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []geonames.Country{{
+		CountryCode:   "IE",
+		CountryName:   "Ireland",
+		ISOAlpha3:     "IRL",
+		ISONumeric:    "372",
+		FIPSCode:      "EI",
+		Capital:       "Dublin",
+		Continent:     "EU",
+		ContinentName: "Europe",
+		AreaInSqKm:    70280,
+		Population:    4994724,
+		CurrencyCode:  "EUR",
+		Languages:     []string{"en-IE", "ga-IE"},
+		GeoNameID:     2963597,
+		North:         55.3872242623,
+		South:         51.4515755725,
+		East:          -5.9966140744,
+		West:          -10.4786596164,
+	}}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
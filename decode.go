@@ -0,0 +1,176 @@
+package geonames
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultMaxResponseSize is the largest response body accepted
+// by a Client that has no MaxResponseSize set.
+const DefaultMaxResponseSize = 10 << 20
+
+// ErrResponseTooLarge is returned when a response body
+// exceeds the client's MaxResponseSize.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// WithMaxResponseSize sets the largest decompressed
+// response body, in bytes, the client accepts.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) error {
+		if n <= 0 {
+			return fmt.Errorf("invalid max response size: %d", n)
+		}
+		c.MaxResponseSize = n
+		return nil
+	}
+}
+
+// decodeResponse decodes a single JSON response read from r into data.
+// If data embeds apiStatus, it returns the APIError carried in the
+// response status envelope, clearing any status left by an earlier
+// response decoded into the same value.
+func decodeResponse(r io.Reader, data any) error {
+	if s, ok := data.(interface{ clearStatus() }); ok {
+		s.clearStatus()
+	}
+	dec := json.NewDecoder(r)
+	var err error
+	if fd, ok := data.(fieldDecoder); ok {
+		err = decodeFields(dec, fd)
+	} else {
+		err = dec.Decode(data)
+	}
+	if err != nil {
+		if errors.Is(err, ErrResponseTooLarge) {
+			return err
+		}
+		return fmt.Errorf("unmarshaling response body: %w", err)
+	}
+	if s, ok := data.(interface{ apiError() *APIError }); ok {
+		if ae := s.apiError(); ae != nil {
+			return ae
+		}
+	}
+	return nil
+}
+
+// fieldDecoder is implemented by responses that decode their fields
+// straight from the token stream, so that long result lists are read
+// one element at a time instead of being buffered whole.
+type fieldDecoder interface {
+	// decodeField decodes the value of the given key and
+	// reports whether the key was recognised.
+	decodeField(dec *json.Decoder, key string) (bool, error)
+}
+
+// decodeFields decodes a JSON object field by field into fd,
+// skipping unrecognised keys.
+func decodeFields(dec *json.Decoder, fd fieldDecoder) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+		ok, err := fd.decodeField(dec, key)
+		if err != nil {
+			return fmt.Errorf("decoding %q: %w", key, err)
+		}
+		if !ok {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// decodeArray decodes a JSON array element by element, passing each to add.
+// A null value is treated as an empty array.
+func decodeArray[T any](dec *json.Decoder, add func(T)) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("want array, got %v", tok)
+	}
+	for dec.More() {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		add(v)
+	}
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("want %v, got %v", want, tok)
+	}
+	return nil
+}
+
+// apiStatus holds the status envelope GeoNames returns in place of
+// the requested data when the request fails. Response types embed it
+// so that the envelope is decoded together with the data.
+type apiStatus struct {
+	Status *APIError `json:"status"`
+}
+
+func (s *apiStatus) apiError() *APIError {
+	return s.Status
+}
+
+func (s *apiStatus) clearStatus() {
+	s.Status = nil
+}
+
+// decodeStatus decodes the status envelope for fieldDecoder implementations.
+// It is deliberately not named decodeField, so that embedding apiStatus
+// does not turn a type into a fieldDecoder.
+func (s *apiStatus) decodeStatus(dec *json.Decoder, key string) (bool, error) {
+	if key != "status" {
+		return false, nil
+	}
+	return true, dec.Decode(&s.Status)
+}
+
+// limitedReader fails with ErrResponseTooLarge
+// once more than max bytes have been read.
+type limitedReader struct {
+	r    io.Reader
+	max  int64
+	read int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.read > l.max {
+		return 0, ErrResponseTooLarge
+	}
+	// Read at most one byte past the limit to tell
+	// a body of exactly max bytes from a larger one.
+	if left := l.max + 1 - l.read; int64(len(p)) > left {
+		p = p[:left]
+	}
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.max {
+		return n - int(l.read-l.max), ErrResponseTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,54 @@
+package geonames
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+)
+
+// readAllDecode is the buffered decoding the client used before
+// switching to decodeResponse. It is kept as a benchmark baseline.
+func readAllDecode(r io.Reader, data any) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, data)
+}
+
+func benchmarkDecode(b *testing.B, testFile string, data func() any, decode func(io.Reader, any) error) {
+	body, err := os.ReadFile(testFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.SetBytes(int64(len(body)))
+	b.ResetTimer()
+	for range b.N {
+		if err := decode(bytes.NewReader(body), data()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	fixtures := []struct {
+		name     string
+		testFile string
+		data     func() any
+	}{
+		{"postal", "geonamestest/fixtures/postal-multiple.json", func() any { return &postalResponse{} }},
+		{"wikipedia", "geonamestest/fixtures/wikipedia.json", func() any { return &wikipediaResponse{} }},
+		{"large", "geonamestest/fixtures/postal-large.json", func() any { return &postalResponse{} }},
+	}
+	for _, f := range fixtures {
+		b.Run(f.name+"/readall", func(b *testing.B) {
+			benchmarkDecode(b, f.testFile, f.data, readAllDecode)
+		})
+		b.Run(f.name+"/stream", func(b *testing.B) {
+			benchmarkDecode(b, f.testFile, f.data, decodeResponse)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package geonames_test
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/qba73/geonames"
+)
+
+func TestGetPostCode_DecodesGzipResponse(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept-Encoding"); got != "gzip" {
+			t.Errorf("want Accept-Encoding gzip, got %q", got)
+		}
+		data, err := os.ReadFile("geonamestest/fixtures/postal-multiple.json")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rw.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(rw)
+		defer zw.Close()
+		zw.Write(data)
+	}))
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	got, err := client.GetPostCode(context.Background(), "Dublin", "IE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 22 {
+		t.Errorf("want 22 postal codes, got %d", len(got))
+	}
+}
+
+func TestGetPostCode_ErrorsOnResponseLargerThanLimit(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		http.ServeFile(rw, r, "geonamestest/fixtures/postal-multiple.json")
+	}))
+	defer ts.Close()
+
+	client, err := geonames.New("DummyUser",
+		geonames.WithBaseURL(ts.URL),
+		geonames.WithMaxResponseSize(1024),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.GetPostCode(context.Background(), "Dublin", "IE")
+	if !errors.Is(err, geonames.ErrResponseTooLarge) {
+		t.Fatalf("want ErrResponseTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package geonames
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	defaultMu     sync.Mutex
+	defaultClient *Client
+)
+
+// ClientFromEnv is a client for the GEONAMES_USER read when the
+// package is initialised. The package-level functions no longer use it.
+//
+// Deprecated: use DefaultClient, which reads the environment on first
+// use, or NewFromEnv.
+var ClientFromEnv = &Client{
+	UserName: os.Getenv("GEONAMES_USER"),
+	BaseURL:  baseURL,
+	HTTPClient: &http.Client{
+		Timeout: 10 * time.Second,
+	},
+	Headers: map[string][]string{
+		"Content-Type": {"application/json"},
+	},
+}
+
+// NewFromEnv creates a client configured from the environment.
+//
+// It reads the username from GEONAMES_USER and, if set, the premium
+// token from GEONAMES_TOKEN, the Web Service URL from GEONAMES_BASE_URL
+// and the request timeout from GEONAMES_TIMEOUT. The timeout is either
+// a duration such as "15s" or a number of seconds. Options given
+// explicitly are applied after the environment settings.
+func NewFromEnv(opts ...Option) (*Client, error) {
+	username := os.Getenv("GEONAMES_USER")
+	if username == "" {
+		return nil, errors.New("GEONAMES_USER is not set")
+	}
+	envOpts := []Option{WithTimeout(10 * time.Second)}
+	if token := os.Getenv("GEONAMES_TOKEN"); token != "" {
+		envOpts = append(envOpts, WithToken(token))
+	}
+	if u := os.Getenv("GEONAMES_BASE_URL"); u != "" {
+		envOpts = append(envOpts, WithBaseURL(u))
+	}
+	if s := os.Getenv("GEONAMES_TIMEOUT"); s != "" {
+		d, err := parseTimeout(s)
+		if err != nil {
+			return nil, fmt.Errorf("parsing GEONAMES_TIMEOUT: %w", err)
+		}
+		envOpts = append(envOpts, WithTimeout(d))
+	}
+	return New(username, append(envOpts, opts...)...)
+}
+
+func parseTimeout(s string) (time.Duration, error) {
+	if secs, err := strconv.Atoi(s); err == nil {
+		return time.Duration(secs) * time.Second, nil
+	}
+	return time.ParseDuration(s)
+}
+
+// DefaultClient returns the client used by the package-level
+// functions such as GetPostCode and GetPlace.
+//
+// Unless one was set with SetDefaultClient, the client is built
+// with NewFromEnv on first use. A failed build is not remembered,
+// so the environment is read again on the next call.
+func DefaultClient() (*Client, error) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	if defaultClient != nil {
+		return defaultClient, nil
+	}
+	c, err := NewFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("creating default client: %w", err)
+	}
+	defaultClient = c
+	return c, nil
+}
+
+// SetDefaultClient replaces the client used by the package-level
+// functions. Passing nil makes the next call read the environment again.
+func SetDefaultClient(c *Client) {
+	defaultMu.Lock()
+	defer defaultMu.Unlock()
+	defaultClient = c
+}
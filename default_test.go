@@ -0,0 +1,100 @@
+package geonames_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qba73/geonames"
+)
+
+func TestNewFromEnv_ReadsConfigurationFromEnvironment(t *testing.T) {
+	t.Setenv("GEONAMES_USER", "DummyUser")
+	t.Setenv("GEONAMES_TOKEN", "secret")
+	t.Setenv("GEONAMES_BASE_URL", "https://secure.geonames.net")
+	t.Setenv("GEONAMES_TIMEOUT", "30")
+
+	c, err := geonames.NewFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.UserName != "DummyUser" || c.Token != "secret" || c.BaseURL != "https://secure.geonames.net" {
+		t.Errorf("environment not applied: %+v", c)
+	}
+	if c.HTTPClient.Timeout != 30*time.Second {
+		t.Errorf("want timeout 30s, got %v", c.HTTPClient.Timeout)
+	}
+}
+
+func TestNewFromEnv_ErrorsOnInvalidTimeout(t *testing.T) {
+	t.Setenv("GEONAMES_USER", "DummyUser")
+	t.Setenv("GEONAMES_TIMEOUT", "soon")
+
+	if _, err := geonames.NewFromEnv(); err == nil {
+		t.Fatal("want error on invalid timeout")
+	}
+}
+
+func TestGetPostCode_ErrorsWithoutUsernameInEnvironment(t *testing.T) {
+	geonames.SetDefaultClient(nil)
+	t.Cleanup(func() { geonames.SetDefaultClient(nil) })
+	t.Setenv("GEONAMES_USER", "")
+
+	if _, err := geonames.GetPostCode("Castlebar", "IE"); err == nil {
+		t.Fatal("want error without GEONAMES_USER")
+	}
+}
+
+func TestGetPostCode_ReadsEnvironmentOnFirstUse(t *testing.T) {
+	ts := newTestServer(
+		"geonamestest/fixtures/postal-single.json",
+		"/postalCodeSearchJSON?country=IE&placename=Castlebar&username=LateUser",
+		t,
+	)
+	defer ts.Close()
+
+	geonames.SetDefaultClient(nil)
+	t.Cleanup(func() { geonames.SetDefaultClient(nil) })
+	t.Setenv("GEONAMES_USER", "LateUser")
+	t.Setenv("GEONAMES_BASE_URL", ts.URL)
+
+	got, err := geonames.GetPostCode("Castlebar", "IE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("want one postal code, got %v", got)
+	}
+}
+
+func TestGetPlace_UsesClientSetWithSetDefaultClient(t *testing.T) {
+	ts := newTestServer(
+		"geonamestest/fixtures/wikipedia-single.json",
+		"/wikipediaSearchJSON?q=Castlebar&title=Castlebar&countryCode=IE&maxRows=1&username=CustomUser",
+		t,
+	)
+	defer ts.Close()
+
+	c, err := geonames.New("CustomUser", geonames.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	geonames.SetDefaultClient(c)
+	t.Cleanup(func() { geonames.SetDefaultClient(nil) })
+
+	got, err := geonames.GetPlace("Castlebar", "IE", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("want one geoname, got %v", got)
+	}
+}
+
+func TestClientFromEnv_RemainsUsable(t *testing.T) {
+	t.Parallel()
+
+	c := geonames.ClientFromEnv
+	if c == nil || c.BaseURL == "" || c.HTTPClient == nil {
+		t.Fatalf("want deprecated ClientFromEnv ready to use, got %+v", c)
+	}
+}
@@ -0,0 +1,51 @@
+package dump
+
+import (
+	"io"
+	"iter"
+	"strconv"
+)
+
+// AlternateName is a record of alternateNamesV2.txt.
+type AlternateName struct {
+	ID        int64
+	GeoNameID int64
+	// Language is an ISO 639 language code, or a pseudo code
+	// such as "post", "iata", "link" or "wkdt". It may be empty.
+	Language     string
+	Name         string
+	IsPreferred  bool
+	IsShort      bool
+	IsColloquial bool
+	IsHistoric   bool
+	// From and To give the period the name was used in, if known.
+	From string
+	To   string
+}
+
+// AlternateNames returns an iterator over the records of
+// alternateNamesV2.txt read from r.
+func AlternateNames(r io.Reader) iter.Seq2[AlternateName, error] {
+	return records(r, format{columns: 10}, parseAlternateName)
+}
+
+func parseAlternateName(tr *tsvReader, f []string) (AlternateName, error) {
+	a := AlternateName{
+		Language:     f[2],
+		Name:         f[3],
+		IsPreferred:  f[4] == "1",
+		IsShort:      f[5] == "1",
+		IsColloquial: f[6] == "1",
+		IsHistoric:   f[7] == "1",
+		From:         f[8],
+		To:           f[9],
+	}
+	var err error
+	if a.ID, err = strconv.ParseInt(f[0], 10, 64); err != nil {
+		return AlternateName{}, tr.errorf("alternateNameId: %w", err)
+	}
+	if a.GeoNameID, err = strconv.ParseInt(f[1], 10, 64); err != nil {
+		return AlternateName{}, tr.errorf("geonameid: %w", err)
+	}
+	return a, nil
+}
@@ -0,0 +1,31 @@
+package dump_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames/dump"
+)
+
+func TestAlternateNames_ParsesFlagsAndPeriods(t *testing.T) {
+	t.Parallel()
+
+	got := collect(t, "testdata/alternateNamesV2.txt", dump.AlternateNames)
+	if len(got) != 8 {
+		t.Fatalf("want 8 alternate names, got %d", len(got))
+	}
+	want := []dump.AlternateName{
+		{ID: 1620502, GeoNameID: 2964574, Language: "en", Name: "Dublin", IsPreferred: true, IsShort: true},
+		{ID: 1620503, GeoNameID: 2964574, Name: "Dyflin", IsHistoric: true},
+	}
+	if !cmp.Equal(want, got[1:3]) {
+		t.Error(cmp.Diff(want, got[1:3]))
+	}
+	last := got[7]
+	if !last.IsHistoric || last.From != "1821" || last.To != "1920" {
+		t.Errorf("want historic name used from 1821 to 1920, got %+v", last)
+	}
+	if !got[6].IsColloquial {
+		t.Errorf("want colloquial name, got %+v", got[6])
+	}
+}
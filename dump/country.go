@@ -0,0 +1,73 @@
+package dump
+
+import (
+	"io"
+	"iter"
+	"strconv"
+)
+
+// CountryInfo is a record of countryInfo.txt.
+type CountryInfo struct {
+	ISO        string
+	ISO3       string
+	ISONumeric string
+	FIPS       string
+	Name       string
+	Capital    string
+	// Area is the area in square kilometers.
+	Area       float64
+	Population int64
+	Continent  string
+	TLD        string
+	// CurrencyCode is the ISO 4217 currency code.
+	CurrencyCode string
+	CurrencyName string
+	Phone        string
+	// PostalCodeFormat uses # for digits and @ for letters.
+	PostalCodeFormat string
+	PostalCodeRegex  string
+	// Languages lists language tags ordered by number of speakers.
+	Languages []string
+	GeoNameID int64
+	// Neighbours lists the ISO codes of bordering countries.
+	Neighbours         []string
+	EquivalentFIPSCode string
+}
+
+// CountryInfos returns an iterator over the records of countryInfo.txt
+// read from r, skipping the comment header.
+func CountryInfos(r io.Reader) iter.Seq2[CountryInfo, error] {
+	return records(r, format{columns: 19, comment: "#"}, func(tr *tsvReader, f []string) (CountryInfo, error) {
+		c := CountryInfo{
+			ISO:                f[0],
+			ISO3:               f[1],
+			ISONumeric:         f[2],
+			FIPS:               f[3],
+			Name:               f[4],
+			Capital:            f[5],
+			Continent:          f[8],
+			TLD:                f[9],
+			CurrencyCode:       f[10],
+			CurrencyName:       f[11],
+			Phone:              f[12],
+			PostalCodeFormat:   f[13],
+			PostalCodeRegex:    f[14],
+			Languages:          splitList(f[15]),
+			Neighbours:         splitList(f[17]),
+			EquivalentFIPSCode: f[18],
+		}
+		var err error
+		if f[6] != "" {
+			if c.Area, err = strconv.ParseFloat(f[6], 64); err != nil {
+				return CountryInfo{}, tr.errorf("area: %w", err)
+			}
+		}
+		if c.Population, err = parseInt64(f[7]); err != nil {
+			return CountryInfo{}, tr.errorf("population: %w", err)
+		}
+		if c.GeoNameID, err = parseInt64(f[16]); err != nil {
+			return CountryInfo{}, tr.errorf("geonameid: %w", err)
+		}
+		return c, nil
+	})
+}
@@ -0,0 +1,29 @@
+package dump_test
+
+import (
+	"testing"
+
+	"github.com/qba73/geonames/dump"
+)
+
+func TestCountryInfos_SkipsCommentHeader(t *testing.T) {
+	t.Parallel()
+
+	got := collect(t, "testdata/countryInfo.txt", dump.CountryInfos)
+	if len(got) != 2 {
+		t.Fatalf("want 2 countries, got %d", len(got))
+	}
+	ie := got[1]
+	if ie.ISO != "IE" || ie.ISO3 != "IRL" || ie.Name != "Ireland" || ie.Capital != "Dublin" {
+		t.Errorf("unexpected country: %+v", ie)
+	}
+	if ie.Area != 70280 || ie.Population != 4853506 || ie.GeoNameID != 2963597 {
+		t.Errorf("unexpected numbers: area %v, population %d, geonameid %d", ie.Area, ie.Population, ie.GeoNameID)
+	}
+	if len(ie.Languages) != 2 || ie.Languages[1] != "ga-IE" {
+		t.Errorf("want languages en-IE,ga-IE, got %v", ie.Languages)
+	}
+	if len(ie.Neighbours) != 1 || ie.Neighbours[0] != "GB" {
+		t.Errorf("want neighbour GB, got %v", ie.Neighbours)
+	}
+}
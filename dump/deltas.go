@@ -0,0 +1,53 @@
+package dump
+
+import (
+	"io"
+	"iter"
+	"strconv"
+)
+
+// Deletion is a record of the daily deletes-YYYY-MM-DD.txt
+// files, listing places removed from the database.
+type Deletion struct {
+	GeoNameID int64
+	Name      string
+	// Comment gives the reason for the deletion, e.g. "duplicate".
+	Comment string
+}
+
+// Deletions returns an iterator over the records of
+// a deletes-YYYY-MM-DD.txt file read from r.
+func Deletions(r io.Reader) iter.Seq2[Deletion, error] {
+	return records(r, format{columns: 3}, func(tr *tsvReader, f []string) (Deletion, error) {
+		id, err := strconv.ParseInt(f[0], 10, 64)
+		if err != nil {
+			return Deletion{}, tr.errorf("geonameid: %w", err)
+		}
+		return Deletion{GeoNameID: id, Name: f[1], Comment: f[2]}, nil
+	})
+}
+
+// AlternateNameDeletion is a record of the daily
+// alternateNamesDeletes-YYYY-MM-DD.txt files.
+type AlternateNameDeletion struct {
+	ID        int64
+	GeoNameID int64
+	Name      string
+	Comment   string
+}
+
+// AlternateNameDeletions returns an iterator over the records of
+// an alternateNamesDeletes-YYYY-MM-DD.txt file read from r.
+func AlternateNameDeletions(r io.Reader) iter.Seq2[AlternateNameDeletion, error] {
+	return records(r, format{columns: 4}, func(tr *tsvReader, f []string) (AlternateNameDeletion, error) {
+		d := AlternateNameDeletion{Name: f[2], Comment: f[3]}
+		var err error
+		if d.ID, err = strconv.ParseInt(f[0], 10, 64); err != nil {
+			return AlternateNameDeletion{}, tr.errorf("alternateNameId: %w", err)
+		}
+		if d.GeoNameID, err = strconv.ParseInt(f[1], 10, 64); err != nil {
+			return AlternateNameDeletion{}, tr.errorf("geonameid: %w", err)
+		}
+		return d, nil
+	})
+}
@@ -0,0 +1,47 @@
+package dump_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames/dump"
+)
+
+func TestDeletions_ParsesDailyDeletesFile(t *testing.T) {
+	t.Parallel()
+
+	got := collect(t, "testdata/updates/deletes-2026-10-16.txt", dump.Deletions)
+	want := []dump.Deletion{
+		{GeoNameID: 2965768, Name: "Belmullet", Comment: "duplicate of 2965769"},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestAlternateNameDeletions_ParsesDailyDeletesFile(t *testing.T) {
+	t.Parallel()
+
+	got := collect(t, "testdata/updates/alternateNamesDeletes-2026-10-16.txt", dump.AlternateNameDeletions)
+	want := []dump.AlternateNameDeletion{
+		{ID: 3012345, GeoNameID: 2964574, Name: "The Big Smoke"},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestDeletions_ReportsInvalidID(t *testing.T) {
+	t.Parallel()
+
+	var errs int
+	for _, err := range dump.Deletions(strings.NewReader("x\tBelmullet\t\n")) {
+		if err != nil {
+			errs++
+		}
+	}
+	if errs != 1 {
+		t.Errorf("want 1 error, got %d", errs)
+	}
+}
@@ -0,0 +1,131 @@
+// Package dump parses the tab-separated data files published at
+// https://download.geonames.org/export/dump/.
+//
+// The parsers stream records one line at a time, so memory use does not
+// depend on the size of the file. Files can be read either as plain text
+// or straight from the zip archives they are distributed in.
+package dump
+
+import (
+	"archive/zip"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxLineLength is the longest line the parsers accept. Lines of
+// allCountries.txt with many alternate names exceed bufio's default.
+const maxLineLength = 1 << 20
+
+// ParseError reports a line that could not be parsed.
+type ParseError struct {
+	// Line is the one-based line number in the file.
+	Line int
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// Open opens a dump file for reading. If path names a zip archive, the
+// returned reader reads the text file of the same base name inside it,
+// e.g. IE.txt in IE.zip, or the only .txt file if there is no such entry.
+func Open(path string) (io.ReadCloser, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		return os.Open(path)
+	}
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := findEntry(zr.File, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))+".txt")
+	if err != nil {
+		zr.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		zr.Close()
+		return nil, err
+	}
+	return &zipEntry{ReadCloser: rc, archive: zr}, nil
+}
+
+func findEntry(files []*zip.File, name string) (*zip.File, error) {
+	var txt []*zip.File
+	for _, f := range files {
+		base := filepath.Base(f.Name)
+		if strings.EqualFold(base, name) {
+			return f, nil
+		}
+		if strings.EqualFold(filepath.Ext(base), ".txt") && !strings.EqualFold(base, "readme.txt") {
+			txt = append(txt, f)
+		}
+	}
+	if len(txt) != 1 {
+		return nil, fmt.Errorf("no entry named %s", name)
+	}
+	return txt[0], nil
+}
+
+// zipEntry closes the archive together with the entry.
+type zipEntry struct {
+	io.ReadCloser
+	archive *zip.ReadCloser
+}
+
+func (z *zipEntry) Close() error {
+	return errors.Join(z.ReadCloser.Close(), z.archive.Close())
+}
+
+// tsvReader reads tab-separated records, keeping track of line numbers.
+type tsvReader struct {
+	s    *bufio.Scanner
+	line int
+	// comment, if set, skips lines starting with it.
+	comment string
+}
+
+func newTSVReader(r io.Reader) *tsvReader {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+	return &tsvReader{s: s}
+}
+
+// next returns the fields of the next non-empty line, or io.EOF.
+func (r *tsvReader) next() ([]string, error) {
+	for r.s.Scan() {
+		r.line++
+		line := strings.TrimSuffix(r.s.Text(), "\r")
+		if line == "" || (r.comment != "" && strings.HasPrefix(line, r.comment)) {
+			continue
+		}
+		return strings.Split(line, "\t"), nil
+	}
+	if err := r.s.Err(); err != nil {
+		return nil, fmt.Errorf("line %d: %w", r.line+1, err)
+	}
+	return nil, io.EOF
+}
+
+// errorf returns a ParseError for the current line.
+func (r *tsvReader) errorf(format string, args ...any) error {
+	return &ParseError{Line: r.line, Err: fmt.Errorf(format, args...)}
+}
+
+// wantFields checks that a record has exactly n fields.
+func (r *tsvReader) wantFields(fields []string, n int) error {
+	if len(fields) != n {
+		return r.errorf("want %d columns, got %d", n, len(fields))
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package dump
+
+import (
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qba73/geonames"
+)
+
+// placeColumns is the number of columns in the main
+// dump files such as allCountries.txt and cities500.txt.
+const placeColumns = 19
+
+// Place is a record of the main GeoNames dump files.
+type Place struct {
+	GeoNameID int64
+	// Name is the name of the place in UTF-8.
+	Name string
+	// ASCIIName is the name in plain ASCII characters.
+	ASCIIName string
+	// AlternateNames lists the alternate names of the place.
+	// See the alternateNames tables for their languages.
+	AlternateNames []string
+	Position       geonames.Position
+	FeatureClass   string
+	FeatureCode    string
+	CountryCode    string
+	// CC2 lists alternate country codes.
+	CC2        []string
+	Admin1Code string
+	Admin2Code string
+	Admin3Code string
+	Admin4Code string
+	Population int64
+	// Elevation is the elevation in meters, or zero if unknown.
+	Elevation int
+	// DEM is the digital elevation model (srtm3 or gtopo30) in meters.
+	DEM int
+	// Timezone is the IANA time zone ID.
+	Timezone string
+	// Modified is the date of the last modification of the record.
+	Modified time.Time
+}
+
+// PlaceReader reads Place records from a main dump file.
+type PlaceReader struct {
+	r *tsvReader
+}
+
+// NewPlaceReader creates a PlaceReader reading from r.
+func NewPlaceReader(r io.Reader) *PlaceReader {
+	return &PlaceReader{r: newTSVReader(r)}
+}
+
+// Read returns the next place. It returns io.EOF at the end of input and
+// a *ParseError for a malformed line, after which reading may continue.
+func (r *PlaceReader) Read() (Place, error) {
+	fields, err := r.r.next()
+	if err != nil {
+		return Place{}, err
+	}
+	return parsePlace(r.r, fields)
+}
+
+// All returns an iterator over the remaining places. Parse errors are
+// yielded and iteration continues; other errors end it.
+func (r *PlaceReader) All() iter.Seq2[Place, error] {
+	return all(r.Read)
+}
+
+// Places returns an iterator over the places in the dump file at path,
+// which may be a zip archive. The file is closed when iteration ends.
+func Places(path string) iter.Seq2[Place, error] {
+	return func(yield func(Place, error) bool) {
+		f, err := Open(path)
+		if err != nil {
+			yield(Place{}, err)
+			return
+		}
+		defer f.Close()
+		NewPlaceReader(f).All()(yield)
+	}
+}
+
+func parsePlace(r *tsvReader, f []string) (Place, error) {
+	if err := r.wantFields(f, placeColumns); err != nil {
+		return Place{}, err
+	}
+	p := Place{
+		Name:           f[1],
+		ASCIIName:      f[2],
+		AlternateNames: splitList(f[3]),
+		FeatureClass:   f[6],
+		FeatureCode:    f[7],
+		CountryCode:    f[8],
+		CC2:            splitList(f[9]),
+		Admin1Code:     f[10],
+		Admin2Code:     f[11],
+		Admin3Code:     f[12],
+		Admin4Code:     f[13],
+		Timezone:       f[17],
+	}
+	var err error
+	if p.GeoNameID, err = strconv.ParseInt(f[0], 10, 64); err != nil {
+		return Place{}, r.errorf("geonameid: %w", err)
+	}
+	if p.Position.Lat, err = strconv.ParseFloat(f[4], 64); err != nil {
+		return Place{}, r.errorf("latitude: %w", err)
+	}
+	if p.Position.Lng, err = strconv.ParseFloat(f[5], 64); err != nil {
+		return Place{}, r.errorf("longitude: %w", err)
+	}
+	if p.Population, err = parseInt64(f[14]); err != nil {
+		return Place{}, r.errorf("population: %w", err)
+	}
+	if p.Elevation, err = parseInt(f[15]); err != nil {
+		return Place{}, r.errorf("elevation: %w", err)
+	}
+	if p.DEM, err = parseInt(f[16]); err != nil {
+		return Place{}, r.errorf("dem: %w", err)
+	}
+	if p.Modified, err = parseDate(f[18]); err != nil {
+		return Place{}, r.errorf("modification date: %w", err)
+	}
+	return p, nil
+}
+
+// all adapts a Read method to an iterator.
+func all[T any](read func() (T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			v, err := read()
+			if err == io.EOF {
+				return
+			}
+			if !yield(v, err) {
+				return
+			}
+			if _, ok := err.(*ParseError); err != nil && !ok {
+				return
+			}
+		}
+	}
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+func parseInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func parseInt64(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
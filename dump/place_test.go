@@ -0,0 +1,141 @@
+package dump_test
+
+import (
+	"archive/zip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/dump"
+)
+
+// zipFile is a test helper that stores the given files in a zip archive at path.
+func zipFile(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, src := range files {
+		data, err := os.ReadFile(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlaces_ParsesAllColumns(t *testing.T) {
+	t.Parallel()
+
+	var got []dump.Place
+	for p, err := range dump.Places("testdata/IE.txt") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, p)
+	}
+	if len(got) != 12 {
+		t.Fatalf("want 12 places, got %d", len(got))
+	}
+
+	want := dump.Place{
+		GeoNameID:      2965347,
+		Name:           "Croagh Patrick",
+		ASCIIName:      "Croagh Patrick",
+		AlternateNames: []string{"Cruach Phadraig", "Cruach Phádraig", "The Reek"},
+		Position:       geonames.Position{Lat: 53.75972, Lng: -9.65861},
+		FeatureClass:   "T",
+		FeatureCode:    "MT",
+		CountryCode:    "IE",
+		Admin1Code:     "C",
+		Admin2Code:     "20",
+		Elevation:      764,
+		DEM:            722,
+		Timezone:       "Europe/Dublin",
+		Modified:       time.Date(2017, 6, 14, 0, 0, 0, 0, time.UTC),
+	}
+	if !cmp.Equal(want, got[8]) {
+		t.Error(cmp.Diff(want, got[8]))
+	}
+}
+
+func TestPlaces_ReadsTextFileFromZipArchive(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "IE.zip")
+	zipFile(t, path, map[string]string{
+		"readme.txt": "testdata/IE.txt",
+		"IE.txt":     "testdata/IE.txt",
+	})
+
+	var n int
+	for _, err := range dump.Places(path) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 12 {
+		t.Errorf("want 12 places, got %d", n)
+	}
+}
+
+func TestPlaceReader_ReportsLineNumberOfMalformedLineAndContinues(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("testdata/IE.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(string(data), "\n")
+	lines[2] = strings.Replace(lines[2], "51.89797", "north", 1)
+
+	r := dump.NewPlaceReader(strings.NewReader(strings.Join(lines, "\n")))
+	var places int
+	var errs []error
+	for _, err := range r.All() {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		places++
+	}
+	if places != 11 {
+		t.Errorf("want 11 places, got %d", places)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("want one error, got %v", errs)
+	}
+	var pe *dump.ParseError
+	if !errors.As(errs[0], &pe) || pe.Line != 3 {
+		t.Errorf("want parse error on line 3, got %v", errs[0])
+	}
+}
+
+func TestPlaceReader_RejectsLineWithMissingColumns(t *testing.T) {
+	t.Parallel()
+
+	r := dump.NewPlaceReader(strings.NewReader("2965654\tCastlebar\tCastlebar\n"))
+	_, err := r.Read()
+	var pe *dump.ParseError
+	if !errors.As(err, &pe) || pe.Line != 1 {
+		t.Errorf("want parse error on line 1, got %v", err)
+	}
+}
@@ -0,0 +1,71 @@
+package dump
+
+import (
+	"io"
+	"iter"
+	"strconv"
+
+	"github.com/qba73/geonames"
+)
+
+// PostalCode is a record of the postal code dump files, such as
+// postal_codes/allCountries.txt or the full-format GB_full.txt.
+type PostalCode struct {
+	CountryCode string
+	PostalCode  string
+	PlaceName   string
+	AdminName1  string
+	AdminCode1  string
+	AdminName2  string
+	AdminCode2  string
+	AdminName3  string
+	AdminCode3  string
+	Position    geonames.Position
+	// Accuracy of the position: 1 estimated, 4 geonameid,
+	// 6 centroid of addresses or shape. Zero if unknown.
+	Accuracy int
+}
+
+// PostalCodes returns an iterator over the records
+// of a postal code dump file read from r.
+func PostalCodes(r io.Reader) iter.Seq2[PostalCode, error] {
+	return records(r, format{columns: 12}, func(tr *tsvReader, f []string) (PostalCode, error) {
+		pc := PostalCode{
+			CountryCode: f[0],
+			PostalCode:  f[1],
+			PlaceName:   f[2],
+			AdminName1:  f[3],
+			AdminCode1:  f[4],
+			AdminName2:  f[5],
+			AdminCode2:  f[6],
+			AdminName3:  f[7],
+			AdminCode3:  f[8],
+		}
+		var err error
+		if pc.Position.Lat, err = strconv.ParseFloat(f[9], 64); err != nil {
+			return PostalCode{}, tr.errorf("latitude: %w", err)
+		}
+		if pc.Position.Lng, err = strconv.ParseFloat(f[10], 64); err != nil {
+			return PostalCode{}, tr.errorf("longitude: %w", err)
+		}
+		if pc.Accuracy, err = parseInt(f[11]); err != nil {
+			return PostalCode{}, tr.errorf("accuracy: %w", err)
+		}
+		return pc, nil
+	})
+}
+
+// PostalCodesFile returns an iterator over the records of the postal
+// code dump file at path, which may be a zip archive. The file is
+// closed when iteration ends.
+func PostalCodesFile(path string) iter.Seq2[PostalCode, error] {
+	return func(yield func(PostalCode, error) bool) {
+		f, err := Open(path)
+		if err != nil {
+			yield(PostalCode{}, err)
+			return
+		}
+		defer f.Close()
+		PostalCodes(f)(yield)
+	}
+}
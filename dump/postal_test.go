@@ -0,0 +1,40 @@
+package dump_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/dump"
+)
+
+func TestPostalCodes_ParsesAllColumns(t *testing.T) {
+	t.Parallel()
+
+	var got []dump.PostalCode
+	for pc, err := range dump.PostalCodesFile("testdata/GB_full.txt") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, pc)
+	}
+	if len(got) != 3 {
+		t.Fatalf("want 3 postal codes, got %d", len(got))
+	}
+	want := dump.PostalCode{
+		CountryCode: "GB",
+		PostalCode:  "PH33 6SY",
+		PlaceName:   "Fort William",
+		AdminName1:  "Scotland",
+		AdminCode1:  "SCT",
+		AdminName2:  "Highland",
+		AdminCode2:  "11",
+		AdminName3:  "Highland",
+		AdminCode3:  "S12000017",
+		Position:    geonames.Position{Lat: 56.8183, Lng: -5.1127},
+		Accuracy:    6,
+	}
+	if !cmp.Equal(want, got[0]) {
+		t.Error(cmp.Diff(want, got[0]))
+	}
+}
@@ -0,0 +1,189 @@
+package dump
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Tables holds the lookup tables needed to describe a Place with
+// human-readable names and to place it in the administrative
+// hierarchy. Alternate names are too many to hold here; read them
+// with AlternateNames or index them with package gazetteer.
+type Tables struct {
+	// Admin1 is keyed by the concatenated code, e.g. "IE.C".
+	Admin1 map[string]AdminCode
+	// Admin2 is keyed by the concatenated code, e.g. "IE.C.20".
+	Admin2 map[string]AdminCode
+	// Countries is keyed by the ISO country code.
+	Countries map[string]CountryInfo
+	// Features is keyed by class and code, e.g. "P.PPLC".
+	Features map[string]FeatureCode
+	// TimeZones is keyed by the time zone ID.
+	TimeZones map[string]TimeZone
+	// Languages is keyed by each of the ISO 639-3,
+	// 639-2 and 639-1 codes of a language.
+	Languages map[string]LanguageCode
+	// Parents maps a GeoNames ID to the ID of its parent
+	// in the administrative ("ADM") hierarchy.
+	Parents map[int64]int64
+}
+
+// Table file names as published on the GeoNames download server.
+const (
+	Admin1File        = "admin1CodesASCII.txt"
+	Admin2File        = "admin2Codes.txt"
+	CountryInfoFile   = "countryInfo.txt"
+	FeatureCodesFile  = "featureCodes_en.txt"
+	TimeZonesFile     = "timeZones.txt"
+	LanguageCodesFile = "iso-languagecodes.txt"
+	HierarchyFile     = "hierarchy.txt"
+)
+
+// LoadTables reads the lookup tables found in dir. Missing files are
+// skipped and leave the corresponding map empty.
+func LoadTables(dir string) (*Tables, error) {
+	t := &Tables{}
+	var err error
+	if t.Admin1, err = loadTable(dir, Admin1File, AdminCodes, func(a AdminCode) string { return a.Code }); err != nil {
+		return nil, err
+	}
+	if t.Admin2, err = loadTable(dir, Admin2File, AdminCodes, func(a AdminCode) string { return a.Code }); err != nil {
+		return nil, err
+	}
+	if t.Countries, err = loadTable(dir, CountryInfoFile, CountryInfos, func(c CountryInfo) string { return c.ISO }); err != nil {
+		return nil, err
+	}
+	if t.Features, err = loadTable(dir, FeatureCodesFile, FeatureCodes, FeatureCode.Key); err != nil {
+		return nil, err
+	}
+	if t.TimeZones, err = loadTable(dir, TimeZonesFile, TimeZones, func(tz TimeZone) string { return tz.ID }); err != nil {
+		return nil, err
+	}
+	if t.Languages, err = loadLanguages(filepath.Join(dir, LanguageCodesFile)); err != nil {
+		return nil, err
+	}
+	if t.Parents, err = loadParents(filepath.Join(dir, HierarchyFile)); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func loadTable[T any](dir, name string, parse func(io.Reader) iter.Seq2[T, error], key func(T) string) (map[string]T, error) {
+	f, err := os.Open(filepath.Join(dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]T{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := Index(parse(f), key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return m, nil
+}
+
+func loadLanguages(path string) (map[string]LanguageCode, error) {
+	m := make(map[string]LanguageCode)
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	for l, err := range LanguageCodes(f) {
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", LanguageCodesFile, err)
+		}
+		for _, code := range []string{l.ISO6393, l.ISO6392, l.ISO6391} {
+			if code != "" {
+				m[code] = l
+			}
+		}
+	}
+	return m, nil
+}
+
+func loadParents(path string) (map[int64]int64, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[int64]int64{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	m, err := Parents(Hierarchy(f))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", HierarchyFile, err)
+	}
+	return m, nil
+}
+
+// Parents collects the administrative ("ADM") links of hierarchy.txt
+// into a map from each child to its parent.
+func Parents(seq iter.Seq2[HierarchyLink, error]) (map[int64]int64, error) {
+	parents := make(map[int64]int64)
+	for h, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		if h.Type == "ADM" {
+			parents[h.ChildID] = h.ParentID
+		}
+	}
+	return parents, nil
+}
+
+// Ancestors returns the IDs of the administrative ancestors of the
+// place with the given ID, from the top down, following Parents.
+func (t *Tables) Ancestors(id int64) []int64 {
+	var ids []int64
+	seen := map[int64]bool{id: true}
+	for parent, ok := t.Parents[id]; ok && !seen[parent]; parent, ok = t.Parents[parent] {
+		seen[parent] = true
+		ids = append(ids, parent)
+	}
+	slices.Reverse(ids)
+	return ids
+}
+
+// PlaceNames holds the human-readable names of the codes in a Place.
+// Names not found in the tables are left empty.
+type PlaceNames struct {
+	CountryName string
+	Admin1Name  string
+	Admin2Name  string
+	FeatureName string
+	// FeatureDescription may be empty even if FeatureName is set.
+	FeatureDescription string
+}
+
+// Resolve looks up the names of the country, administrative
+// divisions and feature of p.
+func (t *Tables) Resolve(p Place) PlaceNames {
+	var n PlaceNames
+	if c, ok := t.Countries[p.CountryCode]; ok {
+		n.CountryName = c.Name
+	}
+	if a, ok := t.Admin1[p.CountryCode+"."+p.Admin1Code]; ok {
+		n.Admin1Name = a.Name
+	}
+	if a, ok := t.Admin2[p.CountryCode+"."+p.Admin1Code+"."+p.Admin2Code]; ok {
+		n.Admin2Name = a.Name
+	}
+	if fc, ok := t.Features[p.FeatureClass+"."+p.FeatureCode]; ok {
+		n.FeatureName = fc.Name
+		n.FeatureDescription = fc.Description
+	}
+	return n
+}
@@ -0,0 +1,84 @@
+package dump_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames/dump"
+)
+
+func TestTables_ResolvesPlaceCodesToNames(t *testing.T) {
+	t.Parallel()
+
+	tables, err := dump.LoadTables("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var castlebar dump.Place
+	for p, err := range dump.Places("testdata/IE.txt") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Name == "Castlebar" {
+			castlebar = p
+		}
+	}
+
+	got := tables.Resolve(castlebar)
+	want := dump.PlaceNames{
+		CountryName: "Ireland",
+		Admin1Name:  "Connacht",
+		Admin2Name:  "Mayo",
+		FeatureName: "seat of a second-order administrative division",
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestLoadTables_IndexesLanguagesByEveryCode(t *testing.T) {
+	t.Parallel()
+
+	tables, err := dump.LoadTables("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []string{"ga", "gle"} {
+		if got := tables.Languages[code].Name; got != "Irish" {
+			t.Errorf("%s: want Irish, got %q", code, got)
+		}
+	}
+	if got := tables.TimeZones["Europe/Dublin"].DSTOffset; got != 1 {
+		t.Errorf("want Europe/Dublin DST offset 1, got %v", got)
+	}
+}
+
+func TestTables_AncestorsFollowAdministrativeLinks(t *testing.T) {
+	t.Parallel()
+
+	tables, err := dump.LoadTables("testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Castlebar is in Mayo, Connacht, Ireland.
+	if want, got := []int64{2963597, 7521314, 2962666}, tables.Ancestors(2965654); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	// Westport is linked to Mayo by a non-administrative relation.
+	if got := tables.Ancestors(2963403); len(got) != 0 {
+		t.Errorf("want no administrative ancestors of Westport, got %v", got)
+	}
+}
+
+func TestLoadTables_SkipsMissingFiles(t *testing.T) {
+	t.Parallel()
+
+	tables, err := dump.LoadTables(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tables.Resolve(dump.Place{CountryCode: "IE"}); got != (dump.PlaceNames{}) {
+		t.Errorf("want no names, got %+v", got)
+	}
+}
@@ -0,0 +1,215 @@
+package dump
+
+import (
+	"io"
+	"iter"
+	"strconv"
+	"strings"
+)
+
+// AdminCode is a record of admin1CodesASCII.txt or admin2Codes.txt.
+type AdminCode struct {
+	// Code is the concatenated code, e.g. "IE.C" or "IE.C.20".
+	Code        string
+	CountryCode string
+	Admin1Code  string
+	// Admin2Code is empty for first-order divisions.
+	Admin2Code string
+	Name       string
+	ASCIIName  string
+	GeoNameID  int64
+}
+
+// AdminCodes returns an iterator over the records of
+// admin1CodesASCII.txt or admin2Codes.txt read from r.
+func AdminCodes(r io.Reader) iter.Seq2[AdminCode, error] {
+	return records(r, format{columns: 4}, func(tr *tsvReader, f []string) (AdminCode, error) {
+		parts := strings.Split(f[0], ".")
+		if len(parts) < 2 || len(parts) > 3 {
+			return AdminCode{}, tr.errorf("invalid admin code %q", f[0])
+		}
+		a := AdminCode{
+			Code:        f[0],
+			CountryCode: parts[0],
+			Admin1Code:  parts[1],
+			Name:        f[1],
+			ASCIIName:   f[2],
+		}
+		if len(parts) == 3 {
+			a.Admin2Code = parts[2]
+		}
+		var err error
+		if a.GeoNameID, err = strconv.ParseInt(f[3], 10, 64); err != nil {
+			return AdminCode{}, tr.errorf("geonameid: %w", err)
+		}
+		return a, nil
+	})
+}
+
+// FeatureCode is a record of featureCodes_en.txt.
+type FeatureCode struct {
+	// Class is the feature class, e.g. "P".
+	Class string
+	// Code is the feature code, e.g. "PPLC".
+	Code        string
+	Name        string
+	Description string
+}
+
+// Key returns the class and code joined as in the file, e.g. "P.PPLC".
+func (fc FeatureCode) Key() string {
+	return fc.Class + "." + fc.Code
+}
+
+// FeatureCodes returns an iterator over the records of a
+// featureCodes_xx.txt file read from r.
+func FeatureCodes(r io.Reader) iter.Seq2[FeatureCode, error] {
+	return records(r, format{columns: 3}, func(tr *tsvReader, f []string) (FeatureCode, error) {
+		class, code, ok := strings.Cut(f[0], ".")
+		if !ok {
+			// The file lists "null" for features without a code.
+			class, code = "", f[0]
+		}
+		return FeatureCode{Class: class, Code: code, Name: f[1], Description: f[2]}, nil
+	})
+}
+
+// TimeZone is a record of timeZones.txt. Offsets are in hours.
+type TimeZone struct {
+	CountryCode string
+	ID          string
+	// GMTOffset is the offset on 1 January of the year the file was made.
+	GMTOffset float64
+	// DSTOffset is the offset on 1 July of the year the file was made.
+	DSTOffset float64
+	// RawOffset is the offset without daylight saving time.
+	RawOffset float64
+}
+
+// TimeZones returns an iterator over the records of timeZones.txt read from r.
+func TimeZones(r io.Reader) iter.Seq2[TimeZone, error] {
+	return records(r, format{columns: 5, header: "CountryCode"}, func(tr *tsvReader, f []string) (TimeZone, error) {
+		tz := TimeZone{CountryCode: f[0], ID: f[1]}
+		var err error
+		if tz.GMTOffset, err = strconv.ParseFloat(f[2], 64); err != nil {
+			return TimeZone{}, tr.errorf("gmt offset: %w", err)
+		}
+		if tz.DSTOffset, err = strconv.ParseFloat(f[3], 64); err != nil {
+			return TimeZone{}, tr.errorf("dst offset: %w", err)
+		}
+		if tz.RawOffset, err = strconv.ParseFloat(f[4], 64); err != nil {
+			return TimeZone{}, tr.errorf("raw offset: %w", err)
+		}
+		return tz, nil
+	})
+}
+
+// HierarchyLink is a record of hierarchy.txt linking a place to its parent.
+type HierarchyLink struct {
+	ParentID int64
+	ChildID  int64
+	// Type is "ADM" for the administrative hierarchy; other
+	// values describe user-defined relations.
+	Type string
+}
+
+// Hierarchy returns an iterator over the records of hierarchy.txt read from r.
+func Hierarchy(r io.Reader) iter.Seq2[HierarchyLink, error] {
+	return records(r, format{columns: 3}, func(tr *tsvReader, f []string) (HierarchyLink, error) {
+		h := HierarchyLink{Type: f[2]}
+		var err error
+		if h.ParentID, err = strconv.ParseInt(f[0], 10, 64); err != nil {
+			return HierarchyLink{}, tr.errorf("parent id: %w", err)
+		}
+		if h.ChildID, err = strconv.ParseInt(f[1], 10, 64); err != nil {
+			return HierarchyLink{}, tr.errorf("child id: %w", err)
+		}
+		return h, nil
+	})
+}
+
+// LanguageCode is a record of iso-languagecodes.txt.
+type LanguageCode struct {
+	ISO6393 string
+	ISO6392 string
+	// ISO6391 is empty for languages without a two-letter code.
+	ISO6391 string
+	Name    string
+}
+
+// LanguageCodes returns an iterator over the records
+// of iso-languagecodes.txt read from r.
+func LanguageCodes(r io.Reader) iter.Seq2[LanguageCode, error] {
+	return records(r, format{columns: 4, header: "ISO 639-3"}, func(tr *tsvReader, f []string) (LanguageCode, error) {
+		return LanguageCode{ISO6393: f[0], ISO6392: f[1], ISO6391: f[2], Name: f[3]}, nil
+	})
+}
+
+// format describes the layout of a table file.
+type format struct {
+	columns int
+	// header is the first column of a header line to skip, if any.
+	header string
+	// comment is the prefix of comment lines to skip, if any.
+	comment string
+}
+
+// records returns an iterator parsing each line of r with parse. Parse
+// errors are yielded and iteration continues; other errors end it.
+func records[T any](r io.Reader, ft format, parse func(*tsvReader, []string) (T, error)) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		tr := newTSVReader(r)
+		tr.comment = ft.comment
+		var zero T
+		for {
+			f, err := tr.next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			if ft.header != "" && f[0] == ft.header {
+				continue
+			}
+			if err := tr.wantFields(f, ft.columns); err != nil {
+				if !yield(zero, err) {
+					return
+				}
+				continue
+			}
+			if !yield(parse(tr, f)) {
+				return
+			}
+		}
+	}
+}
+
+// Index collects the records yielded by seq into a map keyed by key.
+// Later records replace earlier ones with the same key. It stops at
+// the first error.
+func Index[K comparable, T any](seq iter.Seq2[T, error], key func(T) K) (map[K]T, error) {
+	m := make(map[K]T)
+	for v, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		m[key(v)] = v
+	}
+	return m, nil
+}
+
+// Group collects the records yielded by seq into a map of slices keyed
+// by key, keeping the order of the input. It stops at the first error.
+func Group[K comparable, T any](seq iter.Seq2[T, error], key func(T) K) (map[K][]T, error) {
+	m := make(map[K][]T)
+	for v, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		k := key(v)
+		m[k] = append(m[k], v)
+	}
+	return m, nil
+}
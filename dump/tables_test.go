@@ -0,0 +1,140 @@
+package dump_test
+
+import (
+	"io"
+	"iter"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames/dump"
+)
+
+// collect is a test helper that reads every record of the test file with parse.
+func collect[T any](t *testing.T, testFile string, parse func(io.Reader) iter.Seq2[T, error]) []T {
+	t.Helper()
+	f, err := os.Open(testFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []T
+	for v, err := range parse(f) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, v)
+	}
+	return got
+}
+
+func TestAdminCodes_SplitsConcatenatedCodes(t *testing.T) {
+	t.Parallel()
+
+	got := collect(t, "testdata/admin2Codes.txt", dump.AdminCodes)
+	want := dump.AdminCode{
+		Code:        "IE.C.20",
+		CountryCode: "IE",
+		Admin1Code:  "C",
+		Admin2Code:  "20",
+		Name:        "Mayo",
+		ASCIIName:   "Mayo",
+		GeoNameID:   2962666,
+	}
+	if !cmp.Equal(want, got[0]) {
+		t.Error(cmp.Diff(want, got[0]))
+	}
+
+	admin1 := collect(t, "testdata/admin1CodesASCII.txt", dump.AdminCodes)
+	if len(admin1) != 5 || admin1[0].Admin2Code != "" || admin1[0].Name != "Connacht" {
+		t.Errorf("unexpected admin1 codes: %+v", admin1)
+	}
+}
+
+func TestFeatureCodes_ParsesClassAndCode(t *testing.T) {
+	t.Parallel()
+
+	got := collect(t, "testdata/featureCodes_en.txt", dump.FeatureCodes)
+	if len(got) != 8 {
+		t.Fatalf("want 8 feature codes, got %d", len(got))
+	}
+	want := dump.FeatureCode{Class: "P", Code: "PPLC", Name: "capital of a political entity"}
+	if !cmp.Equal(want, got[5]) {
+		t.Error(cmp.Diff(want, got[5]))
+	}
+	if got[7].Class != "" || got[7].Code != "null" {
+		t.Errorf("want null feature code without class, got %+v", got[7])
+	}
+}
+
+func TestTimeZones_SkipsHeaderAndParsesOffsets(t *testing.T) {
+	t.Parallel()
+
+	got := collect(t, "testdata/timeZones.txt", dump.TimeZones)
+	want := []dump.TimeZone{
+		{CountryCode: "GB", ID: "Europe/London", GMTOffset: 0, DSTOffset: 1, RawOffset: 0},
+		{CountryCode: "IE", ID: "Europe/Dublin", GMTOffset: 0, DSTOffset: 1, RawOffset: 0},
+		{CountryCode: "IN", ID: "Asia/Kolkata", GMTOffset: 5.5, DSTOffset: 5.5, RawOffset: 5.5},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestHierarchy_ParsesLinksWithAndWithoutType(t *testing.T) {
+	t.Parallel()
+
+	got := collect(t, "testdata/hierarchy.txt", dump.Hierarchy)
+	want := []dump.HierarchyLink{
+		{ParentID: 2963597, ChildID: 7521314, Type: "ADM"},
+		{ParentID: 7521314, ChildID: 2962666, Type: "ADM"},
+		{ParentID: 2962666, ChildID: 2965654, Type: "ADM"},
+		{ParentID: 2962666, ChildID: 2963403},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestLanguageCodes_SkipsHeader(t *testing.T) {
+	t.Parallel()
+
+	got := collect(t, "testdata/iso-languagecodes.txt", dump.LanguageCodes)
+	want := []dump.LanguageCode{
+		{ISO6393: "eng", ISO6392: "eng", ISO6391: "en", Name: "English"},
+		{ISO6393: "gle", ISO6392: "gle", ISO6391: "ga", Name: "Irish"},
+		{ISO6393: "gla", ISO6392: "gla", ISO6391: "gd", Name: "Scottish Gaelic"},
+		{ISO6393: "non", ISO6392: "non", Name: "Old Norse"},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestGroup_CollectsChildrenByParent(t *testing.T) {
+	t.Parallel()
+
+	f, err := os.Open("testdata/hierarchy.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	children, err := dump.Group(dump.Hierarchy(f), func(h dump.HierarchyLink) int64 { return h.ParentID })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := children[2962666]; len(got) != 2 || got[0].ChildID != 2965654 || got[1].ChildID != 2963403 {
+		t.Errorf("want two children of Mayo, got %+v", got)
+	}
+}
+
+func TestIndex_StopsAtMalformedLine(t *testing.T) {
+	t.Parallel()
+
+	r := strings.NewReader("IE.C\tConnacht\tConnacht\t7521314\nIE.L\tLeinster\n")
+	_, err := dump.Index(dump.AdminCodes(r), func(a dump.AdminCode) string { return a.Code })
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("want error on line 2, got %v", err)
+	}
+}
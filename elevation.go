@@ -3,27 +3,33 @@ package geonames
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
 )
 
 type srtm1Resp struct {
+	apiStatus
 	Srtm1 int     `json:"srtm1"`
 	Lng   float64 `json:"lng"`
 	Lat   float64 `json:"lat"`
 }
 
 type srtm3Resp struct {
+	apiStatus
 	Srtm3 int     `json:"srtm3"`
 	Lng   float64 `json:"lng"`
 	Lat   float64 `json:"lat"`
 }
 
 type asterResp struct {
+	apiStatus
 	Astergdem int     `json:"astergdem"`
 	Lng       float64 `json:"lng"`
 	Lat       float64 `json:"lat"`
 }
 
 type gtopoResp struct {
+	apiStatus
 	Gtopo30 int     `json:"gtopo30"`
 	Lng     float64 `json:"lng"`
 	Lat     float64 `json:"lat"`
@@ -31,27 +37,48 @@ type gtopoResp struct {
 
 // Elevation holds elevation data expressed in meters npm.
 type Elevation struct {
-	Type  string
+	Type string
+	// Lat and Lng locate the sample the service answered with,
+	// which may be snapped to its grid.
 	Lat   float64
 	Lng   float64
 	Value int
+	// Requested is the position asked for.
+	Requested Position
+}
+
+func (c *Client) buildElevationURL(service string, lat, lng float64) (string, error) {
+	params := url.Values{
+		"lat":      {strconv.FormatFloat(lat, 'f', -1, 64)},
+		"lng":      {strconv.FormatFloat(lng, 'f', -1, 64)},
+		"username": {c.UserName},
+	}
+	u, err := url.Parse(fmt.Sprintf("%s/%s", c.BaseURL, service))
+	if err != nil {
+		return "", fmt.Errorf("parsing base url for %s, %w", service, err)
+	}
+	u.RawQuery = params.Encode()
+	return u.String(), nil
 }
 
 // GetElevationSRTM1 takes two float numbers representing latitude and longitude
 // and returns elevation in meters according to SRMT1. The sample area is ca 30m x 30m.
 // Ocean areas returns "no data", and have assigned a value of -32768.
 func (c *Client) GetElevationSRTM1(ctx context.Context, lat, lng float64) (Elevation, error) {
-	path := fmt.Sprintf("/srtm1JSON?lat=%.3f&lng=%.3f&username=%s", lat, lng, c.UserName)
-	var er srtm1Resp
-	err := c.get(ctx, c.BaseURL+path, &er)
+	u, err := c.buildElevationURL("srtm1JSON", lat, lng)
 	if err != nil {
 		return Elevation{}, err
 	}
+	var er srtm1Resp
+	if err := c.get(ctx, u, &er); err != nil {
+		return Elevation{}, err
+	}
 	e := Elevation{
-		Type:  "srtm1",
-		Lat:   er.Lat,
-		Lng:   er.Lng,
-		Value: er.Srtm1,
+		Type:      "srtm1",
+		Lat:       er.Lat,
+		Lng:       er.Lng,
+		Value:     er.Srtm1,
+		Requested: Position{Lat: lat, Lng: lng},
 	}
 	return e, nil
 }
@@ -64,17 +91,20 @@ func (c *Client) GetElevationSRTM1(ctx context.Context, lat, lng float64) (Eleva
 // The dataset covers land areas between 60 degrees north and 56 degrees south.
 // SRTM3 data are data points located every 3-arc-second (approximately 90 meters) on a latitude/longitude grid.
 func (c *Client) GetElevationSRTM3(ctx context.Context, lat, lng float64) (Elevation, error) {
-	path := fmt.Sprintf("/srtm3JSON?lat=%.3f&lng=%.3f&username=%s", lat, lng, c.UserName)
-	var er srtm3Resp
-	err := c.get(ctx, c.BaseURL+path, &er)
+	u, err := c.buildElevationURL("srtm3JSON", lat, lng)
 	if err != nil {
 		return Elevation{}, err
 	}
+	var er srtm3Resp
+	if err := c.get(ctx, u, &er); err != nil {
+		return Elevation{}, err
+	}
 	e := Elevation{
-		Type:  "srtm3",
-		Lat:   er.Lat,
-		Lng:   er.Lng,
-		Value: er.Srtm3,
+		Type:      "srtm3",
+		Lat:       er.Lat,
+		Lng:       er.Lng,
+		Value:     er.Srtm3,
+		Requested: Position{Lat: lat, Lng: lng},
 	}
 	return e, nil
 }
@@ -83,17 +113,20 @@ func (c *Client) GetElevationSRTM3(ctx context.Context, lat, lng float64) (Eleva
 //
 // Sample are: ca 30m x 30m, between 83N and 65S latitude. Ocean areas have been assigned a value of -32768
 func (c *Client) GetElevationAstergdem(ctx context.Context, lat, lng float64) (Elevation, error) {
-	path := fmt.Sprintf("/astergdemJSON?lat=%.3f&lng=%.3f&username=%s", lat, lng, c.UserName)
-	var er asterResp
-	err := c.get(ctx, c.BaseURL+path, &er)
+	u, err := c.buildElevationURL("astergdemJSON", lat, lng)
 	if err != nil {
 		return Elevation{}, err
 	}
+	var er asterResp
+	if err := c.get(ctx, u, &er); err != nil {
+		return Elevation{}, err
+	}
 	e := Elevation{
-		Type:  "astergdem",
-		Lat:   er.Lat,
-		Lng:   er.Lng,
-		Value: er.Astergdem,
+		Type:      "astergdem",
+		Lat:       er.Lat,
+		Lng:       er.Lng,
+		Value:     er.Astergdem,
+		Requested: Position{Lat: lat, Lng: lng},
 	}
 	return e, nil
 }
@@ -107,17 +140,34 @@ func (c *Client) GetElevationAstergdem(ctx context.Context, lat, lng float64) (E
 //
 // Documentation: http://eros.usgs.gov/#/Find_Data/Products_and_Data_Available/gtopo30_info
 func (c *Client) GetElevationGTOPO30(ctx context.Context, lat, lng float64) (Elevation, error) {
-	path := fmt.Sprintf("/gtopo30JSON?lat=%.3f&lng=%.3f&username=%s", lat, lng, c.UserName)
-	var er gtopoResp
-	err := c.get(ctx, c.BaseURL+path, &er)
+	u, err := c.buildElevationURL("gtopo30JSON", lat, lng)
 	if err != nil {
 		return Elevation{}, err
 	}
+	var er gtopoResp
+	if err := c.get(ctx, u, &er); err != nil {
+		return Elevation{}, err
+	}
 	e := Elevation{
-		Type:  "gtopo30",
-		Lat:   er.Lat,
-		Lng:   er.Lng,
-		Value: er.Gtopo30,
+		Type:      "gtopo30",
+		Lat:       er.Lat,
+		Lng:       er.Lng,
+		Value:     er.Gtopo30,
+		Requested: Position{Lat: lat, Lng: lng},
 	}
 	return e, nil
 }
+
+// srtmNoData is the SRTM value for points without data, such as oceans
+// and areas beyond 60 degrees north or 56 degrees south.
+const srtmNoData = -32768
+
+// Elevation returns the SRTM3 elevation at pos, or the GTOPO30
+// elevation where SRTM3 has no data. It implements ElevationProvider.
+func (c *Client) Elevation(ctx context.Context, pos Position) (Elevation, error) {
+	e, err := c.GetElevationSRTM3(ctx, pos.Lat, pos.Lng)
+	if err != nil || e.Value != srtmNoData {
+		return e, err
+	}
+	return c.GetElevationGTOPO30(ctx, pos.Lat, pos.Lng)
+}
@@ -3,7 +3,6 @@ package geonames_test
 import (
 	"bytes"
 	"context"
-	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -29,8 +28,8 @@ func newElevationTestServer(data []byte, wantURI string, t *testing.T) *httptest
 func TestGetElevationSRTM1ReturnsDataOnValidInput(t *testing.T) {
 	t.Parallel()
 
-	lat, lng := 50.0, 50.0
-	wantReqURI := fmt.Sprintf("/srtm1JSON?lat=%.3f&lng=%.3f&username=DummyUser", lat, lng)
+	lat, lng := 54.16612, -6.08297
+	wantReqURI := "/srtm1JSON?lat=54.16612&lng=-6.08297&username=DummyUser"
 
 	ts := newElevationTestServer(srtm1, wantReqURI, t)
 	defer ts.Close()
@@ -39,10 +38,11 @@ func TestGetElevationSRTM1ReturnsDataOnValidInput(t *testing.T) {
 	client.BaseURL = ts.URL
 
 	want := geonames.Elevation{
-		Type:  "srtm1",
-		Lat:   54.166,
-		Lng:   -6.083,
-		Value: 375,
+		Type:      "srtm1",
+		Lat:       54.166,
+		Lng:       -6.083,
+		Value:     375,
+		Requested: geonames.Position{Lat: lat, Lng: lng},
 	}
 
 	got, err := client.GetElevationSRTM1(context.Background(), lat, lng)
@@ -58,8 +58,8 @@ func TestGetElevationSRTM1ReturnsDataOnValidInput(t *testing.T) {
 func TestGetElevationSRTM3ReturnsDataOnValidInput(t *testing.T) {
 	t.Parallel()
 
-	lat, lng := 55.166, -6.088
-	wantReqURI := fmt.Sprintf("/srtm3JSON?lat=%.3f&lng=%.3f&username=DummyUser", lat, lng)
+	lat, lng := 55.16604, -6.08815
+	wantReqURI := "/srtm3JSON?lat=55.16604&lng=-6.08815&username=DummyUser"
 
 	ts := newElevationTestServer(srtm3, wantReqURI, t)
 	defer ts.Close()
@@ -68,10 +68,11 @@ func TestGetElevationSRTM3ReturnsDataOnValidInput(t *testing.T) {
 	client.BaseURL = ts.URL
 
 	want := geonames.Elevation{
-		Type:  "srtm3",
-		Lat:   55.166,
-		Lng:   -6.088,
-		Value: 263,
+		Type:      "srtm3",
+		Lat:       55.166,
+		Lng:       -6.088,
+		Value:     263,
+		Requested: geonames.Position{Lat: lat, Lng: lng},
 	}
 
 	got, err := client.GetElevationSRTM3(context.Background(), lat, lng)
@@ -87,8 +88,8 @@ func TestGetElevationSRTM3ReturnsDataOnValidInput(t *testing.T) {
 func TestGetElevationAstergdemReturnsDataOnValidInput(t *testing.T) {
 	t.Parallel()
 
-	lat, lng := 50.01, 10.20
-	wantReqURI := fmt.Sprintf("/astergdemJSON?lat=%.3f&lng=%.3f&username=DummyUser", lat, lng)
+	lat, lng := 50.0101, 10.2003
+	wantReqURI := "/astergdemJSON?lat=50.0101&lng=10.2003&username=DummyUser"
 
 	ts := newElevationTestServer(astergdem, wantReqURI, t)
 	defer ts.Close()
@@ -97,10 +98,11 @@ func TestGetElevationAstergdemReturnsDataOnValidInput(t *testing.T) {
 	client.BaseURL = ts.URL
 
 	want := geonames.Elevation{
-		Type:  "astergdem",
-		Lat:   50.010,
-		Lng:   10.200,
-		Value: 206,
+		Type:      "astergdem",
+		Lat:       50.010,
+		Lng:       10.200,
+		Value:     206,
+		Requested: geonames.Position{Lat: lat, Lng: lng},
 	}
 
 	got, err := client.GetElevationAstergdem(context.Background(), lat, lng)
@@ -116,8 +118,8 @@ func TestGetElevationAstergdemReturnsDataOnValidInput(t *testing.T) {
 func TestGetElevationGTOPO30ReturnsDataOnValidInput(t *testing.T) {
 	t.Parallel()
 
-	lat, lng := 47.01, 10.2
-	wantReqURI := fmt.Sprintf("/gtopo30JSON?lat=%.3f&lng=%.3f&username=DummyUser", lat, lng)
+	lat, lng := 47.0142, 10.1987
+	wantReqURI := "/gtopo30JSON?lat=47.0142&lng=10.1987&username=DummyUser"
 
 	ts := newElevationTestServer(gtopo30, wantReqURI, t)
 	defer ts.Close()
@@ -126,10 +128,11 @@ func TestGetElevationGTOPO30ReturnsDataOnValidInput(t *testing.T) {
 	client.BaseURL = ts.URL
 
 	want := geonames.Elevation{
-		Type:  "gtopo30",
-		Lat:   47.01,
-		Lng:   10.20,
-		Value: 2632,
+		Type:      "gtopo30",
+		Lat:       47.01,
+		Lng:       10.20,
+		Value:     2632,
+		Requested: geonames.Position{Lat: lat, Lng: lng},
 	}
 
 	got, err := client.GetElevationGTOPO30(context.Background(), lat, lng)
@@ -142,6 +145,28 @@ func TestGetElevationGTOPO30ReturnsDataOnValidInput(t *testing.T) {
 	}
 }
 
+func TestGetElevationSRTM1_EscapesUsername(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("username"); got != "user&name=x" {
+			t.Errorf("want username %q, got %q", "user&name=x", got)
+		}
+		if r.URL.Query().Has("name") {
+			t.Errorf("username leaked into the query: %s", r.URL.RawQuery)
+		}
+		rw.Write(srtm1)
+	}))
+	defer ts.Close()
+
+	client := geonames.NewClient("user&name=x")
+	client.BaseURL = ts.URL
+
+	if _, err := client.GetElevationSRTM1(context.Background(), 54.16612, -6.08297); err != nil {
+		t.Fatal(err)
+	}
+}
+
 var (
 	srtm1     = []byte(`{"srtm1":375,"lng":-6.083,"lat":54.166}`)
 	srtm3     = []byte(`{"srtm3":263,"lng":-6.088,"lat":55.166}`)
@@ -0,0 +1,150 @@
+package geonames
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/qba73/geonames/internal/redact"
+)
+
+// APIError is an error reported by the GeoNames Web Service
+// in the status envelope of an otherwise successful response.
+//
+// The codes are listed at https://www.geonames.org/export/webservice-exception.html.
+type APIError struct {
+	Code    int    `json:"value"`
+	Message string `json:"message"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("geonames: %s (code %d)", e.Message, e.Code)
+}
+
+// GeoNames status codes.
+const (
+	CodeAuthorizationException = 10
+	CodeRecordDoesNotExist     = 11
+	CodeOtherError             = 12
+	CodeDatabaseTimeout        = 13
+	CodeInvalidParameter       = 14
+	CodeNoResultFound          = 15
+	CodeDuplicateException     = 16
+	CodePostalCodeNotFound     = 17
+	CodeDailyLimitExceeded     = 18
+	CodeHourlyLimitExceeded    = 19
+	CodeWeeklyLimitExceeded    = 20
+	CodeInvalidInput           = 21
+	CodeServerOverloaded       = 22
+	CodeServiceNotImplemented  = 23
+	CodeRadiusTooLarge         = 24
+	CodeMaxRowsTooLarge        = 27
+	CodeStatusUnavailable      = 28
+)
+
+// Temporary reports whether the request may succeed if sent again.
+func (e *APIError) Temporary() bool {
+	return e.Code == CodeDatabaseTimeout || e.Code == CodeServerOverloaded
+}
+
+// Is reports whether the error is ErrNotFound, which
+// matches the codes for missing records and results.
+func (e *APIError) Is(target error) bool {
+	if target != ErrNotFound {
+		return false
+	}
+	switch e.Code {
+	case CodeRecordDoesNotExist, CodeNoResultFound, CodePostalCodeNotFound:
+		return true
+	}
+	return false
+}
+
+// maxErrorBody is the number of response body bytes kept in an HTTPError.
+const maxErrorBody = 512
+
+// HTTPError is returned when the Web Service
+// responds with a status other than 200 OK.
+type HTTPError struct {
+	// StatusCode is the HTTP status of the response.
+	StatusCode int
+	// Endpoint is the request path and query, with the
+	// username and token replaced by "REDACTED".
+	Endpoint string
+	// RetryAfter is the wait requested by the Retry-After
+	// header, or zero if the header was absent or invalid.
+	RetryAfter time.Duration
+	// Body holds up to the first 512 bytes of the response body.
+	Body []byte
+}
+
+func (e *HTTPError) Error() string {
+	msg := fmt.Sprintf("geonames: %s: got response code: %d %s", e.Endpoint, e.StatusCode, http.StatusText(e.StatusCode))
+	if len(e.Body) > 0 {
+		msg += ": " + string(e.Body)
+	}
+	return msg
+}
+
+// maxCompressedErrorBody is the number of gzip-encoded body bytes
+// read to recover the beginning of an error body.
+const maxCompressedErrorBody = 64 << 10
+
+// newHTTPError creates an HTTPError from the response, reading
+// the beginning of its body. A gzip-encoded body is decompressed,
+// unless it is not valid gzip, in which case the raw bytes are kept.
+func newHTTPError(res *http.Response) *HTTPError {
+	var body []byte
+	if res.Header.Get("Content-Encoding") == "gzip" {
+		raw, _ := io.ReadAll(io.LimitReader(res.Body, maxCompressedErrorBody))
+		body = raw
+		if zr, err := gzip.NewReader(bytes.NewReader(raw)); err == nil {
+			// A truncated stream still yields its beginning.
+			body, _ = io.ReadAll(io.LimitReader(zr, maxErrorBody))
+		}
+		if len(body) > maxErrorBody {
+			body = body[:maxErrorBody]
+		}
+	} else {
+		body, _ = io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	}
+	return &HTTPError{
+		StatusCode: res.StatusCode,
+		Endpoint:   redact.URL(res.Request.URL),
+		RetryAfter: parseRetryAfter(res.Header.Get("Retry-After"), time.Now()),
+		Body:       bytes.TrimSpace(body),
+	}
+}
+
+// redactURLError hides the credentials in the request URL
+// that a transport error repeats in its message.
+func redactURLError(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		if u, perr := url.Parse(ue.URL); perr == nil {
+			ue.URL = redact.FullURL(u)
+		}
+	}
+	return err
+}
+
+// parseRetryAfter parses a Retry-After header given either
+// in seconds or as an HTTP date relative to now.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
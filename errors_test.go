@@ -0,0 +1,136 @@
+package geonames_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+)
+
+func TestGetPostCode_ReturnsAPIErrorOnStatusEnvelope(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`{"status":{"message":"user account not enabled to use the free webservice.","value":10}}`))
+	}))
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	_, err := client.GetPostCode(context.Background(), "Castlebar", "IE")
+	var ae *geonames.APIError
+	if !errors.As(err, &ae) {
+		t.Fatalf("want *APIError, got %v", err)
+	}
+	if ae.Code != geonames.CodeAuthorizationException {
+		t.Errorf("want code 10, got %d", ae.Code)
+	}
+	if ae.Temporary() {
+		t.Error("want authorization error not temporary")
+	}
+}
+
+func TestGetPostCode_ReturnsHTTPErrorOnNonOKStatus(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Retry-After", "120")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		rw.Write([]byte("service temporarily unavailable\n"))
+	}))
+	defer ts.Close()
+
+	client, err := geonames.New("DummyUser", geonames.WithBaseURL(ts.URL), geonames.WithToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.GetPostCode(context.Background(), "Castlebar", "IE")
+	var he *geonames.HTTPError
+	if !errors.As(err, &he) {
+		t.Fatalf("want *HTTPError, got %v", err)
+	}
+	want := &geonames.HTTPError{
+		StatusCode: http.StatusServiceUnavailable,
+		Endpoint:   "/postalCodeSearchJSON?country=IE&placename=Castlebar&token=REDACTED&username=REDACTED",
+		RetryAfter: 120 * time.Second,
+		Body:       []byte("service temporarily unavailable"),
+	}
+	if !cmp.Equal(want, he) {
+		t.Error(cmp.Diff(want, he))
+	}
+	if strings.Contains(err.Error(), "DummyUser") || strings.Contains(err.Error(), "secret") {
+		t.Errorf("credentials leaked in error message: %v", err)
+	}
+}
+
+func TestHTTPError_KeepsOnlyBeginningOfBody(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+		rw.Write([]byte(strings.Repeat("x", 4096)))
+	}))
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	_, err := client.GetPostCode(context.Background(), "Castlebar", "IE")
+	var he *geonames.HTTPError
+	if !errors.As(err, &he) {
+		t.Fatalf("want *HTTPError, got %v", err)
+	}
+	if len(he.Body) != 512 {
+		t.Errorf("want 512 bytes of body, got %d", len(he.Body))
+	}
+}
+
+func TestHTTPError_DecompressesGzipBody(t *testing.T) {
+	t.Parallel()
+
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("service temporarily unavailable\n"))
+	zw.Close()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Encoding", "gzip")
+		rw.WriteHeader(http.StatusServiceUnavailable)
+		if r.URL.Query().Get("placename") == "Broken" {
+			rw.Write([]byte("not gzip at all"))
+			return
+		}
+		rw.Write(gz.Bytes())
+	}))
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	for place, want := range map[string]string{
+		"Castlebar": "service temporarily unavailable",
+		"Broken":    "not gzip at all",
+	} {
+		_, err := client.GetPostCode(context.Background(), place, "IE")
+		var he *geonames.HTTPError
+		if !errors.As(err, &he) {
+			t.Fatalf("want *HTTPError, got %v", err)
+		}
+		if string(he.Body) != want {
+			t.Errorf("%s: want body %q, got %q", place, want, he.Body)
+		}
+		if !strings.HasSuffix(err.Error(), ": "+want) {
+			t.Errorf("%s: want body in error message, got %v", place, err)
+		}
+	}
+}
@@ -9,12 +9,12 @@ import (
 
 func main() {
 	// We exported valid "GEONAMES_USER" env var
-	codes, err := geonames.GetPostCode("Fort William", "UK")
+	places, err := geonames.GetPlace("Castlebar", "IE", 3)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	for _, c := range codes {
-		fmt.Printf("%+v\n", c)
+	for _, p := range places {
+		fmt.Printf("%+v\n", p)
 	}
 }
@@ -0,0 +1,3 @@
+// Package gazetteer answers GeoNames queries offline from in-memory
+// indexes built over the records parsed by package dump.
+package gazetteer
@@ -0,0 +1,223 @@
+package gazetteer
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/qba73/geonames/dump"
+)
+
+// LocalGazetteer holds dump places and their alternate names in
+// memory and searches them by name.
+//
+// A LocalGazetteer is safe for concurrent use.
+type LocalGazetteer struct {
+	mu       sync.RWMutex
+	places   map[int64]dump.Place
+	altNames map[int64]dump.AlternateName
+	// altByPlace maps GeoNames IDs to alternate name IDs.
+	altByPlace map[int64][]int64
+	// index is built on the first search and
+	// dropped whenever the data changes.
+	index *nameIndex
+}
+
+// NewLocalGazetteer loads the places yielded by places and the alternate
+// names yielded by altNames, which may be nil. Alternate names of places
+// that were not loaded are skipped. It stops at the first error.
+func NewLocalGazetteer(places iter.Seq2[dump.Place, error], altNames iter.Seq2[dump.AlternateName, error]) (*LocalGazetteer, error) {
+	g := &LocalGazetteer{
+		places:     make(map[int64]dump.Place),
+		altNames:   make(map[int64]dump.AlternateName),
+		altByPlace: make(map[int64][]int64),
+	}
+	for p, err := range places {
+		if err != nil {
+			return nil, err
+		}
+		g.places[p.GeoNameID] = p
+	}
+	if altNames == nil {
+		return g, nil
+	}
+	for a, err := range altNames {
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := g.places[a.GeoNameID]; ok {
+			g.addAlternateName(a)
+		}
+	}
+	return g, nil
+}
+
+// Len returns the number of places in the gazetteer.
+func (g *LocalGazetteer) Len() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.places)
+}
+
+// Place returns the place with the given GeoNames ID.
+func (g *LocalGazetteer) Place(id int64) (dump.Place, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	p, ok := g.places[id]
+	return p, ok
+}
+
+// AlternateNames returns the alternate names of the place
+// with the given GeoNames ID, ordered by alternate name ID.
+func (g *LocalGazetteer) AlternateNames(id int64) []dump.AlternateName {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var res []dump.AlternateName
+	for _, altID := range g.altByPlace[id] {
+		res = append(res, g.altNames[altID])
+	}
+	slices.SortFunc(res, func(a, b dump.AlternateName) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return res
+}
+
+// Upsert adds the place, replacing any place with the same GeoNames ID.
+func (g *LocalGazetteer) Upsert(p dump.Place) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.places[p.GeoNameID] = p
+	g.index = nil
+}
+
+// Delete removes the place with the given GeoNames ID
+// together with its alternate names.
+func (g *LocalGazetteer) Delete(id int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, altID := range g.altByPlace[id] {
+		delete(g.altNames, altID)
+	}
+	delete(g.altByPlace, id)
+	delete(g.places, id)
+	g.index = nil
+}
+
+// UpsertAlternateName adds the alternate name, replacing any with the
+// same ID. It reports false and skips names of places not in the
+// gazetteer.
+func (g *LocalGazetteer) UpsertAlternateName(a dump.AlternateName) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.places[a.GeoNameID]; !ok {
+		return false
+	}
+	g.addAlternateName(a)
+	g.index = nil
+	return true
+}
+
+// DeleteAlternateName removes the alternate name with the given ID.
+func (g *LocalGazetteer) DeleteAlternateName(id int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	a, ok := g.altNames[id]
+	if !ok {
+		return
+	}
+	g.unlinkAlternateName(a)
+	delete(g.altNames, id)
+	g.index = nil
+}
+
+func (g *LocalGazetteer) addAlternateName(a dump.AlternateName) {
+	if old, ok := g.altNames[a.ID]; ok {
+		g.unlinkAlternateName(old)
+	}
+	g.altNames[a.ID] = a
+	g.altByPlace[a.GeoNameID] = append(g.altByPlace[a.GeoNameID], a.ID)
+}
+
+// unlinkAlternateName removes a from the alternate names of its place.
+func (g *LocalGazetteer) unlinkAlternateName(a dump.AlternateName) {
+	ids := slices.DeleteFunc(g.altByPlace[a.GeoNameID], func(id int64) bool { return id == a.ID })
+	if len(ids) == 0 {
+		delete(g.altByPlace, a.GeoNameID)
+		return
+	}
+	g.altByPlace[a.GeoNameID] = ids
+}
+
+// nameIndex maps normalized names to places.
+type nameIndex struct {
+	ids map[string][]int64
+	// keys holds the normalized names in sorted order.
+	keys []string
+	// trigrams maps trigrams to positions in keys.
+	trigrams map[string][]int
+}
+
+// codeLanguages are the pseudo languages of alternate
+// names that hold codes and links rather than names.
+var codeLanguages = map[string]bool{
+	"post": true, "iata": true, "icao": true, "faac": true,
+	"link": true, "wkdt": true, "unlc": true, "tcid": true,
+}
+
+// nameIndex returns the name index, building it if needed.
+// The returned index is never modified, only replaced.
+func (g *LocalGazetteer) nameIndex() *nameIndex {
+	g.mu.RLock()
+	idx := g.index
+	g.mu.RUnlock()
+	if idx != nil {
+		return idx
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.index == nil {
+		g.index = g.buildIndex()
+	}
+	return g.index
+}
+
+func (g *LocalGazetteer) buildIndex() *nameIndex {
+	idx := &nameIndex{
+		ids:      make(map[string][]int64),
+		trigrams: make(map[string][]int),
+	}
+	add := func(name string, id int64) {
+		key := Normalize(name)
+		if key == "" {
+			return
+		}
+		if ids := idx.ids[key]; !slices.Contains(ids, id) {
+			idx.ids[key] = append(ids, id)
+		}
+	}
+	for id, p := range g.places {
+		add(p.Name, id)
+		add(p.ASCIIName, id)
+		for _, n := range p.AlternateNames {
+			add(n, id)
+		}
+	}
+	for _, a := range g.altNames {
+		if _, ok := g.places[a.GeoNameID]; ok && !codeLanguages[a.Language] {
+			add(a.Name, a.GeoNameID)
+		}
+	}
+	idx.keys = make([]string, 0, len(idx.ids))
+	for k := range idx.ids {
+		idx.keys = append(idx.keys, k)
+	}
+	sort.Strings(idx.keys)
+	for i, k := range idx.keys {
+		for _, t := range trigrams(k) {
+			idx.trigrams[t] = append(idx.trigrams[t], i)
+		}
+	}
+	return idx
+}
@@ -0,0 +1,70 @@
+package gazetteer
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// foldLetters maps lower-case Latin letters that do not decompose
+// into a base letter and combining marks to their ASCII spelling.
+var foldLetters = map[rune]string{
+	'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'ħ': "h", 'ı': "i",
+	'ŀ': "l", 'ſ': "s", 'ƀ': "b", 'ƈ': "c", 'ƌ': "d", 'ƒ': "f",
+	'ɠ': "g", 'ƙ': "k", 'ƚ': "l", 'ƞ': "n", 'ƥ': "p", 'ƫ': "t",
+	'ƭ': "t", 'ʈ': "t", 'ƴ': "y", 'ƶ': "z", 'ǥ': "g", 'ȡ': "d",
+	'ȴ': "l", 'ȵ': "n", 'ȶ': "t", 'ȷ': "j", 'ȼ': "c", 'ȿ': "s",
+	'ɀ': "z", 'ɇ': "e", 'ɉ': "j", 'ɋ': "q", 'ɍ': "r", 'ɏ': "y",
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th", 'ŋ': "ng",
+	'ĳ': "ij", 'ǆ': "dz", 'ǉ': "lj", 'ǌ': "nj", 'ǳ': "dz",
+}
+
+// Normalize prepares a name for matching: it lower-cases it, folds
+// Latin diacritics to ASCII, turns punctuation into spaces and
+// collapses runs of spaces. "Baile Átha Cliath" becomes
+// "baile atha cliath" and "Saint-Étienne" becomes "saint etienne".
+//
+// Latin letters are decomposed (NFD) and their combining marks
+// dropped, so "Đà Nẵng" becomes "da nang"; letters that do not
+// decompose, such as ø and ß, are spelled out from a small table.
+// Other scripts keep their marks: "Йошкар-Ола" stays "йошкар ола".
+func Normalize(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	space, latin, marked := true, false, false
+	for _, r := range norm.NFD.String(s) {
+		r = unicode.ToLower(r)
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			space, latin = false, true
+		case foldLetters[r] != "":
+			b.WriteString(foldLetters[r])
+			space, latin = false, true
+		case unicode.Is(unicode.Mn, r):
+			// Drop the diacritics of Latin letters only.
+			if !latin {
+				b.WriteRune(r)
+				marked = true
+			}
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			space, latin = false, unicode.Is(unicode.Latin, r)
+		case r == '\'' || r == '’':
+			// Keep "O'Brien" and "O’Brien" together as "obrien".
+		default:
+			if !space {
+				b.WriteByte(' ')
+				space = true
+			}
+			latin = false
+		}
+	}
+	out := strings.TrimSuffix(b.String(), " ")
+	if marked {
+		// Recompose the letters of other scripts.
+		out = norm.NFC.String(out)
+	}
+	return out
+}
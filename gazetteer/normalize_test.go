@@ -0,0 +1,36 @@
+package gazetteer_test
+
+import (
+	"testing"
+
+	"github.com/qba73/geonames/gazetteer"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, want string
+	}{
+		{"Castlebar", "castlebar"},
+		{"Baile Átha Cliath", "baile atha cliath"},
+		{"Saint-Étienne", "saint etienne"},
+		{"  Kraków  ", "krakow"},
+		{"Łódź", "lodz"},
+		{"Straße", "strasse"},
+		{"Ærøskøbing", "aeroskobing"},
+		{"O'Brien’s Bridge", "obriens bridge"},
+		{"Dublin (Co. Dublin)", "dublin co dublin"},
+		{"Sáo Paulo", "sao paulo"},
+		{"Москва", "москва"},
+		{"Đà Nẵng", "da nang"},
+		{"Thừa Thiên–Huế", "thua thien hue"},
+		{"Hạ Long", "ha long"},
+		{"Йошкар-Ола", "йошкар ола"},
+	}
+	for _, tt := range tests {
+		if got := gazetteer.Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q): want %q, got %q", tt.in, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package gazetteer
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/dump"
+)
+
+// elevationRadius is how close, in meters, a place must be for its
+// DEM value to stand for the elevation at a position. Elevation
+// changes fast in hilly terrain, so the place must be at the position
+// itself, give or take the rounding of its coordinates.
+const elevationRadius = 10
+
+// defaultNearbyRows is the number of places FindNearby returns
+// when the query does not set MaxRows.
+const defaultNearbyRows = 10
+
+// Offline answers geonames.Geocoder lookups from in-memory indexes
+// built over dump files. Any index may be nil; lookups that need a
+// missing index fail with geonames.ErrNotFound, so that an Offline
+// can be paired with a Client in a geonames.Hybrid.
+type Offline struct {
+	// Names answers Search and, together with Spatial, Elevation.
+	Names *LocalGazetteer
+	// Postal answers GetPostCode.
+	Postal *PostalIndex
+	// Spatial answers FindNearby.
+	Spatial *SpatialIndex
+	// Tables, if set, fills in country, region and feature names.
+	Tables *dump.Tables
+}
+
+var _ geonames.Geocoder = (*Offline)(nil)
+
+// Search implements geonames.PlaceSearcher. NameEquals is matched
+// exactly and NameStartsWith as a prefix. Name and Q are matched
+// exactly, or fuzzily if Fuzzy is below 1. OrderBy is ignored;
+// results are ranked as by LocalGazetteer.Search.
+func (o *Offline) Search(_ context.Context, q geonames.SearchQuery) (geonames.SearchResult, error) {
+	if o.Names == nil {
+		return geonames.SearchResult{}, fmt.Errorf("no name index: %w", geonames.ErrNotFound)
+	}
+	opts := SearchOptions{
+		Country:      q.Country,
+		Admin1Code:   q.AdminCode1,
+		FeatureClass: q.FeatureClass,
+		FeatureCode:  q.FeatureCode,
+		MaxResults:   geonames.MaxSearchRows,
+	}
+	var query string
+	switch {
+	case q.NameEquals != "":
+		query = q.NameEquals
+	case q.NameStartsWith != "":
+		query, opts.Mode = q.NameStartsWith, MatchPrefix
+	default:
+		query = cmp.Or(q.Name, q.Q)
+		if q.Fuzzy > 0 && q.Fuzzy < 1 {
+			opts.Mode = MatchFuzzy
+		}
+	}
+
+	found := o.Names.Search(query, opts)
+	rows := q.MaxRows
+	if rows <= 0 {
+		rows = 100
+	}
+	start := min(q.StartRow, len(found))
+	end := min(start+rows, len(found))
+	res := geonames.SearchResult{TotalResultsCount: len(found)}
+	for _, r := range found[start:end] {
+		res.Places = append(res.Places, o.place(r.Place))
+	}
+	return res, nil
+}
+
+// GetPostCode implements geonames.PostalLookup.
+func (o *Offline) GetPostCode(ctx context.Context, place, country string) ([]geonames.PostalCode, error) {
+	if o.Postal == nil {
+		return nil, fmt.Errorf("no postal index: %w", geonames.ErrNotFound)
+	}
+	return o.Postal.GetPostCode(ctx, place, country)
+}
+
+// Elevation implements geonames.ElevationProvider. It returns the DEM
+// value and position of a place at pos, within a few meters, which
+// needs both the Names and Spatial indexes. Other positions fail with
+// geonames.ErrNotFound, so that a Hybrid asks the Web Service.
+func (o *Offline) Elevation(_ context.Context, pos geonames.Position) (geonames.Elevation, error) {
+	if o.Names == nil || o.Spatial == nil {
+		return geonames.Elevation{}, fmt.Errorf("no elevation data: %w", geonames.ErrNotFound)
+	}
+	for _, m := range o.Spatial.WithinRadius(pos, elevationRadius) {
+		if p, ok := o.Names.Place(m.GeoNameID); ok {
+			return geonames.Elevation{Type: "dem", Lat: p.Position.Lat, Lng: p.Position.Lng, Value: p.DEM, Requested: pos}, nil
+		}
+	}
+	return geonames.Elevation{}, fmt.Errorf("no place within %dm of %v: %w", elevationRadius, pos, geonames.ErrNotFound)
+}
+
+// FindNearby implements geonames.ReverseGeocoder. A zero Radius
+// does not limit the distance.
+//
+// With a Names index, places it no longer has, such as those removed
+// by an Updater, are left out, and the others are reported at their
+// current position.
+func (o *Offline) FindNearby(_ context.Context, pos geonames.Position, q geonames.NearbyQuery) ([]geonames.Place, error) {
+	if o.Spatial == nil {
+		return nil, fmt.Errorf("no spatial index: %w", geonames.ErrNotFound)
+	}
+	var filters []Filter
+	if len(q.FeatureClass) > 0 {
+		filters = append(filters, FeatureClasses(q.FeatureClass...))
+	}
+	if len(q.FeatureCode) > 0 {
+		filters = append(filters, func(e Entry) bool {
+			return slices.Contains(q.FeatureCode, e.FeatureCode)
+		})
+	}
+	if o.Names != nil {
+		filters = append(filters, func(e Entry) bool {
+			_, ok := o.Names.Place(e.GeoNameID)
+			return ok
+		})
+	}
+	rows := q.MaxRows
+	if rows <= 0 {
+		rows = defaultNearbyRows
+	}
+	var matches []Match
+	if q.Radius > 0 {
+		matches = o.Spatial.WithinRadius(pos, q.Radius*1000, filters...)
+		matches = matches[:min(rows, len(matches))]
+	} else {
+		matches = o.Spatial.Nearest(pos, rows, filters...)
+	}
+
+	places := make([]geonames.Place, 0, len(matches))
+	for _, m := range matches {
+		p, ok := dump.Place{}, false
+		if o.Names != nil {
+			p, ok = o.Names.Place(m.GeoNameID)
+		}
+		if !ok {
+			p = dump.Place{
+				GeoNameID:    m.GeoNameID,
+				Name:         m.Name,
+				Position:     m.Position,
+				CountryCode:  m.CountryCode,
+				Admin1Code:   m.Admin1Code,
+				FeatureClass: m.FeatureClass,
+				FeatureCode:  m.FeatureCode,
+				Population:   m.Population,
+			}
+		}
+		place := o.place(p)
+		place.Distance = m.Distance / 1000
+		if ok {
+			place.Distance = pos.DistanceTo(p.Position) / 1000
+		}
+		places = append(places, place)
+	}
+	slices.SortStableFunc(places, func(a, b geonames.Place) int {
+		return cmp.Compare(a.Distance, b.Distance)
+	})
+	return places, nil
+}
+
+// Place returns the place with the given GeoNames ID from the Names
+// index, converted to the type returned by the Web Service.
+func (o *Offline) Place(id int64) (geonames.Place, bool) {
+	if o.Names == nil {
+		return geonames.Place{}, false
+	}
+	p, ok := o.Names.Place(id)
+	if !ok {
+		return geonames.Place{}, false
+	}
+	return o.place(p), true
+}
+
+// featureClassNames are the names the Web Service gives feature classes.
+var featureClassNames = map[string]string{
+	"A": "country, state, region,...",
+	"H": "stream, lake, ...",
+	"L": "parks,area, ...",
+	"P": "city, village,...",
+	"R": "road, railroad ",
+	"S": "spot, building, farm",
+	"T": "mountain,hill,rock,... ",
+	"U": "undersea",
+	"V": "forest,heath,...",
+}
+
+// place converts a dump record to the type returned by the Web Service.
+func (o *Offline) place(p dump.Place) geonames.Place {
+	res := geonames.Place{
+		GeoNameID:        int(p.GeoNameID),
+		Name:             p.Name,
+		ToponymName:      p.Name,
+		Position:         p.Position,
+		CountryCode:      p.CountryCode,
+		AdminCode1:       p.Admin1Code,
+		FeatureClass:     p.FeatureClass,
+		FeatureClassName: featureClassNames[p.FeatureClass],
+		FeatureCode:      p.FeatureCode,
+		Population:       int(p.Population),
+	}
+	if o.Tables != nil {
+		names := o.Tables.Resolve(p)
+		res.CountryName = names.CountryName
+		res.AdminName1 = names.Admin1Name
+		res.FeatureCodeName = names.FeatureName
+	}
+	return res
+}
@@ -0,0 +1,240 @@
+package gazetteer_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/dump"
+	"github.com/qba73/geonames/gazetteer"
+)
+
+func newOffline(t *testing.T) *gazetteer.Offline {
+	t.Helper()
+	spatial, err := gazetteer.NewSpatialIndex(dump.Places("../dump/testdata/IE.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &gazetteer.Offline{
+		Names:   newLocalGazetteer(t),
+		Postal:  newPostalIndex(t),
+		Spatial: spatial,
+		Tables: &dump.Tables{
+			Countries: map[string]dump.CountryInfo{"IE": {Name: "Ireland"}},
+			Admin1:    map[string]dump.AdminCode{"IE.C": {Name: "Connacht"}},
+			Features:  map[string]dump.FeatureCode{"P.PPLA2": {Name: "seat of a second-order administrative division"}},
+		},
+	}
+}
+
+func TestOffline_SearchReturnsPlacesLikeTheWebService(t *testing.T) {
+	t.Parallel()
+
+	got, err := newOffline(t).Search(context.Background(), geonames.SearchQuery{
+		Name:         "Caisleán an Bharraigh",
+		FeatureClass: []string{"P"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geonames.SearchResult{
+		TotalResultsCount: 1,
+		Places: []geonames.Place{{
+			GeoNameID:        2965654,
+			Name:             "Castlebar",
+			ToponymName:      "Castlebar",
+			Position:         geonames.Position{Lat: 53.85583, Lng: -9.29778},
+			CountryCode:      "IE",
+			CountryName:      "Ireland",
+			AdminCode1:       "C",
+			AdminName1:       "Connacht",
+			FeatureClass:     "P",
+			FeatureClassName: "city, village,...",
+			FeatureCode:      "PPLA2",
+			FeatureCodeName:  "seat of a second-order administrative division",
+			Population:       12068,
+		}},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestOffline_SearchPagesThroughResults(t *testing.T) {
+	t.Parallel()
+
+	o := newOffline(t)
+	q := geonames.SearchQuery{NameStartsWith: "c", MaxRows: 2, StartRow: 1}
+	got, err := o.Search(context.Background(), q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range got.Places {
+		names = append(names, p.Name)
+	}
+	if want := []string{"Cork", "Castlebar"}; !cmp.Equal(want, names) {
+		t.Error(cmp.Diff(want, names))
+	}
+	if got.TotalResultsCount != 5 {
+		t.Errorf("want total 5, got %d", got.TotalResultsCount)
+	}
+
+	got, err = o.Search(context.Background(), geonames.SearchQuery{Q: "Castelbar", Fuzzy: 0.8})
+	if err != nil || len(got.Places) == 0 || got.Places[0].Name != "Castlebar" {
+		t.Errorf("want fuzzy match Castlebar, got %v, %v", got.Places, err)
+	}
+}
+
+func TestOffline_FindNearbyReturnsDistanceInKilometers(t *testing.T) {
+	t.Parallel()
+
+	got, err := newOffline(t).FindNearby(context.Background(), geonames.Position{Lat: 53.8534, Lng: -9.2985}, geonames.NearbyQuery{
+		Radius:       20,
+		FeatureClass: []string{"P"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name != "Castlebar" || got[1].Name != "Westport" {
+		t.Fatalf("want Castlebar and Westport, got %+v", got)
+	}
+	if got[0].Distance < 0.2 || got[0].Distance > 0.4 {
+		t.Errorf("want Castlebar about 0.28km away, got %f", got[0].Distance)
+	}
+}
+
+func TestOffline_FindNearbyFollowsUpdatesOfNames(t *testing.T) {
+	t.Parallel()
+
+	o := newOffline(t)
+	u := gazetteer.NewUpdater(o.Names, "../dump/testdata/updates", filepath.Join(t.TempDir(), "state"))
+	if err := u.Apply(day("2026-10-16")); err != nil {
+		t.Fatal(err)
+	}
+	belmullet := geonames.Position{Lat: 54.22583, Lng: -9.99056}
+	got, err := o.FindNearby(context.Background(), belmullet, geonames.NearbyQuery{MaxRows: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Name == "Belmullet" {
+		t.Errorf("want deleted Belmullet left out, got %+v", got)
+	}
+
+	castlebar, _ := o.Names.Place(2965654)
+	castlebar.Position = belmullet
+	o.Names.Upsert(castlebar)
+	got, err = o.FindNearby(context.Background(), belmullet, geonames.NearbyQuery{MaxRows: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) == 0 || got[0].Name != "Castlebar" || got[0].Position != belmullet || got[0].Distance != 0 {
+		t.Errorf("want moved Castlebar first at its new position, got %+v", got)
+	}
+}
+
+func TestOffline_ElevationUsesDEMOfPlaceAtPosition(t *testing.T) {
+	t.Parallel()
+
+	o := newOffline(t)
+	pos := geonames.Position{Lat: 53.7597, Lng: -9.6586}
+	got, err := o.Elevation(context.Background(), pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geonames.Elevation{Type: "dem", Lat: 53.75972, Lng: -9.65861, Value: 722, Requested: pos}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	_, err = o.Elevation(context.Background(), geonames.Position{Lat: 53.765, Lng: -9.6586})
+	if !errors.Is(err, geonames.ErrNotFound) {
+		t.Errorf("want ErrNotFound 600m from the summit, got %v", err)
+	}
+	_, err = o.Elevation(context.Background(), geonames.Position{Lat: 53.5, Lng: -11})
+	if !errors.Is(err, geonames.ErrNotFound) {
+		t.Errorf("want ErrNotFound at sea, got %v", err)
+	}
+}
+
+func TestOffline_PlaceReturnsPlaceByID(t *testing.T) {
+	t.Parallel()
+
+	o := newOffline(t)
+	p, ok := o.Place(2965654)
+	if !ok || p.Name != "Castlebar" || p.AdminName1 != "Connacht" {
+		t.Errorf("want Castlebar in Connacht, got %+v, %t", p, ok)
+	}
+	if _, ok := o.Place(1); ok {
+		t.Error("want no place for unknown ID")
+	}
+}
+
+func TestOffline_MissingIndexesReturnErrNotFound(t *testing.T) {
+	t.Parallel()
+
+	var o gazetteer.Offline
+	ctx := context.Background()
+	if _, err := o.Search(ctx, geonames.SearchQuery{Q: "Dublin"}); !errors.Is(err, geonames.ErrNotFound) {
+		t.Errorf("Search: want ErrNotFound, got %v", err)
+	}
+	if _, err := o.GetPostCode(ctx, "Dublin", "IE"); !errors.Is(err, geonames.ErrNotFound) {
+		t.Errorf("GetPostCode: want ErrNotFound, got %v", err)
+	}
+	if _, err := o.Elevation(ctx, geonames.Position{}); !errors.Is(err, geonames.ErrNotFound) {
+		t.Errorf("Elevation: want ErrNotFound, got %v", err)
+	}
+	if _, err := o.FindNearby(ctx, geonames.Position{}, geonames.NearbyQuery{}); !errors.Is(err, geonames.ErrNotFound) {
+		t.Errorf("FindNearby: want ErrNotFound, got %v", err)
+	}
+}
+
+func TestHybrid_AnswersOfflineBeforeAskingTheWebService(t *testing.T) {
+	t.Parallel()
+
+	h := geonames.NewHybrid(newOffline(t), geonames.NewClient("DummyUser"))
+	codes, err := h.GetPostCode(context.Background(), "Castlebar", "IE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) == 0 {
+		t.Error("want offline postal codes for Castlebar")
+	}
+}
+
+func TestHybrid_AsksTheWebServiceForElevationAwayFromPlaces(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		fmt.Fprint(rw, `{"srtm3":512,"lng":-9.6586,"lat":53.765}`)
+	}))
+	defer ts.Close()
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+	h := geonames.NewHybrid(newOffline(t), client)
+
+	summit, err := h.Elevation(context.Background(), geonames.Position{Lat: 53.75972, Lng: -9.65861})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summit.Type != "dem" || summit.Value != 722 || calls.Load() != 0 {
+		t.Errorf("want offline DEM at the summit, got %+v after %d calls", summit, calls.Load())
+	}
+
+	slope, err := h.Elevation(context.Background(), geonames.Position{Lat: 53.765, Lng: -9.6586})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slope.Type != "srtm3" || slope.Value != 512 || calls.Load() != 1 {
+		t.Errorf("want Web Service elevation on the slope, got %+v after %d calls", slope, calls.Load())
+	}
+}
@@ -0,0 +1,179 @@
+package gazetteer
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/dump"
+)
+
+// PostalIndex is an in-memory index of postal codes supporting
+// the lookups of the GeoNames postal code services.
+//
+// A PostalIndex is safe for concurrent use once built.
+type PostalIndex struct {
+	// codes is sorted by country and postal code.
+	codes []dump.PostalCode
+	// byPlace maps country and lower-cased place name to indexes into codes.
+	byPlace map[placeKey][]int
+}
+
+type placeKey struct {
+	country string
+	place   string
+}
+
+// NewPostalIndex builds a PostalIndex from the records yielded by seq.
+// It stops at the first error.
+func NewPostalIndex(seq iter.Seq2[dump.PostalCode, error]) (*PostalIndex, error) {
+	var codes []dump.PostalCode
+	for pc, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, pc)
+	}
+	slices.SortStableFunc(codes, func(a, b dump.PostalCode) int {
+		return cmp.Or(
+			cmp.Compare(a.CountryCode, b.CountryCode),
+			cmp.Compare(a.PostalCode, b.PostalCode),
+		)
+	})
+	idx := &PostalIndex{
+		codes:   codes,
+		byPlace: make(map[placeKey][]int),
+	}
+	for i, pc := range codes {
+		k := placeKey{pc.CountryCode, strings.ToLower(pc.PlaceName)}
+		idx.byPlace[k] = append(idx.byPlace[k], i)
+	}
+	return idx, nil
+}
+
+// LoadPostalIndex builds a PostalIndex from the postal code dump files
+// at paths, e.g. allCountries.zip together with GB_full.csv.zip.
+func LoadPostalIndex(paths ...string) (*PostalIndex, error) {
+	return NewPostalIndex(func(yield func(dump.PostalCode, error) bool) {
+		for _, path := range paths {
+			for pc, err := range dump.PostalCodesFile(path) {
+				if !yield(pc, err) {
+					return
+				}
+			}
+		}
+	})
+}
+
+// Len returns the number of postal codes in the index.
+func (idx *PostalIndex) Len() int {
+	return len(idx.codes)
+}
+
+// GetPostCode returns the postal codes of the place with the given name
+// in the given country, matching the name case-insensitively. It has
+// the signature of geonames.Client.GetPostCode so that either can be
+// used. An empty country matches places in every country.
+func (idx *PostalIndex) GetPostCode(_ context.Context, place, country string) ([]geonames.PostalCode, error) {
+	name := strings.ToLower(place)
+	if country != "" {
+		return idx.collect(idx.byPlace[placeKey{country, name}]), nil
+	}
+	var matches []int
+	for k, ids := range idx.byPlace {
+		if k.place == name {
+			matches = append(matches, ids...)
+		}
+	}
+	slices.Sort(matches)
+	return idx.collect(matches), nil
+}
+
+// ByCode returns the places sharing the given postal code in the country.
+func (idx *PostalIndex) ByCode(country, code string) []geonames.PostalCode {
+	lo, hi := idx.span(country, code)
+	var res []geonames.PostalCode
+	for _, pc := range idx.codes[lo:hi] {
+		if pc.PostalCode == code {
+			res = append(res, postalCode(pc))
+		}
+	}
+	return res
+}
+
+// ByPrefix returns the postal codes in the country starting
+// with prefix, ordered by postal code.
+func (idx *PostalIndex) ByPrefix(country, prefix string) []geonames.PostalCode {
+	lo, hi := idx.span(country, prefix)
+	var res []geonames.PostalCode
+	for _, pc := range idx.codes[lo:hi] {
+		res = append(res, postalCode(pc))
+	}
+	return res
+}
+
+// span returns the range of codes in the country starting with prefix.
+func (idx *PostalIndex) span(country, prefix string) (int, int) {
+	lo, _ := slices.BinarySearchFunc(idx.codes, prefix, func(pc dump.PostalCode, p string) int {
+		return cmp.Or(cmp.Compare(pc.CountryCode, country), cmp.Compare(pc.PostalCode, p))
+	})
+	hi := lo
+	for hi < len(idx.codes) && idx.codes[hi].CountryCode == country && strings.HasPrefix(idx.codes[hi].PostalCode, prefix) {
+		hi++
+	}
+	return lo, hi
+}
+
+// Nearest returns up to n postal codes closest to pos,
+// nearest first. It scans the whole index.
+func (idx *PostalIndex) Nearest(pos geonames.Position, n int) []geonames.PostalCode {
+	if n <= 0 {
+		return nil
+	}
+	type candidate struct {
+		i    int
+		dist float64
+	}
+	// best is kept sorted by distance and holds at most n candidates.
+	best := make([]candidate, 0, n+1)
+	for i, pc := range idx.codes {
+		d := pos.DistanceTo(pc.Position)
+		if len(best) == n && d >= best[n-1].dist {
+			continue
+		}
+		at, _ := slices.BinarySearchFunc(best, d, func(c candidate, d float64) int {
+			return cmp.Compare(c.dist, d)
+		})
+		best = slices.Insert(best, at, candidate{i, d})
+		if len(best) > n {
+			best = best[:n]
+		}
+	}
+	res := make([]geonames.PostalCode, len(best))
+	for i, c := range best {
+		res[i] = postalCode(idx.codes[c.i])
+	}
+	return res
+}
+
+func (idx *PostalIndex) collect(ids []int) []geonames.PostalCode {
+	var res []geonames.PostalCode
+	for _, i := range ids {
+		res = append(res, postalCode(idx.codes[i]))
+	}
+	return res
+}
+
+func postalCode(pc dump.PostalCode) geonames.PostalCode {
+	return geonames.PostalCode{
+		PlaceName:   pc.PlaceName,
+		AdminName1:  pc.AdminName1,
+		Position:    pc.Position,
+		CountryCode: pc.CountryCode,
+		PostalCode:  pc.PostalCode,
+		AdminCode1:  pc.AdminCode1,
+	}
+}
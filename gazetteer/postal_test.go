@@ -0,0 +1,107 @@
+package gazetteer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/gazetteer"
+)
+
+// postCoder is satisfied by both the online client and the offline index.
+type postCoder interface {
+	GetPostCode(ctx context.Context, place, country string) ([]geonames.PostalCode, error)
+}
+
+var (
+	_ postCoder = geonames.Client{}
+	_ postCoder = (*gazetteer.PostalIndex)(nil)
+)
+
+func newPostalIndex(t *testing.T) *gazetteer.PostalIndex {
+	t.Helper()
+	idx, err := gazetteer.LoadPostalIndex("../dump/testdata/postal-allCountries.txt", "../dump/testdata/GB_full.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func codes(pcs []geonames.PostalCode) []string {
+	var res []string
+	for _, pc := range pcs {
+		res = append(res, pc.PostalCode)
+	}
+	return res
+}
+
+func TestPostalIndex_GetPostCodeMatchesPlaceNameCaseInsensitively(t *testing.T) {
+	t.Parallel()
+
+	idx := newPostalIndex(t)
+	if idx.Len() != 12 {
+		t.Fatalf("want 12 postal codes, got %d", idx.Len())
+	}
+
+	got, err := idx.GetPostCode(context.Background(), "castlebar", "IE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []geonames.PostalCode{
+		{
+			PlaceName:   "Castlebar",
+			AdminName1:  "Connacht",
+			Position:    geonames.Position{Lat: 53.85, Lng: -9.3},
+			CountryCode: "IE",
+			PostalCode:  "F23",
+			AdminCode1:  "C",
+		},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestPostalIndex_GetPostCodeCombinesAllAndFullFormatFiles(t *testing.T) {
+	t.Parallel()
+
+	got, err := newPostalIndex(t).GetPostCode(context.Background(), "Fort William", "GB")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"PH33", "PH33 6SY", "PH33 6TQ", "PH33 7NG"}
+	if !cmp.Equal(want, codes(got)) {
+		t.Error(cmp.Diff(want, codes(got)))
+	}
+}
+
+func TestPostalIndex_ByCodeAndByPrefix(t *testing.T) {
+	t.Parallel()
+
+	idx := newPostalIndex(t)
+	if got := codes(idx.ByCode("IE", "D02")); !cmp.Equal([]string{"D02"}, got) {
+		t.Errorf("ByCode: want [D02], got %v", got)
+	}
+	if got := idx.ByCode("GB", "D02"); len(got) != 0 {
+		t.Errorf("ByCode: want no match in GB, got %v", got)
+	}
+	if got := codes(idx.ByPrefix("IE", "D")); !cmp.Equal([]string{"D01", "D02", "D6W"}, got) {
+		t.Errorf("ByPrefix: want [D01 D02 D6W], got %v", got)
+	}
+	if got := codes(idx.ByPrefix("GB", "PH33 6")); !cmp.Equal([]string{"PH33 6SY", "PH33 6TQ"}, got) {
+		t.Errorf("ByPrefix: want [PH33 6SY PH33 6TQ], got %v", got)
+	}
+}
+
+func TestPostalIndex_NearestReturnsClosestFirst(t *testing.T) {
+	t.Parallel()
+
+	// Newport, Co. Mayo lies between Castlebar and Westport.
+	newport := geonames.Position{Lat: 53.8858, Lng: -9.5456}
+	got := codes(newPostalIndex(t).Nearest(newport, 3))
+	want := []string{"F28", "F23", "F26"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
@@ -0,0 +1,188 @@
+package gazetteer
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/qba73/geonames/dump"
+)
+
+// MatchMode selects how a query is matched against place names.
+type MatchMode int
+
+const (
+	// MatchExact matches names equal to the query after normalization.
+	MatchExact MatchMode = iota
+	// MatchPrefix matches names starting with the query, as for autocomplete.
+	MatchPrefix
+	// MatchFuzzy matches names within a few edits of the query.
+	MatchFuzzy
+)
+
+// SearchOptions restricts and tunes a Search. The zero
+// value asks for up to 10 exact matches anywhere.
+type SearchOptions struct {
+	Mode MatchMode
+	// MaxEdits is the edit distance allowed by MatchFuzzy. Zero means
+	// one edit for queries of up to five letters and two for longer ones.
+	MaxEdits int
+	// Country restricts results to the given ISO country codes.
+	Country []string
+	// Admin1Code restricts results to a first-order administrative division.
+	Admin1Code string
+	// FeatureClass restricts results to the given feature classes, e.g. "P".
+	FeatureClass []string
+	// FeatureCode restricts results to the given feature codes, e.g. "PPLC".
+	FeatureCode []string
+	// MaxResults limits the number of results. Zero means 10.
+	MaxResults int
+}
+
+// SearchResult is a place found by Search.
+type SearchResult struct {
+	Place dump.Place
+	// MatchedName is the normalized name that matched the query.
+	MatchedName string
+	// Score ranks the result; higher is better.
+	Score float64
+}
+
+// Search finds places whose name, ASCII name or any alternate name
+// matches query. Names are compared after Normalize, so case,
+// diacritics and punctuation do not matter.
+//
+// Results are ranked first by how well the name matches (exact, then
+// prefix, then by edit distance) and then by feature importance and
+// population, so that "Paris" finds the capital of France before
+// Paris, Texas.
+func (g *LocalGazetteer) Search(query string, opts SearchOptions) []SearchResult {
+	q := Normalize(query)
+	if q == "" {
+		return nil
+	}
+	idx := g.nameIndex()
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	best := make(map[int64]SearchResult)
+	consider := func(key string, quality float64) {
+		for _, id := range idx.ids[key] {
+			p, ok := g.places[id]
+			if !ok || !opts.accept(p) {
+				continue
+			}
+			score := 10*quality + importance(p) + math.Log10(1+float64(p.Population))
+			if r, ok := best[id]; !ok || score > r.Score {
+				best[id] = SearchResult{Place: p, MatchedName: key, Score: score}
+			}
+		}
+	}
+
+	switch opts.Mode {
+	case MatchExact:
+		consider(q, 3)
+	case MatchPrefix:
+		for i := sort.SearchStrings(idx.keys, q); i < len(idx.keys) && strings.HasPrefix(idx.keys[i], q); i++ {
+			if idx.keys[i] == q {
+				consider(idx.keys[i], 3)
+			} else {
+				consider(idx.keys[i], 2)
+			}
+		}
+	case MatchFuzzy:
+		edits := opts.maxEdits(q)
+		for _, key := range idx.fuzzyCandidates(q, edits) {
+			if d := editDistance(q, key, edits); d <= edits {
+				consider(key, 3-float64(d)*0.75)
+			}
+		}
+	}
+
+	res := make([]SearchResult, 0, len(best))
+	for _, r := range best {
+		res = append(res, r)
+	}
+	slices.SortFunc(res, func(a, b SearchResult) int {
+		return cmp.Or(
+			cmp.Compare(b.Score, a.Score),
+			cmp.Compare(a.Place.GeoNameID, b.Place.GeoNameID),
+		)
+	})
+	limit := opts.MaxResults
+	if limit <= 0 {
+		limit = 10
+	}
+	return res[:min(limit, len(res))]
+}
+
+func (o SearchOptions) accept(p dump.Place) bool {
+	if len(o.Country) > 0 && !slices.Contains(o.Country, p.CountryCode) {
+		return false
+	}
+	if o.Admin1Code != "" && o.Admin1Code != p.Admin1Code {
+		return false
+	}
+	if len(o.FeatureClass) > 0 && !slices.Contains(o.FeatureClass, p.FeatureClass) {
+		return false
+	}
+	if len(o.FeatureCode) > 0 && !slices.Contains(o.FeatureCode, p.FeatureCode) {
+		return false
+	}
+	return true
+}
+
+func (o SearchOptions) maxEdits(q string) int {
+	if o.MaxEdits > 0 {
+		return o.MaxEdits
+	}
+	if utf8.RuneCountInString(q) <= 5 {
+		return 1
+	}
+	return 2
+}
+
+// fuzzyCandidates returns the keys sharing enough trigrams with q to be
+// within the given number of edits. Each edit changes at most three
+// trigrams, so keys sharing fewer cannot match.
+func (idx *nameIndex) fuzzyCandidates(q string, edits int) []string {
+	qt := trigrams(q)
+	need := max(len(qt)-3*edits, 1)
+	shared := make(map[int]int)
+	for _, t := range qt {
+		for _, i := range idx.trigrams[t] {
+			shared[i]++
+		}
+	}
+	var res []string
+	for i, n := range shared {
+		if n >= need {
+			res = append(res, idx.keys[i])
+		}
+	}
+	return res
+}
+
+// importance scores feature codes so that countries, capitals and
+// administrative seats outrank other features of the same name.
+func importance(p dump.Place) float64 {
+	switch p.FeatureCode {
+	case "PCLI", "PCLD", "PCLIX":
+		return 6
+	case "PPLC":
+		return 5
+	case "ADM1", "PPLA":
+		return 4
+	case "ADM2", "PPLA2":
+		return 3
+	case "ADM3", "PPLA3", "PPLA4":
+		return 2
+	}
+	if p.FeatureClass == "P" || p.FeatureClass == "A" {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,130 @@
+package gazetteer_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames/dump"
+	"github.com/qba73/geonames/gazetteer"
+)
+
+func newLocalGazetteer(t *testing.T) *gazetteer.LocalGazetteer {
+	t.Helper()
+	f, err := dump.Open("../dump/testdata/alternateNamesV2.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	g, err := gazetteer.NewLocalGazetteer(dump.Places("../dump/testdata/IE.txt"), dump.AlternateNames(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func placeNames(rs []gazetteer.SearchResult) []string {
+	var res []string
+	for _, r := range rs {
+		res = append(res, r.Place.Name)
+	}
+	return res
+}
+
+func TestSearch_ExactMatchesNamesWithAndWithoutDiacritics(t *testing.T) {
+	t.Parallel()
+
+	g := newLocalGazetteer(t)
+	for _, q := range []string{"Baile Átha Cliath", "baile atha cliath", "BAILE-ATHA-CLIATH"} {
+		got := placeNames(g.Search(q, gazetteer.SearchOptions{}))
+		if want := []string{"Dublin"}; !cmp.Equal(want, got) {
+			t.Errorf("%q: %s", q, cmp.Diff(want, got))
+		}
+	}
+}
+
+func TestSearch_MatchesAlternateNamesTable(t *testing.T) {
+	t.Parallel()
+
+	got := newLocalGazetteer(t).Search("the big smoke", gazetteer.SearchOptions{})
+	if len(got) != 1 || got[0].Place.Name != "Dublin" || got[0].MatchedName != "the big smoke" {
+		t.Errorf("want Dublin by colloquial name, got %+v", got)
+	}
+}
+
+func TestSearch_SkipsLinksInAlternateNames(t *testing.T) {
+	t.Parallel()
+
+	got := newLocalGazetteer(t).Search("https://en.wikipedia.org/wiki/Dublin", gazetteer.SearchOptions{})
+	if len(got) != 0 {
+		t.Errorf("want no match for link, got %v", placeNames(got))
+	}
+}
+
+func TestSearch_PrefixRanksByImportanceAndPopulation(t *testing.T) {
+	t.Parallel()
+
+	g := newLocalGazetteer(t)
+	got := placeNames(g.Search("c", gazetteer.SearchOptions{Mode: gazetteer.MatchPrefix}))
+	want := []string{"Mayo", "Cork", "Castlebar", "Westport", "Croagh Patrick"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestSearch_PrefixAppliesFilters(t *testing.T) {
+	t.Parallel()
+
+	g := newLocalGazetteer(t)
+	got := placeNames(g.Search("c", gazetteer.SearchOptions{
+		Mode:         gazetteer.MatchPrefix,
+		Country:      []string{"IE"},
+		Admin1Code:   "C",
+		FeatureClass: []string{"P"},
+	}))
+	if want := []string{"Castlebar", "Westport"}; !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	if got := g.Search("Dublin", gazetteer.SearchOptions{Country: []string{"GB"}}); len(got) != 0 {
+		t.Errorf("want no match in GB, got %v", placeNames(got))
+	}
+}
+
+func TestSearch_FuzzyToleratesTypos(t *testing.T) {
+	t.Parallel()
+
+	g := newLocalGazetteer(t)
+	tests := map[string]string{
+		"Dublni":        "Dublin",
+		"Castelbar":     "Castlebar",
+		"Limmerick":     "Limerick",
+		"Gaillimhh":     "Galway",
+		"Waterfrod":     "Waterford",
+		"Belmulet":      "Belmullet",
+		"Croagh Patrik": "Croagh Patrick",
+	}
+	for q, want := range tests {
+		got := g.Search(q, gazetteer.SearchOptions{Mode: gazetteer.MatchFuzzy, MaxResults: 1})
+		if len(got) != 1 || got[0].Place.Name != want {
+			t.Errorf("%q: want %s, got %v", q, want, placeNames(got))
+		}
+	}
+}
+
+func TestSearch_FuzzyPrefersExactMatch(t *testing.T) {
+	t.Parallel()
+
+	got := newLocalGazetteer(t).Search("Cork", gazetteer.SearchOptions{Mode: gazetteer.MatchFuzzy})
+	if len(got) == 0 || got[0].Place.Name != "Cork" {
+		t.Errorf("want Cork first, got %v", placeNames(got))
+	}
+}
+
+func TestLocalGazetteer_ReturnsAlternateNamesOfPlace(t *testing.T) {
+	t.Parallel()
+
+	got := newLocalGazetteer(t).AlternateNames(2965654)
+	if len(got) != 2 || got[0].Name != "Caisleán an Bharraigh" || got[1].Name != "Castlebar" {
+		t.Errorf("want two alternate names of Castlebar, got %+v", got)
+	}
+}
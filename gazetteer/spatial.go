@@ -0,0 +1,368 @@
+package gazetteer
+
+import (
+	"bufio"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"slices"
+
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/dump"
+)
+
+// Entry is the part of a dump.Place kept by a SpatialIndex.
+type Entry struct {
+	GeoNameID    int64
+	Name         string
+	Position     geonames.Position
+	CountryCode  string
+	Admin1Code   string
+	FeatureClass string
+	FeatureCode  string
+	Population   int64
+}
+
+func entry(p dump.Place) Entry {
+	return Entry{
+		GeoNameID:    p.GeoNameID,
+		Name:         p.Name,
+		Position:     p.Position,
+		CountryCode:  p.CountryCode,
+		Admin1Code:   p.Admin1Code,
+		FeatureClass: p.FeatureClass,
+		FeatureCode:  p.FeatureCode,
+		Population:   p.Population,
+	}
+}
+
+// Match is an Entry found by a spatial query.
+type Match struct {
+	Entry
+	// Distance is the great-circle distance from the queried position in meters.
+	Distance float64
+}
+
+// Filter reports whether an entry may be returned by a spatial query.
+type Filter func(Entry) bool
+
+// MinPopulation accepts entries with at least n inhabitants.
+func MinPopulation(n int64) Filter {
+	return func(e Entry) bool {
+		return e.Population >= n
+	}
+}
+
+// FeatureClasses accepts entries of the given feature classes, e.g. "P".
+func FeatureClasses(classes ...string) Filter {
+	return func(e Entry) bool {
+		return slices.Contains(classes, e.FeatureClass)
+	}
+}
+
+// SpatialIndex is a k-d tree over places for nearest-neighbour and
+// radius queries using great-circle distance.
+//
+// Positions are mapped to points on the unit sphere, where straight-line
+// (chord) distance grows with great-circle distance, so the tree can
+// split on plain Cartesian coordinates.
+//
+// A SpatialIndex is safe for concurrent use.
+type SpatialIndex struct {
+	// entries and points are stored in tree order: the root of
+	// every subtree lo..hi is at the middle index (lo+hi)/2.
+	entries []Entry
+	points  []point
+}
+
+type point [3]float64
+
+func toPoint(p geonames.Position) point {
+	lat, lng := p.Lat*math.Pi/180, p.Lng*math.Pi/180
+	return point{math.Cos(lat) * math.Cos(lng), math.Cos(lat) * math.Sin(lng), math.Sin(lat)}
+}
+
+func (a point) dist2(b point) float64 {
+	dx, dy, dz := a[0]-b[0], a[1]-b[1], a[2]-b[2]
+	return dx*dx + dy*dy + dz*dz
+}
+
+// chordToMeters converts a squared chord length on the
+// unit sphere to a great-circle distance in meters.
+func chordToMeters(d2 float64) float64 {
+	return 2 * geonames.EarthRadius * math.Asin(math.Min(1, math.Sqrt(d2)/2))
+}
+
+// metersToChord converts a great-circle distance in meters
+// to a chord length on the unit sphere.
+func metersToChord(m float64) float64 {
+	return 2 * math.Sin(math.Min(m/geonames.EarthRadius, math.Pi)/2)
+}
+
+// NewSpatialIndex builds a SpatialIndex from the places yielded by seq.
+// It stops at the first error.
+func NewSpatialIndex(seq iter.Seq2[dump.Place, error]) (*SpatialIndex, error) {
+	idx := &SpatialIndex{}
+	for p, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		idx.entries = append(idx.entries, entry(p))
+		idx.points = append(idx.points, toPoint(p.Position))
+	}
+	idx.build(0, len(idx.entries), 0)
+	return idx, nil
+}
+
+// build arranges entries lo..hi into a subtree splitting on axis.
+func (idx *SpatialIndex) build(lo, hi, axis int) {
+	if hi-lo < 2 {
+		return
+	}
+	order := make([]int, hi-lo)
+	for i := range order {
+		order[i] = lo + i
+	}
+	slices.SortFunc(order, func(a, b int) int {
+		return cmp.Compare(idx.points[a][axis], idx.points[b][axis])
+	})
+	entries := make([]Entry, len(order))
+	points := make([]point, len(order))
+	for i, j := range order {
+		entries[i], points[i] = idx.entries[j], idx.points[j]
+	}
+	copy(idx.entries[lo:hi], entries)
+	copy(idx.points[lo:hi], points)
+
+	mid := (lo + hi) / 2
+	next := (axis + 1) % 3
+	idx.build(lo, mid, next)
+	idx.build(mid+1, hi, next)
+}
+
+// Len returns the number of entries in the index.
+func (idx *SpatialIndex) Len() int {
+	return len(idx.entries)
+}
+
+// Nearest returns up to k entries accepted by all filters,
+// closest to pos first.
+func (idx *SpatialIndex) Nearest(pos geonames.Position, k int, filters ...Filter) []Match {
+	if k <= 0 {
+		return nil
+	}
+	q := toPoint(pos)
+	// best is kept sorted by squared chord distance.
+	var best []candidate
+	var search func(lo, hi, axis int)
+	search = func(lo, hi, axis int) {
+		if lo >= hi {
+			return
+		}
+		mid := (lo + hi) / 2
+		if d2 := q.dist2(idx.points[mid]); (len(best) < k || d2 < best[len(best)-1].d2) && accept(idx.entries[mid], filters) {
+			at, _ := slices.BinarySearchFunc(best, d2, func(c candidate, d2 float64) int {
+				return cmp.Compare(c.d2, d2)
+			})
+			best = slices.Insert(best, at, candidate{mid, d2})
+			if len(best) > k {
+				best = best[:k]
+			}
+		}
+		next := (axis + 1) % 3
+		diff := q[axis] - idx.points[mid][axis]
+		near, far := [2]int{lo, mid}, [2]int{mid + 1, hi}
+		if diff > 0 {
+			near, far = far, near
+		}
+		search(near[0], near[1], next)
+		if len(best) < k || diff*diff < best[len(best)-1].d2 {
+			search(far[0], far[1], next)
+		}
+	}
+	search(0, len(idx.entries), 0)
+	return idx.matches(best)
+}
+
+// WithinRadius returns the entries accepted by all filters that lie
+// within meters of pos, closest first.
+func (idx *SpatialIndex) WithinRadius(pos geonames.Position, meters float64, filters ...Filter) []Match {
+	q := toPoint(pos)
+	r := metersToChord(meters)
+	r2 := r * r
+	var found []candidate
+	var search func(lo, hi, axis int)
+	search = func(lo, hi, axis int) {
+		if lo >= hi {
+			return
+		}
+		mid := (lo + hi) / 2
+		if d2 := q.dist2(idx.points[mid]); d2 <= r2 && accept(idx.entries[mid], filters) {
+			found = append(found, candidate{mid, d2})
+		}
+		next := (axis + 1) % 3
+		diff := q[axis] - idx.points[mid][axis]
+		if diff <= r {
+			search(lo, mid, next)
+		}
+		if diff >= -r {
+			search(mid+1, hi, next)
+		}
+	}
+	search(0, len(idx.entries), 0)
+	slices.SortFunc(found, func(a, b candidate) int {
+		return cmp.Compare(a.d2, b.d2)
+	})
+	return idx.matches(found)
+}
+
+type candidate struct {
+	i  int
+	d2 float64
+}
+
+func (idx *SpatialIndex) matches(cs []candidate) []Match {
+	res := make([]Match, len(cs))
+	for i, c := range cs {
+		res[i] = Match{Entry: idx.entries[c.i], Distance: chordToMeters(c.d2)}
+	}
+	return res
+}
+
+func accept(e Entry, filters []Filter) bool {
+	for _, f := range filters {
+		if !f(e) {
+			return false
+		}
+	}
+	return true
+}
+
+// spatialMagic identifies the binary format written by WriteTo.
+const spatialMagic = "GNKD\x01"
+
+// WriteTo writes the index to w in a compact binary form
+// that ReadSpatialIndex loads without rebuilding the tree.
+// Coordinates are stored with a precision of 1e-7 degrees.
+func (idx *SpatialIndex) WriteTo(w io.Writer) (int64, error) {
+	bw := &countingWriter{w: bufio.NewWriter(w)}
+	bw.writeString(spatialMagic)
+	bw.writeUvarint(uint64(len(idx.entries)))
+	for _, e := range idx.entries {
+		bw.writeVarint(e.GeoNameID)
+		bw.writeVarint(int64(math.Round(e.Position.Lat * 1e7)))
+		bw.writeVarint(int64(math.Round(e.Position.Lng * 1e7)))
+		bw.writeVarint(e.Population)
+		for _, s := range []string{e.Name, e.CountryCode, e.Admin1Code, e.FeatureClass, e.FeatureCode} {
+			bw.writeUvarint(uint64(len(s)))
+			bw.writeString(s)
+		}
+	}
+	if bw.err == nil {
+		bw.err = bw.w.Flush()
+	}
+	return bw.n, bw.err
+}
+
+// ReadSpatialIndex reads an index written by WriteTo.
+func ReadSpatialIndex(r io.Reader) (*SpatialIndex, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(spatialMagic))
+	if _, err := io.ReadFull(br, magic); err != nil {
+		return nil, fmt.Errorf("reading spatial index header: %w", err)
+	}
+	if string(magic) != spatialMagic {
+		return nil, errors.New("not a spatial index file")
+	}
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, fmt.Errorf("reading spatial index size: %w", err)
+	}
+	idx := &SpatialIndex{
+		entries: make([]Entry, 0, min(n, 1<<20)),
+	}
+	for i := range n {
+		e, err := readEntry(br)
+		if err != nil {
+			return nil, fmt.Errorf("reading spatial index entry %d: %w", i, err)
+		}
+		idx.entries = append(idx.entries, e)
+		idx.points = append(idx.points, toPoint(e.Position))
+	}
+	return idx, nil
+}
+
+func readEntry(br *bufio.Reader) (Entry, error) {
+	var nums [4]int64
+	for i := range nums {
+		v, err := binary.ReadVarint(br)
+		if err != nil {
+			return Entry{}, err
+		}
+		nums[i] = v
+	}
+	var strs [5]string
+	for i := range strs {
+		l, err := binary.ReadUvarint(br)
+		if err != nil {
+			return Entry{}, err
+		}
+		if l > 1<<16 {
+			return Entry{}, fmt.Errorf("string length %d too large", l)
+		}
+		b := make([]byte, l)
+		if _, err := io.ReadFull(br, b); err != nil {
+			return Entry{}, err
+		}
+		strs[i] = string(b)
+	}
+	return Entry{
+		GeoNameID:    nums[0],
+		Position:     geonames.Position{Lat: float64(nums[1]) / 1e7, Lng: float64(nums[2]) / 1e7},
+		Population:   nums[3],
+		Name:         strs[0],
+		CountryCode:  strs[1],
+		Admin1Code:   strs[2],
+		FeatureClass: strs[3],
+		FeatureCode:  strs[4],
+	}, nil
+}
+
+// countingWriter writes varints and strings, remembering
+// the first error and the number of bytes written.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (cw *countingWriter) write(b []byte) {
+	if cw.err != nil {
+		return
+	}
+	n, err := cw.w.Write(b)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+func (cw *countingWriter) writeString(s string) {
+	if cw.err != nil {
+		return
+	}
+	n, err := cw.w.WriteString(s)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+func (cw *countingWriter) writeUvarint(v uint64) {
+	cw.write(cw.buf[:binary.PutUvarint(cw.buf[:], v)])
+}
+
+func (cw *countingWriter) writeVarint(v int64) {
+	cw.write(cw.buf[:binary.PutVarint(cw.buf[:], v)])
+}
@@ -0,0 +1,175 @@
+package gazetteer_test
+
+import (
+	"bytes"
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	gcmp "github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/dump"
+	"github.com/qba73/geonames/gazetteer"
+)
+
+func newSpatialIndex(t *testing.T) *gazetteer.SpatialIndex {
+	t.Helper()
+	idx, err := gazetteer.NewSpatialIndex(dump.Places("../dump/testdata/IE.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func names(ms []gazetteer.Match) []string {
+	var res []string
+	for _, m := range ms {
+		res = append(res, m.Name)
+	}
+	return res
+}
+
+// randomPlaces is a test helper that generates n places spread over the globe.
+func randomPlaces(n int) []dump.Place {
+	r := rand.New(rand.NewPCG(1, 2))
+	places := make([]dump.Place, n)
+	for i := range places {
+		places[i] = dump.Place{
+			GeoNameID:    int64(i + 1),
+			Position:     geonames.Position{Lat: r.Float64()*180 - 90, Lng: r.Float64()*360 - 180},
+			FeatureClass: []string{"P", "A", "T"}[i%3],
+			Population:   int64(r.IntN(100000)),
+		}
+	}
+	return places
+}
+
+func placeSeq(places []dump.Place) func(func(dump.Place, error) bool) {
+	return func(yield func(dump.Place, error) bool) {
+		for _, p := range places {
+			if !yield(p, nil) {
+				return
+			}
+		}
+	}
+}
+
+func TestSpatialIndex_NearestReturnsClosestPlacesFirst(t *testing.T) {
+	t.Parallel()
+
+	newport := geonames.Position{Lat: 53.8858, Lng: -9.5456}
+	got := newSpatialIndex(t).Nearest(newport, 3, gazetteer.FeatureClasses("P"))
+	want := []string{"Westport", "Castlebar", "Belmullet"}
+	if !gcmp.Equal(want, names(got)) {
+		t.Error(gcmp.Diff(want, names(got)))
+	}
+	if got[0].Distance < 9000 || got[0].Distance > 11000 {
+		t.Errorf("want Westport about 10 km away, got %.0f m", got[0].Distance)
+	}
+}
+
+func TestSpatialIndex_NearestAppliesPopulationFilter(t *testing.T) {
+	t.Parallel()
+
+	newport := geonames.Position{Lat: 53.8858, Lng: -9.5456}
+	got := newSpatialIndex(t).Nearest(newport, 1, gazetteer.MinPopulation(50000), gazetteer.FeatureClasses("P"))
+	if want := []string{"Galway"}; !gcmp.Equal(want, names(got)) {
+		t.Error(gcmp.Diff(want, names(got)))
+	}
+}
+
+func TestSpatialIndex_WithinRadiusReturnsPlacesInsideCircle(t *testing.T) {
+	t.Parallel()
+
+	castlebar := geonames.Position{Lat: 53.85583, Lng: -9.29778}
+	got := newSpatialIndex(t).WithinRadius(castlebar, 30000)
+	want := []string{"Castlebar", "Mayo", "Westport", "Croagh Patrick"}
+	if !gcmp.Equal(want, names(got)) {
+		t.Error(gcmp.Diff(want, names(got)))
+	}
+}
+
+func TestSpatialIndex_MatchesBruteForceSearch(t *testing.T) {
+	t.Parallel()
+
+	places := randomPlaces(5000)
+	idx, err := gazetteer.NewSpatialIndex(placeSeq(places))
+	if err != nil {
+		t.Fatal(err)
+	}
+	probe := geonames.Position{Lat: 48.85, Lng: 2.35}
+
+	all := idx.WithinRadius(probe, 1e9)
+	if len(all) != len(places) {
+		t.Fatalf("want all %d places within the whole globe, got %d", len(places), len(all))
+	}
+	if !slices.IsSortedFunc(all, func(a, b gazetteer.Match) int { return cmp.Compare(a.Distance, b.Distance) }) {
+		t.Fatal("want results sorted by distance")
+	}
+
+	// The radius query covering everything is a brute force ranking.
+	got := idx.Nearest(probe, 10)
+	if !gcmp.Equal(all[:10], got) {
+		t.Error(gcmp.Diff(all[:10], got))
+	}
+
+	var want []gazetteer.Match
+	for _, m := range all {
+		if m.Distance <= 1_000_000 && m.FeatureClass == "P" {
+			want = append(want, m)
+		}
+	}
+	got = idx.WithinRadius(probe, 1_000_000, gazetteer.FeatureClasses("P"))
+	if !gcmp.Equal(want, got) {
+		t.Error(gcmp.Diff(want, got))
+	}
+}
+
+func TestSpatialIndex_RoundTripsThroughBinaryForm(t *testing.T) {
+	t.Parallel()
+
+	idx := newSpatialIndex(t)
+	var buf bytes.Buffer
+	n, err := idx.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("want %d bytes reported, got %d", buf.Len(), n)
+	}
+
+	loaded, err := gazetteer.ReadSpatialIndex(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != idx.Len() {
+		t.Fatalf("want %d entries, got %d", idx.Len(), loaded.Len())
+	}
+	pos := geonames.Position{Lat: 53.3, Lng: -6.3}
+	want, got := idx.Nearest(pos, 5), loaded.Nearest(pos, 5)
+	if !gcmp.Equal(want, got) {
+		t.Error(gcmp.Diff(want, got))
+	}
+}
+
+func TestReadSpatialIndex_RejectsOtherFiles(t *testing.T) {
+	t.Parallel()
+
+	if _, err := gazetteer.ReadSpatialIndex(bytes.NewReader([]byte("not an index"))); err == nil {
+		t.Error("want error")
+	}
+}
+
+func BenchmarkSpatialIndex_Nearest(b *testing.B) {
+	idx, err := gazetteer.NewSpatialIndex(placeSeq(randomPlaces(200000)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	r := rand.New(rand.NewPCG(3, 4))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for range b.N {
+		idx.Nearest(geonames.Position{Lat: r.Float64()*180 - 90, Lng: r.Float64()*360 - 180}, 1)
+	}
+}
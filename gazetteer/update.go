@@ -0,0 +1,230 @@
+package gazetteer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/qba73/geonames/dump"
+)
+
+// Prefixes of the daily delta files published by GeoNames. Each is
+// followed by the date of the changes and ".txt", e.g.
+// modifications-2024-05-01.txt.
+const (
+	ModificationsPrefix               = "modifications-"
+	DeletesPrefix                     = "deletes-"
+	AlternateNamesModificationsPrefix = "alternateNamesModifications-"
+	AlternateNamesDeletesPrefix       = "alternateNamesDeletes-"
+)
+
+// deltaPrefixes lists the delta files in the order they are applied.
+var deltaPrefixes = []string{
+	ModificationsPrefix,
+	DeletesPrefix,
+	AlternateNamesModificationsPrefix,
+	AlternateNamesDeletesPrefix,
+}
+
+const dateLayout = "2006-01-02"
+
+// ErrMissingDelta is returned by CatchUp when the delta files of a
+// day between the last applied day and the requested one are missing.
+var ErrMissingDelta = errors.New("missing delta files")
+
+// Updater keeps a LocalGazetteer current by applying the daily delta
+// files GeoNames publishes next to the full dumps, read from a local
+// directory.
+//
+// Applying a day is idempotent: modifications replace records and
+// deletes of missing records do nothing, so a day that failed half way
+// can simply be applied again.
+//
+// Only the gazetteer is updated. A SpatialIndex built from the same
+// dump keeps the old entries; Offline.FindNearby drops those the
+// gazetteer no longer has.
+type Updater struct {
+	Gazetteer *LocalGazetteer
+	// Dir holds the delta files.
+	Dir string
+	// StateFile records the date of the last applied day.
+	StateFile string
+	// Accept reports whether a modified place missing from the
+	// gazetteer is added. If nil, only places already present are
+	// updated, so that a mirror of a subset dump such as cities500
+	// does not grow into a worldwide one.
+	Accept func(dump.Place) bool
+}
+
+// NewUpdater returns an Updater applying the delta files in dir to g
+// and recording its progress in stateFile.
+func NewUpdater(g *LocalGazetteer, dir, stateFile string) *Updater {
+	return &Updater{Gazetteer: g, Dir: dir, StateFile: stateFile}
+}
+
+// LastApplied returns the last day applied, or the zero time
+// if the state file does not exist yet.
+func (u *Updater) LastApplied() (time.Time, error) {
+	b, err := os.ReadFile(u.StateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	day, err := time.Parse(dateLayout, strings.TrimSpace(string(b)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("reading state file %s: %w", u.StateFile, err)
+	}
+	return day, nil
+}
+
+// Days returns the days for which Dir holds any delta file, in order.
+func (u *Updater) Days() ([]time.Time, error) {
+	entries, err := os.ReadDir(u.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var days []time.Time
+	for _, e := range entries {
+		for _, prefix := range deltaPrefixes {
+			s, ok := strings.CutPrefix(e.Name(), prefix)
+			if !ok {
+				continue
+			}
+			s, ok = strings.CutSuffix(s, ".txt")
+			if !ok {
+				continue
+			}
+			if day, err := time.Parse(dateLayout, s); err == nil && !slices.ContainsFunc(days, day.Equal) {
+				days = append(days, day)
+			}
+		}
+	}
+	slices.SortFunc(days, time.Time.Compare)
+	return days, nil
+}
+
+// Apply applies the delta files of the given day: place modifications,
+// then deletes, then alternate name modifications and deletes. Missing
+// files count as empty. The day is recorded in the state file unless
+// a later day already is.
+func (u *Updater) Apply(day time.Time) error {
+	date := day.Format(dateLayout)
+	g := u.Gazetteer
+	steps := []func(io.Reader) error{
+		func(r io.Reader) error {
+			return each(dump.NewPlaceReader(r).All(), u.upsert)
+		},
+		func(r io.Reader) error {
+			return each(dump.Deletions(r), func(d dump.Deletion) { g.Delete(d.GeoNameID) })
+		},
+		func(r io.Reader) error {
+			return each(dump.AlternateNames(r), func(a dump.AlternateName) { g.UpsertAlternateName(a) })
+		},
+		func(r io.Reader) error {
+			return each(dump.AlternateNameDeletions(r), func(d dump.AlternateNameDeletion) { g.DeleteAlternateName(d.ID) })
+		},
+	}
+	for i, prefix := range deltaPrefixes {
+		name := filepath.Join(u.Dir, prefix+date+".txt")
+		f, err := os.Open(name)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		err = steps[i](f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("applying %s: %w", name, err)
+		}
+	}
+
+	last, err := u.LastApplied()
+	if err != nil {
+		return err
+	}
+	if day.After(last) {
+		return u.record(day)
+	}
+	return nil
+}
+
+// CatchUp applies, in order, every day in Dir after the last applied
+// one up to and including until, and returns the days applied. If the
+// state file records a day, the following days must all be present;
+// otherwise CatchUp applies nothing and returns ErrMissingDelta.
+func (u *Updater) CatchUp(until time.Time) ([]time.Time, error) {
+	last, err := u.LastApplied()
+	if err != nil {
+		return nil, err
+	}
+	days, err := u.Days()
+	if err != nil {
+		return nil, err
+	}
+	days = slices.DeleteFunc(days, func(d time.Time) bool {
+		return !d.After(last) || d.After(until)
+	})
+	if !last.IsZero() {
+		want := last
+		for _, d := range days {
+			want = want.AddDate(0, 0, 1)
+			if !d.Equal(want) {
+				return nil, fmt.Errorf("%w for %s", ErrMissingDelta, want.Format(dateLayout))
+			}
+		}
+	}
+
+	var applied []time.Time
+	for _, d := range days {
+		if err := u.Apply(d); err != nil {
+			return applied, err
+		}
+		applied = append(applied, d)
+	}
+	return applied, nil
+}
+
+// upsert replaces p if the gazetteer has it, or adds it if Accept does.
+func (u *Updater) upsert(p dump.Place) {
+	if _, ok := u.Gazetteer.Place(p.GeoNameID); ok || (u.Accept != nil && u.Accept(p)) {
+		u.Gazetteer.Upsert(p)
+	}
+}
+
+// record atomically writes day to the state file.
+func (u *Updater) record(day time.Time) error {
+	f, err := os.CreateTemp(filepath.Dir(u.StateFile), filepath.Base(u.StateFile)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := fmt.Fprintln(f, day.Format(dateLayout)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), u.StateFile)
+}
+
+// each calls fn for every record of seq, stopping at the first error.
+func each[T any](seq iter.Seq2[T, error], fn func(T)) error {
+	for v, err := range seq {
+		if err != nil {
+			return err
+		}
+		fn(v)
+	}
+	return nil
+}
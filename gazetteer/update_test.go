@@ -0,0 +1,181 @@
+package gazetteer_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames/dump"
+	"github.com/qba73/geonames/gazetteer"
+)
+
+func day(s string) time.Time {
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// irish accepts the places of Ireland.
+func irish(p dump.Place) bool { return p.CountryCode == "IE" }
+
+func TestUpdater_ApplyUpdatesOnlyKnownPlacesWithoutAccept(t *testing.T) {
+	t.Parallel()
+
+	g := newLocalGazetteer(t)
+	u := gazetteer.NewUpdater(g, "../dump/testdata/updates", filepath.Join(t.TempDir(), "state"))
+	if err := u.Apply(day("2026-10-16")); err != nil {
+		t.Fatal(err)
+	}
+	if p, _ := g.Place(2965654); p.Population != 12500 {
+		t.Errorf("want Castlebar population updated to 12500, got %d", p.Population)
+	}
+	if _, ok := g.Place(2966778); ok {
+		t.Error("want new place Ballina not added")
+	}
+	if got := g.AlternateNames(2966778); len(got) != 0 {
+		t.Errorf("want no alternate names of unknown Ballina, got %+v", got)
+	}
+}
+
+func TestUpdater_ApplyAddsNewPlacesTheFilterAccepts(t *testing.T) {
+	t.Parallel()
+
+	g := newLocalGazetteer(t)
+	u := gazetteer.NewUpdater(g, "../dump/testdata/updates", filepath.Join(t.TempDir(), "state"))
+	u.Accept = func(p dump.Place) bool { return p.Population >= 15000 }
+	if err := u.Apply(day("2026-10-16")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.Place(2966778); ok {
+		t.Error("want Ballina, with 10171 people, rejected")
+	}
+	if p, _ := g.Place(2965654); p.Population != 12500 {
+		t.Errorf("want known Castlebar updated regardless of the filter, got %d", p.Population)
+	}
+
+	u.Accept = irish
+	if err := u.Apply(day("2026-10-16")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := g.Place(2966778); !ok {
+		t.Error("want Ballina added once accepted")
+	}
+}
+
+func TestUpdater_CatchUpAppliesMissedDaysInOrder(t *testing.T) {
+	t.Parallel()
+
+	g := newLocalGazetteer(t)
+	state := filepath.Join(t.TempDir(), "state")
+	u := gazetteer.NewUpdater(g, "../dump/testdata/updates", state)
+	u.Accept = irish
+
+	applied, err := u.CatchUp(day("2026-10-19"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []time.Time{day("2026-10-16"), day("2026-10-17")}; !cmp.Equal(want, applied) {
+		t.Error(cmp.Diff(want, applied))
+	}
+
+	if p, _ := g.Place(2965654); p.Population != 12500 {
+		t.Errorf("want Castlebar population updated to 12500, got %d", p.Population)
+	}
+	if p, _ := g.Place(2963403); p.Population != 6300 {
+		t.Errorf("want Westport population updated to 6300, got %d", p.Population)
+	}
+	if _, ok := g.Place(2965768); ok {
+		t.Error("want Belmullet deleted")
+	}
+	if got := g.Search("Béal an Átha", gazetteer.SearchOptions{}); len(got) != 1 || got[0].Place.Name != "Ballina" {
+		t.Errorf("want new place Ballina found by new alternate name, got %v", placeNames(got))
+	}
+	if got := g.Search("The Big Smoke", gazetteer.SearchOptions{}); len(got) != 0 {
+		t.Errorf("want deleted alternate name not found, got %v", placeNames(got))
+	}
+
+	b, err := os.ReadFile(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "2026-10-17\n"; string(b) != want {
+		t.Errorf("want state %q, got %q", want, b)
+	}
+}
+
+func TestUpdater_CatchUpSkipsAppliedDays(t *testing.T) {
+	t.Parallel()
+
+	state := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(state, []byte("2026-10-16\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g := newLocalGazetteer(t)
+	u := gazetteer.NewUpdater(g, "../dump/testdata/updates", state)
+
+	applied, err := u.CatchUp(day("2026-10-19"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []time.Time{day("2026-10-17")}; !cmp.Equal(want, applied) {
+		t.Error(cmp.Diff(want, applied))
+	}
+	if _, ok := g.Place(2965768); !ok {
+		t.Error("want deletes of the applied day not replayed")
+	}
+
+	applied, err = u.CatchUp(day("2026-10-19"))
+	if err != nil || len(applied) != 0 {
+		t.Errorf("want nothing left to apply, got %v, %v", applied, err)
+	}
+}
+
+func TestUpdater_CatchUpRefusesToSkipMissingDays(t *testing.T) {
+	t.Parallel()
+
+	state := filepath.Join(t.TempDir(), "state")
+	if err := os.WriteFile(state, []byte("2026-10-14\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g := newLocalGazetteer(t)
+	u := gazetteer.NewUpdater(g, "../dump/testdata/updates", state)
+
+	_, err := u.CatchUp(day("2026-10-19"))
+	if !errors.Is(err, gazetteer.ErrMissingDelta) {
+		t.Fatalf("want ErrMissingDelta, got %v", err)
+	}
+	if p, _ := g.Place(2965654); p.Population != 12068 {
+		t.Error("want nothing applied")
+	}
+}
+
+func TestUpdater_ApplyIsIdempotent(t *testing.T) {
+	t.Parallel()
+
+	g := newLocalGazetteer(t)
+	u := gazetteer.NewUpdater(g, "../dump/testdata/updates", filepath.Join(t.TempDir(), "state"))
+	u.Accept = irish
+	for range 2 {
+		if err := u.Apply(day("2026-10-16")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := g.AlternateNames(2966778); len(got) != 1 {
+		t.Errorf("want one alternate name of Ballina, got %+v", got)
+	}
+	if g.Len() != 12 {
+		t.Errorf("want 12 places, got %d", g.Len())
+	}
+	last, err := u.LastApplied()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.Equal(day("2026-10-16")) {
+		t.Errorf("want last applied 2026-10-16, got %s", last)
+	}
+}
@@ -0,0 +1,100 @@
+package geonames
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when a lookup has no answer. An APIError
+// reporting a missing record, result or postal code matches it with
+// errors.Is.
+var ErrNotFound = errors.New("geonames: not found")
+
+// PlaceSearcher finds places by name.
+type PlaceSearcher interface {
+	Search(ctx context.Context, q SearchQuery) (SearchResult, error)
+}
+
+// PostalLookup finds the postal codes of a place.
+type PostalLookup interface {
+	GetPostCode(ctx context.Context, place, country string) ([]PostalCode, error)
+}
+
+// ElevationProvider returns the elevation at a position.
+type ElevationProvider interface {
+	Elevation(ctx context.Context, pos Position) (Elevation, error)
+}
+
+// ReverseGeocoder finds the places near a position.
+type ReverseGeocoder interface {
+	FindNearby(ctx context.Context, pos Position, q NearbyQuery) ([]Place, error)
+}
+
+// Geocoder is implemented by both the Web Service Client and
+// the offline backends in package gazetteer.
+type Geocoder interface {
+	PlaceSearcher
+	PostalLookup
+	ElevationProvider
+	ReverseGeocoder
+}
+
+var _ Geocoder = (*Client)(nil)
+
+// Hybrid answers from Local first and asks Remote only when Local
+// misses, that is when it returns ErrNotFound or no results. Other
+// errors from Local are returned as they are.
+//
+// A typical Hybrid pairs an offline gazetteer with a Client, so that
+// common lookups cost no API credits.
+type Hybrid struct {
+	Local  Geocoder
+	Remote Geocoder
+}
+
+var _ Geocoder = (*Hybrid)(nil)
+
+// NewHybrid returns a Hybrid asking local before remote.
+func NewHybrid(local, remote Geocoder) *Hybrid {
+	return &Hybrid{Local: local, Remote: remote}
+}
+
+// Search implements PlaceSearcher.
+func (h *Hybrid) Search(ctx context.Context, q SearchQuery) (SearchResult, error) {
+	res, err := h.Local.Search(ctx, q)
+	if miss(err) || (err == nil && len(res.Places) == 0) {
+		return h.Remote.Search(ctx, q)
+	}
+	return res, err
+}
+
+// GetPostCode implements PostalLookup.
+func (h *Hybrid) GetPostCode(ctx context.Context, place, country string) ([]PostalCode, error) {
+	codes, err := h.Local.GetPostCode(ctx, place, country)
+	if miss(err) || (err == nil && len(codes) == 0) {
+		return h.Remote.GetPostCode(ctx, place, country)
+	}
+	return codes, err
+}
+
+// Elevation implements ElevationProvider.
+func (h *Hybrid) Elevation(ctx context.Context, pos Position) (Elevation, error) {
+	e, err := h.Local.Elevation(ctx, pos)
+	if miss(err) {
+		return h.Remote.Elevation(ctx, pos)
+	}
+	return e, err
+}
+
+// FindNearby implements ReverseGeocoder.
+func (h *Hybrid) FindNearby(ctx context.Context, pos Position, q NearbyQuery) ([]Place, error) {
+	places, err := h.Local.FindNearby(ctx, pos, q)
+	if miss(err) || (err == nil && len(places) == 0) {
+		return h.Remote.FindNearby(ctx, pos, q)
+	}
+	return places, err
+}
+
+func miss(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
@@ -0,0 +1,169 @@
+package geonames_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+)
+
+// fakeGeocoder answers every lookup with fixed results and records
+// how many times it was asked.
+type fakeGeocoder struct {
+	places    []geonames.Place
+	codes     []geonames.PostalCode
+	elevation geonames.Elevation
+	err       error
+	calls     int
+}
+
+func (f *fakeGeocoder) Search(context.Context, geonames.SearchQuery) (geonames.SearchResult, error) {
+	f.calls++
+	return geonames.SearchResult{TotalResultsCount: len(f.places), Places: f.places}, f.err
+}
+
+func (f *fakeGeocoder) GetPostCode(context.Context, string, string) ([]geonames.PostalCode, error) {
+	f.calls++
+	return f.codes, f.err
+}
+
+func (f *fakeGeocoder) Elevation(context.Context, geonames.Position) (geonames.Elevation, error) {
+	f.calls++
+	return f.elevation, f.err
+}
+
+func (f *fakeGeocoder) FindNearby(context.Context, geonames.Position, geonames.NearbyQuery) ([]geonames.Place, error) {
+	f.calls++
+	return f.places, f.err
+}
+
+func TestHybrid_AnswersLocallyWhenLocalHasResults(t *testing.T) {
+	t.Parallel()
+
+	local := &fakeGeocoder{
+		places: []geonames.Place{{Name: "Castlebar"}},
+		codes:  []geonames.PostalCode{{PostalCode: "F23"}},
+	}
+	remote := &fakeGeocoder{}
+	h := geonames.NewHybrid(local, remote)
+	ctx := context.Background()
+
+	res, err := h.Search(ctx, geonames.SearchQuery{Q: "Castlebar"})
+	if err != nil || len(res.Places) != 1 {
+		t.Fatalf("want local place, got %v, %v", res, err)
+	}
+	if _, err := h.GetPostCode(ctx, "Castlebar", "IE"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.Elevation(ctx, geonames.Position{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.FindNearby(ctx, geonames.Position{}, geonames.NearbyQuery{}); err != nil {
+		t.Fatal(err)
+	}
+	if local.calls != 4 || remote.calls != 0 {
+		t.Errorf("want 4 local and 0 remote calls, got %d and %d", local.calls, remote.calls)
+	}
+}
+
+func TestHybrid_FallsBackToRemoteOnMiss(t *testing.T) {
+	t.Parallel()
+
+	remote := &fakeGeocoder{
+		places:    []geonames.Place{{Name: "Castlebar"}},
+		codes:     []geonames.PostalCode{{PostalCode: "F23"}},
+		elevation: geonames.Elevation{Type: "srtm3", Value: 41},
+	}
+	ctx := context.Background()
+
+	for name, local := range map[string]*fakeGeocoder{
+		"empty":     {},
+		"not found": {err: fmt.Errorf("no index: %w", geonames.ErrNotFound)},
+	} {
+		h := geonames.NewHybrid(local, remote)
+		res, err := h.Search(ctx, geonames.SearchQuery{Q: "Castlebar"})
+		if err != nil || len(res.Places) != 1 {
+			t.Errorf("%s: want remote place, got %v, %v", name, res, err)
+		}
+		codes, err := h.GetPostCode(ctx, "Castlebar", "IE")
+		if err != nil || len(codes) != 1 {
+			t.Errorf("%s: want remote postal code, got %v, %v", name, codes, err)
+		}
+		places, err := h.FindNearby(ctx, geonames.Position{}, geonames.NearbyQuery{})
+		if err != nil || len(places) != 1 {
+			t.Errorf("%s: want remote nearby place, got %v, %v", name, places, err)
+		}
+	}
+
+	h := geonames.NewHybrid(&fakeGeocoder{err: geonames.ErrNotFound}, remote)
+	e, err := h.Elevation(ctx, geonames.Position{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (geonames.Elevation{Type: "srtm3", Value: 41}); !cmp.Equal(want, e) {
+		t.Error(cmp.Diff(want, e))
+	}
+}
+
+func TestHybrid_ReturnsLocalErrorsOtherThanNotFound(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	remote := &fakeGeocoder{}
+	h := geonames.NewHybrid(&fakeGeocoder{err: boom}, remote)
+	if _, err := h.Search(context.Background(), geonames.SearchQuery{}); !errors.Is(err, boom) {
+		t.Errorf("want local error, got %v", err)
+	}
+	if remote.calls != 0 {
+		t.Errorf("want no remote calls, got %d", remote.calls)
+	}
+}
+
+func TestAPIError_MatchesErrNotFoundForMissingResults(t *testing.T) {
+	t.Parallel()
+
+	for code, want := range map[int]bool{
+		geonames.CodeRecordDoesNotExist: true,
+		geonames.CodeNoResultFound:      true,
+		geonames.CodePostalCodeNotFound: true,
+		geonames.CodeInvalidParameter:   false,
+		geonames.CodeDailyLimitExceeded: false,
+	} {
+		err := fmt.Errorf("lookup: %w", &geonames.APIError{Code: code})
+		if got := errors.Is(err, geonames.ErrNotFound); got != want {
+			t.Errorf("code %d: want %t, got %t", code, want, got)
+		}
+	}
+}
+
+func TestClientElevation_FallsBackToGTOPO30WithoutSRTMData(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/srtm3JSON":
+			fmt.Fprint(w, `{"srtm3":-32768,"lng":-9.3,"lat":71.2}`)
+		case "/gtopo30JSON":
+			fmt.Fprint(w, `{"gtopo30":12,"lng":-9.3,"lat":71.2}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+	got, err := client.Elevation(context.Background(), geonames.Position{Lat: 71.2, Lng: -9.3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geonames.Elevation{Type: "gtopo30", Lat: 71.2, Lng: -9.3, Value: 12, Requested: geonames.Position{Lat: 71.2, Lng: -9.3}}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
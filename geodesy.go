@@ -0,0 +1,184 @@
+package geonames
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// EarthRadius is the mean radius of the Earth in meters.
+const EarthRadius = 6371008.8
+
+// WGS-84 ellipsoid parameters used by VincentyDistanceTo.
+const (
+	wgs84A = 6378137.0
+	wgs84F = 1 / 298.257223563
+	wgs84B = wgs84A * (1 - wgs84F)
+)
+
+// ErrInvalidPosition is returned for latitudes outside
+// -90..90 or longitudes outside -180..180 degrees.
+var ErrInvalidPosition = errors.New("invalid position")
+
+// Validate reports whether p is a valid position.
+func (p Position) Validate() error {
+	if math.IsNaN(p.Lat) || p.Lat < -90 || p.Lat > 90 {
+		return fmt.Errorf("latitude %v out of range: %w", p.Lat, ErrInvalidPosition)
+	}
+	if math.IsNaN(p.Lng) || p.Lng < -180 || p.Lng > 180 {
+		return fmt.Errorf("longitude %v out of range: %w", p.Lng, ErrInvalidPosition)
+	}
+	return nil
+}
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
+
+// normalizeLng wraps a longitude in degrees into -180..180.
+func normalizeLng(lng float64) float64 {
+	lng = math.Mod(lng+180, 360)
+	if lng < 0 {
+		lng += 360
+	}
+	return lng - 180
+}
+
+// DistanceTo returns the great-circle distance from p to q in meters,
+// computed with the haversine formula on a sphere of EarthRadius. It is
+// within about 0.5% of the distance on the WGS-84 ellipsoid.
+func (p Position) DistanceTo(q Position) float64 {
+	lat1, lat2 := radians(p.Lat), radians(q.Lat)
+	dLat := lat2 - lat1
+	dLng := radians(q.Lng - p.Lng)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * EarthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// VincentyDistanceTo returns the distance from p to q in meters on the
+// WGS-84 ellipsoid, computed with Vincenty's inverse formula, which is
+// accurate to within a millimeter. For nearly antipodal points, where
+// the formula does not converge, it returns DistanceTo.
+func (p Position) VincentyDistanceTo(q Position) float64 {
+	l := radians(q.Lng - p.Lng)
+	u1 := math.Atan((1 - wgs84F) * math.Tan(radians(p.Lat)))
+	u2 := math.Atan((1 - wgs84F) * math.Tan(radians(q.Lat)))
+	sinU1, cosU1 := math.Sincos(u1)
+	sinU2, cosU2 := math.Sincos(u2)
+
+	lambda := l
+	for range 200 {
+		sinLambda, cosLambda := math.Sincos(lambda)
+		sinSigma := math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			return 0 // coincident points
+		}
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cos2Alpha := 1 - sinAlpha*sinAlpha
+		cos2SigmaM := 0.0 // equatorial line
+		if cos2Alpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cos2Alpha
+		}
+		c := wgs84F / 16 * cos2Alpha * (4 + wgs84F*(4-3*cos2Alpha))
+		prev := lambda
+		lambda = l + (1-c)*wgs84F*sinAlpha*(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+		if math.Abs(lambda-prev) > 1e-12 {
+			continue
+		}
+		u2 := cos2Alpha * (wgs84A*wgs84A - wgs84B*wgs84B) / (wgs84B * wgs84B)
+		a := 1 + u2/16384*(4096+u2*(-768+u2*(320-175*u2)))
+		b := u2 / 1024 * (256 + u2*(-128+u2*(74-47*u2)))
+		deltaSigma := b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+			b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+		return wgs84B * a * (sigma - deltaSigma)
+	}
+	return p.DistanceTo(q)
+}
+
+// BearingTo returns the initial great-circle bearing from p to q in
+// degrees clockwise from north, between 0 and 360.
+func (p Position) BearingTo(q Position) float64 {
+	lat1, lat2 := radians(p.Lat), radians(q.Lat)
+	dLng := radians(q.Lng - p.Lng)
+	y := math.Sin(dLng) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLng)
+	return math.Mod(degrees(math.Atan2(y, x))+360, 360)
+}
+
+// Destination returns the position reached by travelling the given
+// distance in meters from p along a great circle with the given
+// initial bearing in degrees.
+func (p Position) Destination(bearing, meters float64) Position {
+	lat1, lng1 := radians(p.Lat), radians(p.Lng)
+	theta := radians(bearing)
+	delta := meters / EarthRadius
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(delta) + math.Cos(lat1)*math.Sin(delta)*math.Cos(theta))
+	lng2 := lng1 + math.Atan2(math.Sin(theta)*math.Sin(delta)*math.Cos(lat1), math.Cos(delta)-math.Sin(lat1)*math.Sin(lat2))
+	return Position{Lat: degrees(lat2), Lng: normalizeLng(degrees(lng2))}
+}
+
+// Midpoint returns the point halfway between p and q along the great circle.
+func (p Position) Midpoint(q Position) Position {
+	return p.Interpolate(q, 0.5)
+}
+
+// Interpolate returns the point at fraction f of the way from p to q
+// along the great circle, so that f = 0 gives p and f = 1 gives q.
+func (p Position) Interpolate(q Position, f float64) Position {
+	delta := p.DistanceTo(q) / EarthRadius
+	if delta == 0 {
+		return p
+	}
+	lat1, lng1 := radians(p.Lat), radians(p.Lng)
+	lat2, lng2 := radians(q.Lat), radians(q.Lng)
+	a := math.Sin((1-f)*delta) / math.Sin(delta)
+	b := math.Sin(f*delta) / math.Sin(delta)
+	x := a*math.Cos(lat1)*math.Cos(lng1) + b*math.Cos(lat2)*math.Cos(lng2)
+	y := a*math.Cos(lat1)*math.Sin(lng1) + b*math.Cos(lat2)*math.Sin(lng2)
+	z := a*math.Sin(lat1) + b*math.Sin(lat2)
+	return Position{
+		Lat: degrees(math.Atan2(z, math.Hypot(x, y))),
+		Lng: degrees(math.Atan2(y, x)),
+	}
+}
+
+// BoundingBox is an area bounded by two parallels and two meridians,
+// in degrees. A box crossing the antimeridian has West greater than East.
+type BoundingBox struct {
+	North, South, East, West float64
+}
+
+// Contains reports whether p lies within the box.
+func (b BoundingBox) Contains(p Position) bool {
+	if p.Lat < b.South || p.Lat > b.North {
+		return false
+	}
+	if b.West <= b.East {
+		return p.Lng >= b.West && p.Lng <= b.East
+	}
+	return p.Lng >= b.West || p.Lng <= b.East
+}
+
+// BoundingBoxAround returns the smallest box holding every position
+// within the given distance in meters of p. A box reaching a pole
+// spans all longitudes.
+func (p Position) BoundingBoxAround(meters float64) BoundingBox {
+	delta := degrees(meters / EarthRadius)
+	b := BoundingBox{North: p.Lat + delta, South: p.Lat - delta}
+	if b.North >= 90 || b.South <= -90 {
+		b.North, b.South = math.Min(b.North, 90), math.Max(b.South, -90)
+		b.West, b.East = -180, 180
+		return b
+	}
+	// The meridians touching the circle, which is widest
+	// north or south of p's latitude.
+	dLng := degrees(math.Asin(math.Sin(meters/EarthRadius) / math.Cos(radians(p.Lat))))
+	if meters/EarthRadius >= math.Pi/2 || math.IsNaN(dLng) || dLng >= 180 {
+		b.West, b.East = -180, 180
+		return b
+	}
+	b.West, b.East = normalizeLng(p.Lng-dLng), normalizeLng(p.Lng+dLng)
+	return b
+}
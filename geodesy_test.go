@@ -0,0 +1,168 @@
+package geonames_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/qba73/geonames"
+)
+
+var (
+	dublin    = geonames.Position{Lat: 53.33306, Lng: -6.24889}
+	london    = geonames.Position{Lat: 51.50853, Lng: -0.12574}
+	castlebar = geonames.Position{Lat: 53.85583, Lng: -9.29778}
+)
+
+func near(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func nearPosition(a, b geonames.Position) bool {
+	return near(a.Lat, b.Lat, 1e-6) && near(a.Lng, b.Lng, 1e-6)
+}
+
+func TestPositionValidate_RejectsOutOfRangeCoordinates(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []geonames.Position{
+		{Lat: 90.5, Lng: 0},
+		{Lat: -91, Lng: 0},
+		{Lat: 0, Lng: 180.1},
+		{Lat: math.NaN(), Lng: 0},
+	} {
+		if err := p.Validate(); !errors.Is(err, geonames.ErrInvalidPosition) {
+			t.Errorf("%v: want ErrInvalidPosition, got %v", p, err)
+		}
+	}
+	for _, p := range []geonames.Position{dublin, {Lat: 90, Lng: -180}, {Lat: -90, Lng: 180}} {
+		if err := p.Validate(); err != nil {
+			t.Errorf("%v: want valid, got %v", p, err)
+		}
+	}
+}
+
+func TestPositionDistanceTo_ReturnsGreatCircleDistance(t *testing.T) {
+	t.Parallel()
+
+	if d := dublin.DistanceTo(london); !near(d, 462_000, 1000) {
+		t.Errorf("want Dublin to London about 462 km, got %.0f m", d)
+	}
+	if d := dublin.DistanceTo(dublin); d != 0 {
+		t.Errorf("want zero distance to itself, got %v", d)
+	}
+	quarter := geonames.Position{Lat: 0, Lng: 90}
+	if d := (geonames.Position{}).DistanceTo(quarter); !near(d, math.Pi/2*geonames.EarthRadius, 1e-6) {
+		t.Errorf("want a quarter of the equator, got %v", d)
+	}
+}
+
+func TestPositionVincentyDistanceTo_MatchesReferenceGeodesic(t *testing.T) {
+	t.Parallel()
+
+	// Flinders Peak to Buninyong, the worked example of Vincenty (1975).
+	flinders := geonames.Position{Lat: -(37 + 57/60.0 + 3.72030/3600), Lng: 144 + 25/60.0 + 29.52440/3600}
+	buninyong := geonames.Position{Lat: -(37 + 39/60.0 + 10.15610/3600), Lng: 143 + 55/60.0 + 35.38390/3600}
+	if d := flinders.VincentyDistanceTo(buninyong); !near(d, 54972.271, 0.001) {
+		t.Errorf("want 54972.271 m, got %.4f m", d)
+	}
+	if d := dublin.VincentyDistanceTo(dublin); d != 0 {
+		t.Errorf("want zero distance to itself, got %v", d)
+	}
+	// Nearly antipodal points fall back to the spherical distance.
+	a, b := geonames.Position{Lat: 0, Lng: 0}, geonames.Position{Lat: 0.5, Lng: 179.7}
+	if d := a.VincentyDistanceTo(b); math.IsNaN(d) || !near(d, a.DistanceTo(b), 0.01*a.DistanceTo(b)) {
+		t.Errorf("want about %.0f m for nearly antipodal points, got %v", a.DistanceTo(b), d)
+	}
+}
+
+func TestPositionBearingTo_ReturnsInitialBearing(t *testing.T) {
+	t.Parallel()
+
+	origin := geonames.Position{}
+	tests := []struct {
+		to   geonames.Position
+		want float64
+	}{
+		{geonames.Position{Lat: 1, Lng: 0}, 0},
+		{geonames.Position{Lat: 0, Lng: 1}, 90},
+		{geonames.Position{Lat: -1, Lng: 0}, 180},
+		{geonames.Position{Lat: 0, Lng: -1}, 270},
+	}
+	for _, tc := range tests {
+		if got := origin.BearingTo(tc.to); !near(got, tc.want, 1e-9) {
+			t.Errorf("bearing to %v: want %v, got %v", tc.to, tc.want, got)
+		}
+	}
+	if got := dublin.BearingTo(castlebar); got < 270 || got > 300 {
+		t.Errorf("want Castlebar west-north-west of Dublin, got bearing %v", got)
+	}
+}
+
+func TestPositionDestination_InvertsBearingAndDistance(t *testing.T) {
+	t.Parallel()
+
+	got := dublin.Destination(dublin.BearingTo(castlebar), dublin.DistanceTo(castlebar))
+	if !nearPosition(castlebar, got) {
+		t.Errorf("want %v, got %v", castlebar, got)
+	}
+	// Travelling east across the antimeridian wraps the longitude.
+	got = geonames.Position{Lat: 0, Lng: 179.5}.Destination(90, geonames.EarthRadius*math.Pi/180)
+	if want := (geonames.Position{Lat: 0, Lng: -179.5}); !nearPosition(want, got) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+}
+
+func TestPositionMidpointAndInterpolate_FollowGreatCircle(t *testing.T) {
+	t.Parallel()
+
+	a, b := geonames.Position{Lat: 0, Lng: 0}, geonames.Position{Lat: 0, Lng: 90}
+	if got, want := a.Midpoint(b), (geonames.Position{Lat: 0, Lng: 45}); !nearPosition(want, got) {
+		t.Errorf("want midpoint %v, got %v", want, got)
+	}
+	if got := dublin.Interpolate(castlebar, 0); !nearPosition(dublin, got) {
+		t.Errorf("want start %v, got %v", dublin, got)
+	}
+	if got := dublin.Interpolate(castlebar, 1); !nearPosition(castlebar, got) {
+		t.Errorf("want end %v, got %v", castlebar, got)
+	}
+	quarter := dublin.Interpolate(castlebar, 0.25)
+	if d, total := dublin.DistanceTo(quarter), dublin.DistanceTo(castlebar); !near(d, total/4, 1e-3) {
+		t.Errorf("want a quarter of %v m, got %v m", total, d)
+	}
+	if got := dublin.Midpoint(dublin); got != dublin {
+		t.Errorf("want %v, got %v", dublin, got)
+	}
+}
+
+func TestPositionBoundingBoxAround_HoldsCircle(t *testing.T) {
+	t.Parallel()
+
+	box := castlebar.BoundingBoxAround(10_000)
+	for bearing := 0.0; bearing < 360; bearing += 15 {
+		if p := castlebar.Destination(bearing, 9_999); !box.Contains(p) {
+			t.Errorf("box %+v misses %v at bearing %v", box, p, bearing)
+		}
+	}
+	if box.Contains(castlebar.Destination(0, 10_100)) || box.Contains(castlebar.Destination(90, 10_100)) {
+		t.Errorf("box %+v larger than the circle", box)
+	}
+}
+
+func TestPositionBoundingBoxAround_HandlesAntimeridianAndPoles(t *testing.T) {
+	t.Parallel()
+
+	box := geonames.Position{Lat: 0, Lng: 179.9}.BoundingBoxAround(50_000)
+	if box.West <= box.East {
+		t.Fatalf("want box crossing the antimeridian, got %+v", box)
+	}
+	if !box.Contains(geonames.Position{Lat: 0, Lng: -179.9}) || box.Contains(geonames.Position{Lat: 0, Lng: 0}) {
+		t.Errorf("wrong containment for box %+v", box)
+	}
+
+	box = geonames.Position{Lat: 89.9, Lng: 10}.BoundingBoxAround(50_000)
+	want := geonames.BoundingBox{North: 90, South: box.South, East: 180, West: -180}
+	if box != want {
+		t.Errorf("want box spanning all longitudes, got %+v", box)
+	}
+}
@@ -0,0 +1,77 @@
+package geonames
+
+import (
+	"fmt"
+	"strings"
+)
+
+// geohashAlphabet is the base 32 alphabet of geohashes.
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxGeohashPrecision is the longest geohash Geohash returns,
+// whose cells are a few centimeters across.
+const MaxGeohashPrecision = 12
+
+// Geohash returns the geohash of p with the given number of characters,
+// which is clamped to 1..MaxGeohashPrecision. Five characters locate p
+// within about 2.4 km, eight within about 20 m.
+func (p Position) Geohash(precision int) string {
+	precision = min(max(precision, 1), MaxGeohashPrecision)
+	lat := [2]float64{-90, 90}
+	lng := [2]float64{-180, 180}
+	var sb strings.Builder
+	even := true
+	bits, ch := 0, 0
+	for sb.Len() < precision {
+		r, v := &lat, p.Lat
+		if even {
+			r, v = &lng, p.Lng
+		}
+		mid := (r[0] + r[1]) / 2
+		ch <<= 1
+		if v >= mid {
+			ch |= 1
+			r[0] = mid
+		} else {
+			r[1] = mid
+		}
+		even = !even
+		if bits++; bits == 5 {
+			sb.WriteByte(geohashAlphabet[ch])
+			bits, ch = 0, 0
+		}
+	}
+	return sb.String()
+}
+
+// ParseGeohash returns the center of the cell of the given geohash
+// and the cell's bounds. Letters may be upper or lower case.
+func ParseGeohash(hash string) (Position, BoundingBox, error) {
+	if hash == "" {
+		return Position{}, BoundingBox{}, fmt.Errorf("parsing geohash: empty geohash")
+	}
+	lat := [2]float64{-90, 90}
+	lng := [2]float64{-180, 180}
+	even := true
+	for _, r := range strings.ToLower(hash) {
+		ch := strings.IndexRune(geohashAlphabet, r)
+		if ch < 0 {
+			return Position{}, BoundingBox{}, fmt.Errorf("parsing geohash %q: invalid character %q", hash, r)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			r := &lat
+			if even {
+				r = &lng
+			}
+			mid := (r[0] + r[1]) / 2
+			if ch&(1<<bit) != 0 {
+				r[0] = mid
+			} else {
+				r[1] = mid
+			}
+			even = !even
+		}
+	}
+	box := BoundingBox{South: lat[0], North: lat[1], West: lng[0], East: lng[1]}
+	return Position{Lat: (lat[0] + lat[1]) / 2, Lng: (lng[0] + lng[1]) / 2}, box, nil
+}
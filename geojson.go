@@ -0,0 +1,269 @@
+package geonames
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// GeoJSONFeature is a GeoJSON Feature (RFC 7946).
+type GeoJSONFeature struct {
+	// ID is the feature identifier, omitted when nil.
+	ID         any
+	Geometry   GeoJSONGeometry
+	Properties map[string]any
+}
+
+// MarshalJSON writes f as a GeoJSON Feature object.
+func (f GeoJSONFeature) MarshalJSON() ([]byte, error) {
+	props := f.Properties
+	if props == nil {
+		props = map[string]any{}
+	}
+	return json.Marshal(struct {
+		Type       string          `json:"type"`
+		ID         any             `json:"id,omitempty"`
+		Geometry   GeoJSONGeometry `json:"geometry"`
+		Properties map[string]any  `json:"properties"`
+	}{"Feature", f.ID, f.Geometry, props})
+}
+
+// GeoJSONGeometry is a GeoJSON geometry. Coordinates holds the JSON
+// coordinates array, longitude first.
+type GeoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// GeoJSONFeaturer is implemented by results that become a GeoJSON Feature.
+type GeoJSONFeaturer interface {
+	GeoJSONFeature() GeoJSONFeature
+}
+
+// MarshalGeoJSON returns the results as a GeoJSON FeatureCollection.
+func MarshalGeoJSON[T GeoJSONFeaturer](results []T) ([]byte, error) {
+	features := make([]GeoJSONFeature, len(results))
+	for i, r := range results {
+		features[i] = r.GeoJSONFeature()
+	}
+	return json.Marshal(struct {
+		Type     string           `json:"type"`
+		Features []GeoJSONFeature `json:"features"`
+	}{"FeatureCollection", features})
+}
+
+// Point returns p as a GeoJSON Point.
+func (p Position) Point() GeoJSONGeometry {
+	return GeoJSONGeometry{Type: "Point", Coordinates: coordinates([2]float64{p.Lng, p.Lat})}
+}
+
+// Polygon returns b as a GeoJSON Polygon, or as a MultiPolygon cut
+// at the antimeridian when b crosses it.
+func (b BoundingBox) Polygon() GeoJSONGeometry {
+	ring := func(west, east float64) [][][2]float64 {
+		return [][][2]float64{{{west, b.South}, {east, b.South}, {east, b.North}, {west, b.North}, {west, b.South}}}
+	}
+	if b.West > b.East {
+		return GeoJSONGeometry{Type: "MultiPolygon", Coordinates: coordinates([][][][2]float64{ring(b.West, 180), ring(-180, b.East)})}
+	}
+	return GeoJSONGeometry{Type: "Polygon", Coordinates: coordinates(ring(b.West, b.East))}
+}
+
+// coordinates encodes a coordinates array, which cannot fail.
+func coordinates(v any) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// GeoJSONFeature returns b as a Feature with a Polygon geometry.
+func (b BoundingBox) GeoJSONFeature() GeoJSONFeature {
+	return GeoJSONFeature{Geometry: b.Polygon()}
+}
+
+// MarshalGeoJSON returns b as a GeoJSON Polygon.
+func (b BoundingBox) MarshalGeoJSON() ([]byte, error) {
+	return json.Marshal(b.Polygon())
+}
+
+// geonameID returns id as a feature ID, or nil if it is unknown.
+func geonameID(id int) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// GeoJSONFeature returns g as a Point Feature
+// identified by its GeoNames ID, if it has one.
+func (g Geoname) GeoJSONFeature() GeoJSONFeature {
+	return GeoJSONFeature{
+		ID:       geonameID(g.GeoNameID),
+		Geometry: g.Position.Point(),
+		Properties: map[string]any{
+			"geonameId":    g.GeoNameID,
+			"title":        g.Title,
+			"summary":      g.Summary,
+			"feature":      g.Feature,
+			"countryCode":  g.CountryCode,
+			"elevation":    g.Elevation,
+			"rank":         g.Rank,
+			"lang":         g.Language,
+			"wikipediaUrl": g.URL,
+		},
+	}
+}
+
+// MarshalGeoJSON returns g as a GeoJSON Feature.
+func (g Geoname) MarshalGeoJSON() ([]byte, error) {
+	return json.Marshal(g.GeoJSONFeature())
+}
+
+// GeoJSONFeature returns p as a Point Feature
+// identified by its GeoNames ID.
+func (p Place) GeoJSONFeature() GeoJSONFeature {
+	props := map[string]any{
+		"geonameId":   p.GeoNameID,
+		"name":        p.Name,
+		"toponymName": p.ToponymName,
+		"countryCode": p.CountryCode,
+		"countryName": p.CountryName,
+		"adminCode1":  p.AdminCode1,
+		"adminName1":  p.AdminName1,
+		"fcl":         p.FeatureClass,
+		"fclName":     p.FeatureClassName,
+		"fcode":       p.FeatureCode,
+		"fcodeName":   p.FeatureCodeName,
+		"population":  p.Population,
+	}
+	if p.Distance != 0 {
+		props["distance"] = p.Distance
+	}
+	return GeoJSONFeature{ID: geonameID(p.GeoNameID), Geometry: p.Position.Point(), Properties: props}
+}
+
+// MarshalGeoJSON returns p as a GeoJSON Feature.
+func (p Place) MarshalGeoJSON() ([]byte, error) {
+	return json.Marshal(p.GeoJSONFeature())
+}
+
+// GeoJSONFeature returns pc as a Point Feature.
+func (pc PostalCode) GeoJSONFeature() GeoJSONFeature {
+	return GeoJSONFeature{
+		Geometry: pc.Position.Point(),
+		Properties: map[string]any{
+			"postalCode":  pc.PostalCode,
+			"placeName":   pc.PlaceName,
+			"countryCode": pc.CountryCode,
+			"adminCode1":  pc.AdminCode1,
+			"adminName1":  pc.AdminName1,
+		},
+	}
+}
+
+// MarshalGeoJSON returns pc as a GeoJSON Feature.
+func (pc PostalCode) MarshalGeoJSON() ([]byte, error) {
+	return json.Marshal(pc.GeoJSONFeature())
+}
+
+// GeoJSONFeature returns e as a Point Feature at the sampled position,
+// with the elevation in meters, the elevation model and, when set, the
+// requested position as [lng, lat] as properties.
+func (e Elevation) GeoJSONFeature() GeoJSONFeature {
+	props := map[string]any{
+		"elevation": e.Value,
+		"model":     e.Type,
+	}
+	if e.Requested != (Position{}) {
+		props["requested"] = [2]float64{e.Requested.Lng, e.Requested.Lat}
+	}
+	return GeoJSONFeature{Geometry: Position{Lat: e.Lat, Lng: e.Lng}.Point(), Properties: props}
+}
+
+// MarshalGeoJSON returns e as a GeoJSON Feature.
+func (e Elevation) MarshalGeoJSON() ([]byte, error) {
+	return json.Marshal(e.GeoJSONFeature())
+}
+
+// geoJSONObject holds the members of any GeoJSON object
+// that DecodeGeoJSONPositions reads.
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    *geoJSONObject  `json:"geometry"`
+	Geometries  []geoJSONObject `json:"geometries"`
+	Features    []geoJSONObject `json:"features"`
+}
+
+// DecodeGeoJSONPositions reads a GeoJSON FeatureCollection, Feature or
+// geometry from r and returns the positions of its Points and
+// MultiPoints in document order, ready for BatchElevations or
+// BatchFindNearby. Features without a geometry are skipped; other
+// geometry types are an error.
+func DecodeGeoJSONPositions(r io.Reader) ([]Position, error) {
+	var obj geoJSONObject
+	if err := json.NewDecoder(r).Decode(&obj); err != nil {
+		return nil, fmt.Errorf("decoding GeoJSON: %w", err)
+	}
+	positions, err := obj.positions(nil)
+	if err != nil {
+		return nil, fmt.Errorf("decoding GeoJSON: %w", err)
+	}
+	return positions, nil
+}
+
+func (o geoJSONObject) positions(dst []Position) ([]Position, error) {
+	var err error
+	switch o.Type {
+	case "FeatureCollection":
+		for _, f := range o.Features {
+			if dst, err = f.positions(dst); err != nil {
+				return nil, err
+			}
+		}
+	case "Feature":
+		if o.Geometry != nil {
+			return o.Geometry.positions(dst)
+		}
+	case "GeometryCollection":
+		for _, g := range o.Geometries {
+			if dst, err = g.positions(dst); err != nil {
+				return nil, err
+			}
+		}
+	case "Point":
+		var c []float64
+		if err := json.Unmarshal(o.Coordinates, &c); err != nil {
+			return nil, fmt.Errorf("point coordinates: %w", err)
+		}
+		return appendPosition(dst, c)
+	case "MultiPoint":
+		var cs [][]float64
+		if err := json.Unmarshal(o.Coordinates, &cs); err != nil {
+			return nil, fmt.Errorf("multipoint coordinates: %w", err)
+		}
+		for _, c := range cs {
+			if dst, err = appendPosition(dst, c); err != nil {
+				return nil, err
+			}
+		}
+	case "":
+		return nil, errors.New("missing type")
+	default:
+		return nil, fmt.Errorf("unsupported type %q", o.Type)
+	}
+	return dst, nil
+}
+
+// appendPosition appends the GeoJSON position c, longitude first,
+// ignoring any altitude.
+func appendPosition(dst []Position, c []float64) ([]Position, error) {
+	if len(c) < 2 {
+		return nil, fmt.Errorf("position %v has fewer than two coordinates", c)
+	}
+	p := Position{Lat: c[1], Lng: c[0]}
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return append(dst, p), nil
+}
@@ -0,0 +1,303 @@
+package geonames_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+)
+
+// unmarshal decodes JSON into generic values, so that
+// documents compare regardless of key order and spacing.
+func unmarshal(t *testing.T, data []byte) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	return v
+}
+
+func TestPostalCodeMarshalGeoJSON_WritesPointFeature(t *testing.T) {
+	t.Parallel()
+
+	pc := geonames.PostalCode{
+		PlaceName:   "Castlebar",
+		AdminName1:  "Connacht",
+		Position:    castlebar,
+		CountryCode: "IE",
+		PostalCode:  "F23",
+		AdminCode1:  "C",
+	}
+	got, err := pc.MarshalGeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+		"type": "Feature",
+		"geometry": {"type": "Point", "coordinates": [-9.29778, 53.85583]},
+		"properties": {
+			"postalCode": "F23",
+			"placeName": "Castlebar",
+			"countryCode": "IE",
+			"adminCode1": "C",
+			"adminName1": "Connacht"
+		}
+	}`
+	if !cmp.Equal(unmarshal(t, []byte(want)), unmarshal(t, got)) {
+		t.Error(cmp.Diff(unmarshal(t, []byte(want)), unmarshal(t, got)))
+	}
+}
+
+func TestElevationMarshalGeoJSON_KeepsValueAndModel(t *testing.T) {
+	t.Parallel()
+
+	got, err := geonames.Elevation{Type: "srtm3", Lat: 53.856, Lng: -9.298, Value: 41}.MarshalGeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+		"type": "Feature",
+		"geometry": {"type": "Point", "coordinates": [-9.298, 53.856]},
+		"properties": {"elevation": 41, "model": "srtm3"}
+	}`
+	if !cmp.Equal(unmarshal(t, []byte(want)), unmarshal(t, got)) {
+		t.Error(cmp.Diff(unmarshal(t, []byte(want)), unmarshal(t, got)))
+	}
+}
+
+func TestElevationMarshalGeoJSON_KeepsRequestedPosition(t *testing.T) {
+	t.Parallel()
+
+	e := geonames.Elevation{Type: "srtm1", Lat: 54.166, Lng: -6.083, Value: 375, Requested: geonames.Position{Lat: 54.16612, Lng: -6.08297}}
+	got, err := e.MarshalGeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+		"type": "Feature",
+		"geometry": {"type": "Point", "coordinates": [-6.083, 54.166]},
+		"properties": {"elevation": 375, "model": "srtm1", "requested": [-6.08297, 54.16612]}
+	}`
+	if !cmp.Equal(unmarshal(t, []byte(want)), unmarshal(t, got)) {
+		t.Error(cmp.Diff(unmarshal(t, []byte(want)), unmarshal(t, got)))
+	}
+}
+
+func TestGeonameMarshalGeoJSON_SetsFeatureID(t *testing.T) {
+	t.Parallel()
+
+	got, err := geonames.Geoname{GeoNameID: 2965654, Title: "Castlebar", Position: castlebar}.MarshalGeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var f struct{ ID any }
+	if err := json.Unmarshal(got, &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.ID != float64(2965654) {
+		t.Errorf("want id 2965654, got %s", got)
+	}
+
+	got, err = geonames.Geoname{Title: "Castlebar", Position: castlebar}.MarshalGeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(got), `"id"`) {
+		t.Errorf("want no id without a GeoNames ID, got %s", got)
+	}
+}
+
+func TestMarshalGeoJSON_WritesFeatureCollection(t *testing.T) {
+	t.Parallel()
+
+	places := []geonames.Place{
+		{GeoNameID: 2965654, Name: "Castlebar", Position: castlebar, CountryCode: "IE", FeatureClass: "P", FeatureCode: "PPLA", Population: 12068},
+		{GeoNameID: 2964574, Name: "Dublin", Position: dublin, CountryCode: "IE", FeatureClass: "P", FeatureCode: "PPLC", Population: 1024027, Distance: 183.2},
+	}
+	data, err := geonames.MarshalGeoJSON(places)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fc struct {
+		Type     string
+		Features []struct {
+			Type     string
+			ID       int
+			Geometry struct {
+				Type        string
+				Coordinates []float64
+			}
+			Properties map[string]any
+		}
+	}
+	if err := json.Unmarshal(data, &fc); err != nil {
+		t.Fatal(err)
+	}
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 2 {
+		t.Fatalf("want a collection of 2 features, got %s", data)
+	}
+	dub := fc.Features[1]
+	if dub.Type != "Feature" || dub.ID != 2964574 || dub.Geometry.Type != "Point" || !cmp.Equal([]float64{dublin.Lng, dublin.Lat}, dub.Geometry.Coordinates) {
+		t.Errorf("unexpected feature %+v", dub)
+	}
+	if dub.Properties["name"] != "Dublin" || dub.Properties["fcode"] != "PPLC" || dub.Properties["distance"] != 183.2 {
+		t.Errorf("unexpected properties %v", dub.Properties)
+	}
+	if _, ok := fc.Features[0].Properties["distance"]; ok {
+		t.Error("want no distance property when Distance is zero")
+	}
+
+	empty, err := geonames.MarshalGeoJSON([]geonames.Geoname(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(empty) != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("want an empty features array, got %s", empty)
+	}
+}
+
+func TestBoundingBoxMarshalGeoJSON_WritesPolygon(t *testing.T) {
+	t.Parallel()
+
+	got, err := geonames.BoundingBox{North: 55, South: 51, East: -6, West: -10}.MarshalGeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"type":"Polygon","coordinates":[[[-10,51],[-6,51],[-6,55],[-10,55],[-10,51]]]}`
+	if string(got) != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+
+	got, err = geonames.BoundingBox{North: -15, South: -20, East: -178, West: 177}.MarshalGeoJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `{"type":"MultiPolygon","coordinates":[` +
+		`[[[177,-20],[180,-20],[180,-15],[177,-15],[177,-20]]],` +
+		`[[[-180,-20],[-178,-20],[-178,-15],[-180,-15],[-180,-20]]]]}`
+	if string(got) != want {
+		t.Errorf("want box cut at the antimeridian %s, got %s", want, got)
+	}
+}
+
+func TestDecodeGeoJSONPositions_ReadsPointsInOrder(t *testing.T) {
+	t.Parallel()
+
+	in := `{
+		"type": "FeatureCollection",
+		"features": [
+			{"type": "Feature", "geometry": {"type": "Point", "coordinates": [-9.29778, 53.85583, 41]}, "properties": {}},
+			{"type": "Feature", "geometry": null, "properties": {"name": "nowhere"}},
+			{"type": "Feature", "geometry": {"type": "MultiPoint", "coordinates": [[-6.24889, 53.33306], [-0.12574, 51.50853]]}},
+			{"type": "Feature", "geometry": {"type": "GeometryCollection", "geometries": [
+				{"type": "Point", "coordinates": [151.2153, -33.8568]}
+			]}}
+		]
+	}`
+	got, err := geonames.DecodeGeoJSONPositions(strings.NewReader(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []geonames.Position{castlebar, dublin, london, {Lat: -33.8568, Lng: 151.2153}}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestDecodeGeoJSONPositions_RoundTripsMarshalledResults(t *testing.T) {
+	t.Parallel()
+
+	data, err := geonames.MarshalGeoJSON([]geonames.PostalCode{{Position: castlebar}, {Position: dublin}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := geonames.DecodeGeoJSONPositions(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []geonames.Position{castlebar, dublin}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestDecodeGeoJSONPositions_RejectsInvalidInput(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{
+		``,
+		`[]`,
+		`{"coordinates": [1, 2]}`,
+		`{"type": "LineString", "coordinates": [[1, 2], [3, 4]]}`,
+		`{"type": "Point", "coordinates": [1]}`,
+		`{"type": "Point", "coordinates": [10, 95]}`,
+		`{"type": "Feature", "geometry": {"type": "Point", "coordinates": "here"}}`,
+	} {
+		if got, err := geonames.DecodeGeoJSONPositions(strings.NewReader(in)); err == nil {
+			t.Errorf("%q: want error, got %v", in, got)
+		}
+	}
+}
+
+func TestBatchElevations_LooksUpEveryPosition(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		fmt.Fprintf(rw, `{"srtm3":41,"lng":%s,"lat":%s}`, q.Get("lng"), q.Get("lat"))
+	}))
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	positions, err := geonames.DecodeGeoJSONPositions(strings.NewReader(
+		`{"type": "MultiPoint", "coordinates": [[-9.298, 53.856], [-6.249, 53.333]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results := client.BatchElevations(context.Background(), positions, geonames.BatchOptions{Concurrency: 2})
+	if len(results) != 2 {
+		t.Fatalf("want 2 results, got %d", len(results))
+	}
+	for i, r := range results {
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		if r.Value.Value != 41 || r.Value.Lat != positions[i].Lat || r.Value.Lng != positions[i].Lng {
+			t.Errorf("result %d: unexpected elevation %+v", i, r.Value)
+		}
+	}
+}
+
+func TestBatchFindNearby_LooksUpEveryPosition(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("radius") != "5" {
+			t.Errorf("want radius 5, got %q", r.URL.Query().Get("radius"))
+		}
+		http.ServeFile(rw, r, "geonamestest/fixtures/nearby.json")
+	}))
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	results := client.BatchFindNearby(context.Background(), []geonames.Position{castlebar, dublin}, geonames.NearbyQuery{Radius: 5}, geonames.BatchOptions{})
+	if len(results) != 2 {
+		t.Fatalf("want 2 results, got %d", len(results))
+	}
+	for i, r := range results {
+		if r.Err != nil || len(r.Value) == 0 {
+			t.Errorf("result %d: want places, got %+v", i, r)
+		}
+	}
+}
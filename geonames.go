@@ -1,12 +1,14 @@
 package geonames
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
+	"net/url"
 	"time"
 )
 
@@ -17,6 +19,30 @@ type Client struct {
 	BaseURL    string
 	HTTPClient *http.Client
 
+	// Token is an optional premium webservice token sent
+	// with every request alongside the username.
+	Token string
+
+	// Language is an optional ISO-639 language code used for
+	// place names in responses (the GeoNames 'lang' parameter).
+	Language string
+
+	// Cache, if set, stores raw responses keyed by request URL.
+	Cache Cache
+
+	// Limiter, if set, is waited on before every outgoing request.
+	Limiter Limiter
+
+	// Retry controls how failed requests are retried.
+	Retry RetryPolicy
+
+	// Observer, if set, is notified after every API call.
+	Observer Observer
+
+	// MaxResponseSize is the largest decompressed response body, in bytes,
+	// the client accepts. Zero means DefaultMaxResponseSize.
+	MaxResponseSize int64
+
 	// Optional HTTP headers to set for each API request.
 	Headers map[string][]string
 }
@@ -24,8 +50,28 @@ type Client struct {
 const (
 	libraryVersion = "0.1"
 	userAgent      = "geonames/" + libraryVersion
+	baseURL        = "http://api.geonames.org"
 )
 
+// New creates a new client for GeoNames Web service
+// configured with the given options.
+//
+// The username has to be registered at the GeoNames.org website.
+// New returns an error if the username is empty or if any
+// of the options is invalid.
+func New(username string, opts ...Option) (*Client, error) {
+	if username == "" {
+		return nil, errors.New("username is required")
+	}
+	c := NewClient(username)
+	for _, opt := range opts {
+		if err := opt(c); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
 // NewClient creates a new client for GeoNames Web service.
 //
 // The username has to be registered at the GeoNames.org website.
@@ -34,42 +80,197 @@ func NewClient(username string) *Client {
 	c := Client{
 		UserName:  username,
 		UserAgent: userAgent,
-		BaseURL:   "http://api.geonames.org",
+		BaseURL:   baseURL,
 		HTTPClient: &http.Client{
 			Timeout: time.Second * 5,
 		},
 		Headers: map[string][]string{
-			"User-Agent":   {userAgent},
 			"Content-Type": {"application/json"},
 		},
 	}
 	return &c
 }
 
-func (c Client) get(ctx context.Context, url string, data any) error {
+// RetryPolicy describes how many times a failed request is attempted
+// and how long to wait between attempts. Only network errors, 429 and
+// 5xx responses and temporary APIErrors are retried. A Retry-After
+// header longer than the backoff is honoured. The zero value
+// disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+	// Backoff is the wait before the first retry. It doubles
+	// after each subsequent attempt.
+	Backoff time.Duration
+}
+
+func (c Client) get(ctx context.Context, url string, data any) (err error) {
+	url, err = c.withParams(url)
+	if err != nil {
+		return err
+	}
+	info := RequestInfo{Endpoint: endpoint(url)}
+	if c.Observer != nil {
+		start := time.Now()
+		defer func() {
+			info.Duration = time.Since(start)
+			info.Err = err
+			var ae *APIError
+			if errors.As(err, &ae) {
+				info.ErrorCode = ae.Code
+			}
+			c.Observer.Observe(ctx, info)
+		}()
+	}
+	if c.Cache != nil {
+		if body, ok := c.Cache.Get(url); ok {
+			info.CacheHit = true
+			return decodeResponse(bytes.NewReader(body), data)
+		}
+	}
+
+	attempts := max(c.Retry.MaxAttempts, 1)
+	backoff := c.Retry.Backoff
+	var body []byte
+	for attempt := 1; ; attempt++ {
+		var status int
+		body, status, err = c.do(ctx, url, data)
+		if status != 0 {
+			info.StatusCode = status
+			info.Credits++
+		}
+		if err == nil {
+			break
+		}
+		if attempt >= attempts || !retryable(err) {
+			return err
+		}
+		wait := backoff
+		var he *HTTPError
+		if errors.As(err, &he) && he.RetryAfter > wait {
+			wait = he.RetryAfter
+		}
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+		info.Retries++
+		backoff *= 2
+	}
+
+	if c.Cache != nil {
+		c.Cache.Set(url, body)
+	}
+	return nil
+}
+
+// do sends a single GET request and decodes the response into data.
+// It returns the HTTP status code, which is zero if no response was
+// received, and the raw response body if the client has a cache.
+func (c Client) do(ctx context.Context, url string, data any) ([]byte, int, error) {
+	if c.Limiter != nil {
+		if err := c.Limiter.Wait(ctx); err != nil {
+			return nil, 0, err
+		}
+	}
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, 0, fmt.Errorf("creating request: %w", err)
 	}
+	for k, v := range c.Headers {
+		req.Header[k] = v
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
 	res, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("sending GET request: %w", err)
+		return nil, 0, fmt.Errorf("sending GET request: %w", redactURLError(err))
 	}
 	defer res.Body.Close()
 
 	if res.StatusCode != http.StatusOK {
-		return fmt.Errorf("got response code: %v", res.StatusCode)
+		return nil, res.StatusCode, newHTTPError(res)
 	}
 
-	body, err := io.ReadAll(res.Body)
+	var r io.Reader = res.Body
+	if res.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return nil, res.StatusCode, fmt.Errorf("reading gzip response body: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	limit := c.MaxResponseSize
+	if limit <= 0 {
+		limit = DefaultMaxResponseSize
+	}
+	r = &limitedReader{r: r, max: limit}
+
+	var buf *bytes.Buffer
+	if c.Cache != nil {
+		buf = new(bytes.Buffer)
+		r = io.TeeReader(r, buf)
+	}
+	if err := decodeResponse(r, data); err != nil {
+		return nil, res.StatusCode, err
+	}
+	if buf == nil {
+		return nil, res.StatusCode, nil
+	}
+	return buf.Bytes(), res.StatusCode, nil
+}
+
+// withParams adds the optional token and language
+// parameters to the request URL.
+func (c Client) withParams(rawURL string) (string, error) {
+	if c.Token == "" && c.Language == "" {
+		return rawURL, nil
+	}
+	u, err := url.Parse(rawURL)
 	if err != nil {
-		return fmt.Errorf("reading response body: %w", err)
+		return "", fmt.Errorf("parsing request url: %w", err)
+	}
+	q := u.Query()
+	if c.Token != "" && !q.Has("token") {
+		q.Set("token", c.Token)
+	}
+	if c.Language != "" && !q.Has("lang") {
+		q.Set("lang", c.Language)
 	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
 
-	if err := json.Unmarshal(body, data); err != nil {
-		return fmt.Errorf("unmarshaling response body: %w", err)
+// retryable reports whether the request that failed with err
+// is worth sending again.
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.Temporary()
+	}
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he.StatusCode == http.StatusTooManyRequests || he.StatusCode >= 500
+	}
+	var ue *url.Error
+	return errors.As(err, &ue)
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
 	}
-	return nil
 }
 
 // Position holds information about Lat and Long.
@@ -80,20 +281,9 @@ type Position struct {
 
 var DemoClient = &Client{
 	UserName:   "demo",
-	BaseURL:    "http://api.geonames.org",
+	BaseURL:    baseURL,
 	HTTPClient: http.DefaultClient,
 	Headers: map[string][]string{
 		"Content-Type": {"application/json"},
 	},
 }
-
-var ClientFromEnv = &Client{
-	UserName: os.Getenv("GEONAMES_USER"),
-	BaseURL:  "http://api.geonames.org",
-	HTTPClient: &http.Client{
-		Timeout: 10 * time.Second,
-	},
-	Headers: map[string][]string{
-		"Content-Type": {"application/json"},
-	},
-}
@@ -64,7 +64,7 @@ func verifyURIs(wanturi, goturi string, t *testing.T) {
 func TestWikipediaResolvesGeoNameOnValidInput(t *testing.T) {
 	t.Parallel()
 
-	testFile := "testdata/response-geoname-wikipedia-single.json"
+	testFile := "geonamestest/fixtures/wikipedia-single.json"
 	wantReqURI := "/wikipediaSearchJSON?q=Castlebar&title=Castlebar&countryCode=IE&maxRows=1&username=DummyUser"
 	ts := newTestServer(testFile, wantReqURI, t)
 	defer ts.Close()
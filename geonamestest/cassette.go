@@ -0,0 +1,267 @@
+package geonamestest
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/internal/redact"
+)
+
+// RecordEnv is the environment variable that switches WithCassette
+// and NewTransport to recording when set to "1" or "true".
+const RecordEnv = "GEONAMES_RECORD"
+
+// Cassette is a recording of Web Service requests and responses,
+// stored as indented JSON so that it can be reviewed in diffs.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is a recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest identifies a request. URL holds its path and
+// query, with the username and token replaced by "REDACTED".
+type RecordedRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+}
+
+// RecordedResponse is a response with its body decompressed.
+// JSON bodies are kept in JSON, other bodies in Body.
+type RecordedResponse struct {
+	Status int             `json:"status"`
+	Header http.Header     `json:"header,omitempty"`
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Body   string          `json:"body,omitempty"`
+}
+
+// LoadCassette reads the cassette stored at path.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path, creating its directory if needed.
+func (c *Cassette) Save(path string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// matchKey identifies a request by method, path and
+// query, ignoring the host and credentials.
+func matchKey(method string, u *url.URL) string {
+	q := u.Query()
+	for _, k := range redact.Params {
+		q.Del(k)
+	}
+	return method + " " + u.Path + "?" + q.Encode()
+}
+
+// RecordingTransport is an http.RoundTripper that sends requests
+// through Transport and appends every exchange to the cassette at
+// Path, which is rewritten after each response. The username and
+// token sent in the query are replaced by "REDACTED" in the URL and in
+// the message of a status envelope, where GeoNames names the account
+// when a credit limit is exceeded.
+type RecordingTransport struct {
+	// Path is the cassette file.
+	Path string
+	// Transport sends the requests. Nil means http.DefaultTransport.
+	Transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	res, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var r io.Reader = res.Body
+	if res.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(res.Body)
+		if err != nil {
+			return nil, fmt.Errorf("recording %s: %w", redact.URL(req.URL), err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("recording %s: %w", redact.URL(req.URL), err)
+	}
+	body = redact.Body(body, req.URL)
+
+	rec := RecordedResponse{Status: res.StatusCode, Header: res.Header.Clone()}
+	for _, h := range []string{"Content-Encoding", "Content-Length", "Date", "Set-Cookie"} {
+		rec.Header.Del(h)
+	}
+	if json.Valid(body) {
+		var buf bytes.Buffer
+		json.Compact(&buf, body)
+		rec.JSON = buf.Bytes()
+	} else {
+		rec.Body = string(body)
+	}
+	t.mu.Lock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{
+		Request:  RecordedRequest{Method: req.Method, URL: redact.URL(req.URL)},
+		Response: rec,
+	})
+	err = t.cassette.Save(t.Path)
+	t.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("saving cassette: %w", err)
+	}
+	return rec.response(req), nil
+}
+
+// response rebuilds the recorded response to req.
+func (r RecordedResponse) response(req *http.Request) *http.Response {
+	body := r.Body
+	if r.JSON != nil {
+		body = string(r.JSON)
+	}
+	header := r.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// ReplayTransport is an http.RoundTripper answering requests from a
+// cassette without network access. A request is matched by method,
+// path and query, ignoring the host, username and token. Recorded
+// interactions are used in order, and the last match is repeated once
+// all matches have been used.
+type ReplayTransport struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayTransport returns a ReplayTransport for the cassette at path.
+func NewReplayTransport(path string) (*ReplayTransport, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ReplayTransport{cassette: c, used: make([]bool, len(c.Interactions))}, nil
+}
+
+// RoundTrip implements http.RoundTripper. It fails for
+// requests that were not recorded.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key := matchKey(req.Method, req.URL)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	last := -1
+	for i, in := range t.cassette.Interactions {
+		u, err := url.Parse(in.Request.URL)
+		if err != nil || matchKey(in.Request.Method, u) != key {
+			continue
+		}
+		if !t.used[i] {
+			t.used[i] = true
+			return in.Response.response(req), nil
+		}
+		last = i
+	}
+	if last < 0 {
+		return nil, fmt.Errorf("geonamestest: no recorded response for %s %s", req.Method, redact.URL(req.URL))
+	}
+	return t.cassette.Interactions[last].Response.response(req), nil
+}
+
+// Recording reports whether the RecordEnv environment
+// variable asks for interactions to be recorded.
+func Recording() bool {
+	return slices.Contains([]string{"1", "true"}, strings.ToLower(os.Getenv(RecordEnv)))
+}
+
+// NewTransport returns a RecordingTransport writing to the cassette at
+// path, sending requests through next, if Recording reports true, and
+// a ReplayTransport reading the cassette otherwise.
+func NewTransport(path string, next http.RoundTripper) (http.RoundTripper, error) {
+	if Recording() {
+		return &RecordingTransport{Path: path, Transport: next}, nil
+	}
+	t, err := NewReplayTransport(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("cassette %s not found; set %s=1 to record it: %w", path, RecordEnv, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// WithCassette returns a geonames.Option that replays the cassette at
+// path, or records it through the client's transport if Recording
+// reports true. The client's HTTP client is copied, not modified.
+func WithCassette(path string) geonames.Option {
+	return func(c *geonames.Client) error {
+		hc := &http.Client{}
+		if c.HTTPClient != nil {
+			*hc = *c.HTTPClient
+		}
+		t, err := NewTransport(path, hc.Transport)
+		if err != nil {
+			return err
+		}
+		hc.Transport = t
+		c.HTTPClient = hc
+		return nil
+	}
+}
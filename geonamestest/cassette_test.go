@@ -0,0 +1,202 @@
+package geonamestest_test
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/geonamestest"
+)
+
+// record searches for Castlebar and then fails a postal code
+// lookup through a RecordingTransport writing to path.
+func record(t *testing.T, path string) []geonames.Place {
+	t.Helper()
+	srv := geonamestest.NewServer(t)
+	srv.Handle("searchJSON", nil, geonamestest.Fixture("search"))
+	srv.Handle("postalCodeSearchJSON", nil, geonamestest.Status(http.StatusServiceUnavailable))
+
+	c := srv.Client(geonames.WithToken("SecretToken"), geonames.WithHTTPClient(&http.Client{
+		Transport: &geonamestest.RecordingTransport{Path: path},
+	}))
+	res, err := c.Search(context.Background(), geonames.SearchQuery{Name: "Castlebar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetPostCode(context.Background(), "Castlebar", "IE"); err == nil {
+		t.Fatal("want error from postal code lookup")
+	}
+	return res.Places
+}
+
+func TestRecordingTransport_WritesReadableCassetteWithoutCredentials(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "search.json")
+	record(t, path)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{geonamestest.Username, "SecretToken"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+	c, err := geonamestest.LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []geonamestest.RecordedRequest
+	for _, in := range c.Interactions {
+		got = append(got, in.Request)
+	}
+	want := []geonamestest.RecordedRequest{
+		{Method: http.MethodGet, URL: "/searchJSON?name=Castlebar&token=REDACTED&username=REDACTED"},
+		{Method: http.MethodGet, URL: "/postalCodeSearchJSON?country=IE&placename=Castlebar&token=REDACTED&username=REDACTED"},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if !strings.Contains(string(data), `"totalResultsCount": 3,`) {
+		t.Errorf("want JSON body stored indented, got:\n%s", data)
+	}
+}
+
+func TestRecordingTransport_RedactsCredentialsInStatusMessagesOnly(t *testing.T) {
+	t.Parallel()
+
+	srv := geonamestest.NewServer(t)
+	srv.Handle("searchJSON", nil, geonamestest.Error(geonames.CodeDailyLimitExceeded,
+		"the daily limit of 20000 credits for "+geonamestest.Username+" has been exceeded."))
+	srv.Handle("postalCodeSearchJSON", nil, geonamestest.JSON(
+		`{"postalCodes":[{"placeName":"`+geonamestest.Username+`","countryCode":"IE","postalCode":"F23"}]}`))
+
+	path := filepath.Join(t.TempDir(), "limit.json")
+	c := srv.Client(geonames.WithToken("SecretToken"), geonames.WithHTTPClient(&http.Client{
+		Transport: &geonamestest.RecordingTransport{Path: path},
+	}))
+	if _, err := c.Search(context.Background(), geonames.SearchQuery{Name: "Castlebar"}); err == nil {
+		t.Fatal("want daily limit error")
+	}
+	if _, err := c.GetPostCode(context.Background(), "Castlebar", "IE"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "SecretToken") {
+		t.Errorf("cassette contains the token:\n%s", data)
+	}
+	for _, want := range []string{"credits for REDACTED has", `"placeName": "` + geonamestest.Username + `"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("want %q in cassette, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestReplayTransport_ReplaysRecordingWithOtherCredentials(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "search.json")
+	want := record(t, path)
+
+	tr, err := geonamestest.NewReplayTransport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := geonames.New("OtherUser",
+		geonames.WithBaseURL("http://geonames.invalid"),
+		geonames.WithHTTPClient(&http.Client{Transport: tr}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Replayed twice: the last match is repeated.
+	for range 2 {
+		res, err := c.Search(context.Background(), geonames.SearchQuery{Name: "Castlebar"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !cmp.Equal(want, res.Places) {
+			t.Error(cmp.Diff(want, res.Places))
+		}
+	}
+	_, err = c.GetPostCode(context.Background(), "Castlebar", "IE")
+	var he *geonames.HTTPError
+	if !errors.As(err, &he) || he.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("want recorded HTTP error 503, got %v", err)
+	}
+	if _, err := c.Search(context.Background(), geonames.SearchQuery{Name: "Westport"}); err == nil {
+		t.Error("want error for request missing from the cassette")
+	}
+}
+
+func TestRecordingTransport_StoresGzipResponsesDecompressed(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		zw := gzip.NewWriter(w)
+		zw.Write([]byte(`{"geonames":[]}`))
+		zw.Close()
+	}))
+	defer ts.Close()
+
+	path := filepath.Join(t.TempDir(), "gzip.json")
+	c, err := geonames.New("User",
+		geonames.WithBaseURL(ts.URL),
+		geonames.WithHTTPClient(&http.Client{Transport: &geonamestest.RecordingTransport{Path: path}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetHierarchy(context.Background(), 2965654); err != nil {
+		t.Fatal(err)
+	}
+	cassette, err := geonamestest.LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(strings.Fields(string(cassette.Interactions[0].Response.JSON)), ""); got != `{"geonames":[]}` {
+		t.Errorf("want decompressed body, got %q", got)
+	}
+}
+
+func TestWithCassette_RecordsWhenEnvironmentAsks(t *testing.T) {
+	t.Setenv(geonamestest.RecordEnv, "")
+	path := filepath.Join(t.TempDir(), "search.json")
+	srv := geonamestest.NewServer(t)
+	srv.Handle("searchJSON", nil, geonamestest.Fixture("search"))
+
+	if _, err := geonames.New("User", geonamestest.WithCassette(path)); err == nil {
+		t.Fatal("want error replaying a missing cassette")
+	}
+
+	t.Setenv(geonamestest.RecordEnv, "1")
+	if _, err := srv.Client(geonamestest.WithCassette(path)).Search(context.Background(), geonames.SearchQuery{Q: "Castlebar"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(geonamestest.RecordEnv, "")
+	c, err := geonames.New("User", geonames.WithBaseURL("http://geonames.invalid"), geonamestest.WithCassette(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Search(context.Background(), geonames.SearchQuery{Q: "Castlebar"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.Count("searchJSON"); n != 1 {
+		t.Errorf("want one request recorded and one replayed, got %d requests", n)
+	}
+}
@@ -0,0 +1,258 @@
+// Package geonamestest provides an in-process fake of the GeoNames
+// Web Service for testing code that uses a geonames.Client.
+//
+// Tests register canned responses per endpoint and query, point a
+// client at the fake and then inspect the requests it received:
+//
+//	srv := geonamestest.NewServer(t)
+//	srv.Handle("postalCodeSearchJSON", url.Values{"placename": {"Castlebar"}}, geonamestest.Fixture("postal-single"))
+//	client := srv.Client()
+//	...
+//	if n := len(srv.Requests()); n != 1 {
+//		t.Errorf("want one request, got %d", n)
+//	}
+package geonamestest
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qba73/geonames"
+)
+
+// Username is the GeoNames username used by clients returned by Server.Client.
+const Username = "geonamestest"
+
+// fixtures holds recorded Web Service responses. The tests of package
+// geonames read the same files, so that there is one copy of each.
+//
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Response is a canned response served by a Server.
+type Response struct {
+	// Status is the HTTP status code. Zero means 200 OK.
+	Status int
+	// Header holds extra response headers, e.g. Retry-After.
+	Header http.Header
+	// Body is the response body.
+	Body []byte
+	// Delay is how long the server waits before responding.
+	Delay time.Duration
+}
+
+// JSON returns a 200 OK response with the given body.
+func JSON(body string) Response {
+	return Response{Body: []byte(body)}
+}
+
+// Fixture returns a 200 OK response holding one of the built-in
+// fixtures, which are sample responses of the Web Service:
+//
+//   - "country": countryInfoJSON for Ireland
+//   - "hierarchy": hierarchyJSON for Castlebar
+//   - "nearby": findNearbyJSON around Castlebar
+//   - "postal-single": postalCodeSearchJSON for Castlebar
+//   - "postal-multiple": postalCodeSearchJSON for Dublin
+//   - "postal-large": postalCodeSearchJSON returning many postal codes
+//   - "search": searchJSON for Castlebar
+//   - "timezone": timezoneJSON for Castlebar
+//   - "wikipedia-single": wikipediaSearchJSON returning one article
+//   - "wikipedia": wikipediaSearchJSON returning several articles
+//
+// Fixture panics if there is no fixture with the given name.
+func Fixture(name string) Response {
+	body, err := fixtures.ReadFile(path.Join("fixtures", name+".json"))
+	if err != nil {
+		panic(fmt.Sprintf("geonamestest: unknown fixture %q", name))
+	}
+	return Response{Body: body}
+}
+
+// Error returns a 200 OK response carrying a GeoNames status envelope
+// with the given code, which a Client reports as a *geonames.APIError.
+func Error(code int, message string) Response {
+	body, _ := json.Marshal(map[string]*geonames.APIError{"status": {Code: code, Message: message}})
+	return Response{Body: body}
+}
+
+// Status returns an empty response with the given HTTP status
+// code, e.g. http.StatusServiceUnavailable.
+func Status(code int) Response {
+	return Response{Status: code}
+}
+
+// After returns a copy of r that is served after a delay of d.
+func (r Response) After(d time.Duration) Response {
+	r.Delay = d
+	return r
+}
+
+// Request is a request received by a Server.
+type Request struct {
+	// Endpoint is the name of the Web Service, e.g. "searchJSON".
+	Endpoint string
+	// Query holds the query parameters, including username and token.
+	Query  url.Values
+	Header http.Header
+}
+
+type route struct {
+	endpoint  string
+	query     url.Values
+	responses []Response
+	served    int
+}
+
+// matches reports whether req asks for the route's endpoint with
+// at least the route's query parameters.
+func (rt *route) matches(req Request) bool {
+	if rt.endpoint != req.Endpoint {
+		return false
+	}
+	for key, values := range rt.query {
+		if !slices.Equal(values, req.Query[key]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Server is a fake GeoNames Web Service. Its methods are safe
+// for concurrent use.
+type Server struct {
+	// URL is the base URL of the server, for use with geonames.WithBaseURL.
+	URL string
+
+	tb     testing.TB
+	mu     sync.Mutex
+	routes []*route
+	reqs   []Request
+}
+
+// NewServer starts a Server that is closed when the test ends.
+// Requests matching no registered route are reported with tb.Errorf
+// and answered with 404 Not Found.
+func NewServer(tb testing.TB) *Server {
+	tb.Helper()
+	s := &Server{tb: tb}
+	ts := httptest.NewServer(s)
+	tb.Cleanup(ts.Close)
+	s.URL = ts.URL
+	return s
+}
+
+// Handle serves responses for requests to endpoint whose query holds
+// every parameter in query with the same values. Other parameters,
+// such as username and token, are not compared; a nil query matches
+// every request to the endpoint.
+//
+// The responses are served in turn to successive matching requests
+// and the last one is repeated, so that, for example, a 503 response
+// followed by a fixture tests a retry. When several routes match a
+// request, the one registered last is used.
+func (s *Server) Handle(endpoint string, query url.Values, responses ...Response) {
+	if len(responses) == 0 {
+		panic("geonamestest: Handle needs at least one response")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = append(s.routes, &route{endpoint: endpoint, query: query, responses: responses})
+}
+
+// Client returns a client for the server, configured with opts.
+func (s *Server) Client(opts ...geonames.Option) *geonames.Client {
+	s.tb.Helper()
+	c, err := geonames.New(Username, append([]geonames.Option{geonames.WithBaseURL(s.URL)}, opts...)...)
+	if err != nil {
+		s.tb.Fatal(err)
+	}
+	return c
+}
+
+// Requests returns the requests received so far, oldest first.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.reqs)
+}
+
+// Count returns the number of requests received for endpoint.
+func (s *Server) Count(endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for _, r := range s.reqs {
+		if r.Endpoint == endpoint {
+			n++
+		}
+	}
+	return n
+}
+
+// Reset forgets the received requests and registered routes.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes, s.reqs = nil, nil
+}
+
+// ServeHTTP records r and serves the response of the matching route.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := Request{
+		Endpoint: strings.TrimPrefix(r.URL.Path, "/"),
+		Query:    r.URL.Query(),
+		Header:   r.Header.Clone(),
+	}
+	res, ok := s.respond(req)
+	if !ok {
+		s.tb.Errorf("geonamestest: unexpected request %s?%s", r.URL.Path, r.URL.RawQuery)
+		http.NotFound(w, r)
+		return
+	}
+	if res.Delay > 0 {
+		t := time.NewTimer(res.Delay)
+		defer t.Stop()
+		select {
+		case <-r.Context().Done():
+			return
+		case <-t.C:
+		}
+	}
+	for k, v := range res.Header {
+		w.Header()[k] = v
+	}
+	if w.Header().Get("Content-Type") == "" {
+		w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	}
+	if res.Status != 0 {
+		w.WriteHeader(res.Status)
+	}
+	w.Write(res.Body)
+}
+
+func (s *Server) respond(req Request) (Response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.reqs = append(s.reqs, req)
+	for i := len(s.routes) - 1; i >= 0; i-- {
+		rt := s.routes[i]
+		if !rt.matches(req) {
+			continue
+		}
+		res := rt.responses[min(rt.served, len(rt.responses)-1)]
+		rt.served++
+		return res, true
+	}
+	return Response{}, false
+}
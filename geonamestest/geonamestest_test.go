@@ -0,0 +1,141 @@
+package geonamestest_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/geonamestest"
+)
+
+func TestServer_ServesFixtureForMatchingQuery(t *testing.T) {
+	t.Parallel()
+
+	srv := geonamestest.NewServer(t)
+	srv.Handle("postalCodeSearchJSON", url.Values{"placename": {"Dublin"}}, geonamestest.Fixture("postal-multiple"))
+	srv.Handle("postalCodeSearchJSON", url.Values{"placename": {"Castlebar"}}, geonamestest.Fixture("postal-single"))
+
+	got, err := srv.Client().GetPostCode(context.Background(), "Castlebar", "IE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].PostalCode != "F23" {
+		t.Errorf("want postal code F23, got %v", got)
+	}
+}
+
+func TestServer_RecordsRequests(t *testing.T) {
+	t.Parallel()
+
+	srv := geonamestest.NewServer(t)
+	srv.Handle("searchJSON", nil, geonamestest.Fixture("search"))
+	c := srv.Client(geonames.WithToken("Secret"))
+	if _, err := c.Search(context.Background(), geonames.SearchQuery{Name: "Castlebar", MaxRows: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("want one request, got %d", len(reqs))
+	}
+	want := url.Values{
+		"name":     {"Castlebar"},
+		"maxRows":  {"3"},
+		"username": {geonamestest.Username},
+		"token":    {"Secret"},
+	}
+	if !cmp.Equal(want, reqs[0].Query) {
+		t.Error(cmp.Diff(want, reqs[0].Query))
+	}
+	if n := srv.Count("searchJSON"); n != 1 {
+		t.Errorf("want one searchJSON request, got %d", n)
+	}
+}
+
+func TestServer_SimulatesStatusEnvelopeErrors(t *testing.T) {
+	t.Parallel()
+
+	srv := geonamestest.NewServer(t)
+	srv.Handle("searchJSON", nil, geonamestest.Error(geonames.CodeHourlyLimitExceeded, "hourly limit exceeded"))
+
+	_, err := srv.Client().Search(context.Background(), geonames.SearchQuery{Q: "Castlebar"})
+	var ae *geonames.APIError
+	if !errors.As(err, &ae) || ae.Code != geonames.CodeHourlyLimitExceeded {
+		t.Errorf("want API error %d, got %v", geonames.CodeHourlyLimitExceeded, err)
+	}
+}
+
+func TestServer_ServesResponsesInTurn(t *testing.T) {
+	t.Parallel()
+
+	srv := geonamestest.NewServer(t)
+	srv.Handle("timezoneJSON", nil, geonamestest.Status(http.StatusServiceUnavailable), geonamestest.Fixture("timezone"))
+
+	c := srv.Client(geonames.WithRetry(2, time.Millisecond))
+	tz, err := c.GetTimezone(context.Background(), geonames.Position{Lat: 53.85583, Lng: -9.29778})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tz.TimezoneID != "Europe/Dublin" {
+		t.Errorf("want Europe/Dublin, got %q", tz.TimezoneID)
+	}
+	if n := srv.Count("timezoneJSON"); n != 2 {
+		t.Errorf("want the failed request retried once, got %d requests", n)
+	}
+}
+
+func TestServer_SimulatesLatency(t *testing.T) {
+	t.Parallel()
+
+	srv := geonamestest.NewServer(t)
+	srv.Handle("searchJSON", nil, geonamestest.Fixture("search").After(time.Second))
+
+	c := srv.Client(geonames.WithTimeout(50 * time.Millisecond))
+	if _, err := c.Search(context.Background(), geonames.SearchQuery{Q: "Castlebar"}); err == nil {
+		t.Error("want timeout error")
+	}
+}
+
+// recorder is a testing.TB that collects reported errors.
+type recorder struct {
+	testing.TB
+	errs []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf(format, args...))
+}
+
+func TestServer_ReportsUnexpectedRequests(t *testing.T) {
+	t.Parallel()
+
+	rec := &recorder{TB: t}
+	srv := geonamestest.NewServer(rec)
+	srv.Handle("searchJSON", url.Values{"q": {"Dublin"}}, geonamestest.Fixture("search"))
+
+	_, err := srv.Client().Search(context.Background(), geonames.SearchQuery{Q: "Castlebar"})
+	var he *geonames.HTTPError
+	if !errors.As(err, &he) || he.StatusCode != http.StatusNotFound {
+		t.Errorf("want HTTP error 404, got %v", err)
+	}
+	if len(rec.errs) != 1 {
+		t.Errorf("want one reported error, got %v", rec.errs)
+	}
+}
+
+func TestFixture_PanicsOnUnknownName(t *testing.T) {
+	t.Parallel()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("want panic")
+		}
+	}()
+	geonamestest.Fixture("no-such-fixture")
+}
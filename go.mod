@@ -1,5 +1,8 @@
 module github.com/qba73/geonames
 
-go 1.22
+go 1.23.0
 
-require github.com/google/go-cmp v0.7.0
+require (
+	github.com/google/go-cmp v0.7.0
+	golang.org/x/text v0.28.0
+)
@@ -0,0 +1,40 @@
+package geonames
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// GetHierarchy returns the places above the place with the given
+// GeoNames ID, from the top (usually the Earth) down to the place
+// itself.
+func (c Client) GetHierarchy(ctx context.Context, geonameID int) ([]Place, error) {
+	u, err := c.buildHierarchyURL(geonameID)
+	if err != nil {
+		return nil, err
+	}
+	var sr searchResponse
+	if err := c.get(ctx, u, &sr); err != nil {
+		return nil, err
+	}
+	places := make([]Place, 0, len(sr.Geonames))
+	for _, p := range sr.Geonames {
+		places = append(places, p.place())
+	}
+	return places, nil
+}
+
+func (c Client) buildHierarchyURL(geonameID int) (string, error) {
+	params := url.Values{
+		"geonameId": {strconv.Itoa(geonameID)},
+		"username":  {c.UserName},
+	}
+	u, err := url.Parse(fmt.Sprintf("%s/hierarchyJSON", c.BaseURL))
+	if err != nil {
+		return "", fmt.Errorf("parsing hierarchy base url: %w", err)
+	}
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
@@ -0,0 +1,38 @@
+package geonames_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+)
+
+func TestGetHierarchy_ReturnsPlacesFromTopDown(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(
+		"geonamestest/fixtures/hierarchy.json",
+		"/hierarchyJSON?geonameId=2965654&username=DummyUser",
+		t,
+	)
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	places, err := client.GetHierarchy(context.Background(), 2965654)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range places {
+		got = append(got, p.Name)
+	}
+	if want := []string{"Earth", "Ireland", "Castlebar"}; !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+	if places[0].Population != 6814400000 {
+		t.Errorf("want population of Earth 6814400000, got %d", places[0].Population)
+	}
+}
@@ -0,0 +1,83 @@
+// Package redact hides GeoNames credentials in request URLs and in
+// the response bodies that echo them.
+package redact
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// Placeholder replaces credential values.
+const Placeholder = "REDACTED"
+
+// Params are the query parameters holding credentials.
+var Params = []string{"username", "token"}
+
+// URL returns the path and query of u with credentials hidden.
+func URL(u *url.URL) string {
+	q := query(u)
+	if len(q) == 0 {
+		return u.Path
+	}
+	return u.Path + "?" + q.Encode()
+}
+
+// FullURL returns u in full, with credentials hidden.
+func FullURL(u *url.URL) string {
+	r := *u
+	r.User = nil
+	r.RawQuery = query(u).Encode()
+	return r.String()
+}
+
+func query(u *url.URL) url.Values {
+	q := u.Query()
+	for _, k := range Params {
+		if q.Has(k) {
+			q.Set(k, Placeholder)
+		}
+	}
+	return q
+}
+
+// Body returns body with every credential value sent in the query
+// of u replaced in the message of the status envelope, where GeoNames
+// names the account in its daily limit messages. The rest of the body
+// is returned unchanged, since a short username may well also be part
+// of the place data.
+func Body(body []byte, u *url.URL) []byte {
+	var env map[string]json.RawMessage
+	if json.Unmarshal(body, &env) != nil || env["status"] == nil {
+		return body
+	}
+	var status map[string]json.RawMessage
+	var msg string
+	if json.Unmarshal(env["status"], &status) != nil || json.Unmarshal(status["message"], &msg) != nil {
+		return body
+	}
+	hidden := Text(msg, u)
+	if hidden == msg {
+		return body
+	}
+	status["message"], _ = json.Marshal(hidden)
+	env["status"], _ = json.Marshal(status)
+	out, err := json.Marshal(env)
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+// Text returns s with every credential value sent in the query of u replaced.
+func Text(s string, u *url.URL) string {
+	q := u.Query()
+	for _, k := range Params {
+		for _, v := range q[k] {
+			if v != "" {
+				s = strings.ReplaceAll(s, v, Placeholder)
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+package redact_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/qba73/geonames/internal/redact"
+)
+
+func TestURL_HidesCredentials(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("https://api.geonames.org/searchJSON?q=Castlebar&username=alice&token=s3cret")
+	want := "/searchJSON?q=Castlebar&token=REDACTED&username=REDACTED"
+	if got := redact.URL(u); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+	u, _ = url.Parse("https://api.geonames.org/searchJSON")
+	if got := redact.URL(u); got != "/searchJSON" {
+		t.Errorf("want path only, got %q", got)
+	}
+}
+
+func TestFullURL_KeepsHostAndHidesCredentials(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("https://api.geonames.org/searchJSON?q=Castlebar&username=alice&token=s3cret")
+	want := "https://api.geonames.org/searchJSON?q=Castlebar&token=REDACTED&username=REDACTED"
+	if got := redact.FullURL(u); got != want {
+		t.Errorf("want %q, got %q", want, got)
+	}
+}
+
+func TestBody_HidesCredentialsEchoedInBody(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("/searchJSON?q=Castlebar&username=alice&token=")
+	body := []byte(`{"status":{"message":"the daily limit of 20000 credits for alice has been exceeded.","value":18}}`)
+	want := `{"status":{"message":"the daily limit of 20000 credits for REDACTED has been exceeded.","value":18}}`
+	if got := redact.Body(body, u); string(got) != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestBody_KeepsCredentialValuesInPlaceData(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("/searchJSON?q=Paris&username=paris")
+	body := []byte(`{"geonames":[{"name":"Paris","toponymName":"paris","wikipedia":"en.wikipedia.org/wiki/paris"}]}`)
+	if got := redact.Body(body, u); string(got) != string(body) {
+		t.Errorf("want body unchanged, got %s", got)
+	}
+	body = []byte(`{"status":{"message":"the daily limit of 20000 credits for paris has been exceeded.","value":18}}`)
+	want := `{"status":{"message":"the daily limit of 20000 credits for REDACTED has been exceeded.","value":18}}`
+	if got := redact.Body(body, u); string(got) != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,66 @@
+package geonames
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// NearbyQuery holds the parameters of a reverse geocoding lookup.
+// Empty fields are not sent.
+type NearbyQuery struct {
+	// Radius is the search radius in kilometers.
+	Radius float64
+	// FeatureClass restricts results to the given feature classes, e.g. "P".
+	FeatureClass []string
+	// FeatureCode restricts results to the given feature codes, e.g. "PPLC".
+	FeatureCode []string
+	// MaxRows limits the number of places. Zero means the service default.
+	MaxRows int
+}
+
+// FindNearby returns the places closest to pos, nearest first,
+// with their Distance set. It implements ReverseGeocoder.
+func (c Client) FindNearby(ctx context.Context, pos Position, q NearbyQuery) ([]Place, error) {
+	u, err := c.buildNearbyURL(pos, q)
+	if err != nil {
+		return nil, err
+	}
+	var sr searchResponse
+	if err := c.get(ctx, u, &sr); err != nil {
+		return nil, err
+	}
+	places := make([]Place, 0, len(sr.Geonames))
+	for _, p := range sr.Geonames {
+		places = append(places, p.place())
+	}
+	return places, nil
+}
+
+func (c Client) buildNearbyURL(pos Position, q NearbyQuery) (string, error) {
+	params := url.Values{
+		"lat":      {strconv.FormatFloat(pos.Lat, 'f', -1, 64)},
+		"lng":      {strconv.FormatFloat(pos.Lng, 'f', -1, 64)},
+		"username": {c.UserName},
+	}
+	if q.Radius > 0 {
+		params.Set("radius", strconv.FormatFloat(q.Radius, 'f', -1, 64))
+	}
+	for _, fc := range q.FeatureClass {
+		params.Add("featureClass", fc)
+	}
+	for _, fc := range q.FeatureCode {
+		params.Add("featureCode", fc)
+	}
+	if q.MaxRows > 0 {
+		params.Set("maxRows", strconv.Itoa(q.MaxRows))
+	}
+
+	u, err := url.Parse(fmt.Sprintf("%s/findNearbyJSON", c.BaseURL))
+	if err != nil {
+		return "", fmt.Errorf("parsing nearby base url: %w", err)
+	}
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
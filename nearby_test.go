@@ -0,0 +1,68 @@
+package geonames_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+)
+
+func TestFindNearby_ReturnsPlacesWithDistanceOnValidInput(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(
+		"geonamestest/fixtures/nearby.json",
+		"/findNearbyJSON?lat=53.8534&lng=-9.2985&radius=5&featureClass=P&maxRows=2&username=DummyUser",
+		t,
+	)
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	got, err := client.FindNearby(context.Background(), geonames.Position{Lat: 53.8534, Lng: -9.2985}, geonames.NearbyQuery{
+		Radius:       5,
+		FeatureClass: []string{"P"},
+		MaxRows:      2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []geonames.Place{
+		{
+			GeoNameID:        2965654,
+			Name:             "Castlebar",
+			ToponymName:      "Castlebar",
+			Position:         geonames.Position{Lat: 53.85583, Lng: -9.29778},
+			CountryCode:      "IE",
+			CountryName:      "Ireland",
+			AdminCode1:       "C",
+			AdminName1:       "Connacht",
+			FeatureClass:     "P",
+			FeatureClassName: "city, village,...",
+			FeatureCode:      "PPLA2",
+			FeatureCodeName:  "seat of a second-order administrative division",
+			Population:       12068,
+			Distance:         0.28112,
+		},
+		{
+			GeoNameID:        2962153,
+			Name:             "Knockaphunta",
+			ToponymName:      "Knockaphunta",
+			Position:         geonames.Position{Lat: 53.86667, Lng: -9.25},
+			CountryCode:      "IE",
+			CountryName:      "Ireland",
+			AdminCode1:       "C",
+			AdminName1:       "Connacht",
+			FeatureClass:     "P",
+			FeatureClassName: "city, village,...",
+			FeatureCode:      "PPL",
+			FeatureCodeName:  "populated place",
+			Distance:         3.30524,
+		},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
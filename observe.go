@@ -0,0 +1,150 @@
+package geonames
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log/slog"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+)
+
+// RequestInfo describes a single API call made by the Client.
+type RequestInfo struct {
+	// Endpoint is the name of the Web Service, e.g. "postalCodeSearchJSON".
+	Endpoint string
+	// Duration is the time spent on the call, including retries.
+	Duration time.Duration
+	// StatusCode is the HTTP status of the last response, or zero
+	// if no response was received or the call was served from cache.
+	StatusCode int
+	// ErrorCode is the GeoNames status code reported in the
+	// response body, or zero if there was none.
+	ErrorCode int
+	// Retries is the number of times the request was resent.
+	Retries int
+	// CacheHit reports whether the response was served from the cache.
+	CacheHit bool
+	// Credits is the number of requests that reached the Web Service
+	// and so count towards the account's credit limits.
+	Credits int
+	// Err is the error returned to the caller, if any.
+	Err error
+}
+
+// Observer is notified after every API call made by the Client.
+//
+// Implementations must be safe for concurrent use.
+type Observer interface {
+	Observe(ctx context.Context, info RequestInfo)
+}
+
+// ObserverFunc adapts a function to the Observer interface.
+type ObserverFunc func(ctx context.Context, info RequestInfo)
+
+// Observe calls f(ctx, info).
+func (f ObserverFunc) Observe(ctx context.Context, info RequestInfo) {
+	f(ctx, info)
+}
+
+// MultiObserver returns an Observer that notifies each of the given observers.
+func MultiObserver(observers ...Observer) Observer {
+	return ObserverFunc(func(ctx context.Context, info RequestInfo) {
+		for _, o := range observers {
+			o.Observe(ctx, info)
+		}
+	})
+}
+
+// WithObserver sets the observer notified after every API call.
+func WithObserver(o Observer) Option {
+	return func(c *Client) error {
+		if o == nil {
+			return errors.New("nil observer")
+		}
+		c.Observer = o
+		return nil
+	}
+}
+
+// SlogObserver logs every API call with a slog.Logger. Successful calls
+// are logged at the Debug level and failed calls at the Error level.
+type SlogObserver struct {
+	Logger *slog.Logger
+}
+
+// NewSlogObserver creates a SlogObserver writing to logger.
+func NewSlogObserver(logger *slog.Logger) *SlogObserver {
+	return &SlogObserver{Logger: logger}
+}
+
+// Observe logs the call described by info.
+func (o *SlogObserver) Observe(ctx context.Context, info RequestInfo) {
+	attrs := []slog.Attr{
+		slog.String("endpoint", info.Endpoint),
+		slog.Duration("duration", info.Duration),
+		slog.Int("status", info.StatusCode),
+		slog.Int("retries", info.Retries),
+		slog.Bool("cache_hit", info.CacheHit),
+		slog.Int("credits", info.Credits),
+	}
+	level := slog.LevelDebug
+	if info.Err != nil {
+		level = slog.LevelError
+		attrs = append(attrs,
+			slog.Int("error_code", info.ErrorCode),
+			slog.String("error", info.Err.Error()),
+		)
+	}
+	o.Logger.LogAttrs(ctx, level, "geonames request", attrs...)
+}
+
+// ExpvarObserver counts API calls in an expvar.Map.
+//
+// It maintains the totals "requests", "errors", "retries",
+// "cache_hits", "cache_misses", "credits" and "duration_ns", and the
+// per-key counters "endpoint.<name>", "status.<code>" and "error_code.<code>".
+type ExpvarObserver struct {
+	Vars *expvar.Map
+}
+
+// NewExpvarObserver creates an ExpvarObserver adding to m.
+// Publish the map with expvar.NewMap to expose it on /debug/vars.
+func NewExpvarObserver(m *expvar.Map) *ExpvarObserver {
+	return &ExpvarObserver{Vars: m}
+}
+
+// Observe adds the call described by info to the counters.
+func (o *ExpvarObserver) Observe(_ context.Context, info RequestInfo) {
+	m := o.Vars
+	m.Add("requests", 1)
+	m.Add("endpoint."+info.Endpoint, 1)
+	m.Add("retries", int64(info.Retries))
+	m.Add("credits", int64(info.Credits))
+	m.Add("duration_ns", int64(info.Duration))
+	if info.CacheHit {
+		m.Add("cache_hits", 1)
+	} else {
+		m.Add("cache_misses", 1)
+	}
+	if info.StatusCode != 0 {
+		m.Add("status."+strconv.Itoa(info.StatusCode), 1)
+	}
+	if info.Err != nil {
+		m.Add("errors", 1)
+	}
+	if info.ErrorCode != 0 {
+		m.Add("error_code."+strconv.Itoa(info.ErrorCode), 1)
+	}
+}
+
+// endpoint returns the Web Service name from the request URL.
+func endpoint(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return path.Base(u.Path)
+}
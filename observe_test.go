@@ -0,0 +1,171 @@
+package geonames_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/qba73/geonames"
+)
+
+// recorder is an Observer that stores every reported call.
+type recorder struct {
+	mu    sync.Mutex
+	infos []geonames.RequestInfo
+}
+
+func (r *recorder) Observe(_ context.Context, info geonames.RequestInfo) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.infos = append(r.infos, info)
+}
+
+func TestObserver_ReportsRetriesCreditsAndCacheHits(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		http.ServeFile(rw, r, "geonamestest/fixtures/postal-single.json")
+	}))
+	defer ts.Close()
+
+	rec := &recorder{}
+	c, err := geonames.New("DummyUser",
+		geonames.WithBaseURL(ts.URL),
+		geonames.WithRetry(2, time.Millisecond),
+		geonames.WithCache(geonames.NewMemoryCache(time.Minute)),
+		geonames.WithObserver(rec),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := c.GetPostCode(context.Background(), "Castlebar", "IE"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []geonames.RequestInfo{
+		{Endpoint: "postalCodeSearchJSON", StatusCode: http.StatusOK, Retries: 1, Credits: 2},
+		{Endpoint: "postalCodeSearchJSON", CacheHit: true},
+	}
+	if !cmp.Equal(want, rec.infos, cmpopts.IgnoreFields(geonames.RequestInfo{}, "Duration")) {
+		t.Error(cmp.Diff(want, rec.infos))
+	}
+}
+
+func TestObserver_ReportsGeoNamesErrorCode(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`{"status":{"message":"the hourly limit of 1000 credits has been exceeded","value":19}}`))
+	}))
+	defer ts.Close()
+
+	rec := &recorder{}
+	c, err := geonames.New("DummyUser", geonames.WithBaseURL(ts.URL), geonames.WithObserver(rec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetPostCode(context.Background(), "Castlebar", "IE"); err == nil {
+		t.Fatal("want error")
+	}
+	if len(rec.infos) != 1 {
+		t.Fatalf("want one observed call, got %d", len(rec.infos))
+	}
+	if got := rec.infos[0].ErrorCode; got != geonames.CodeHourlyLimitExceeded {
+		t.Errorf("want error code 19, got %d", got)
+	}
+}
+
+func TestSlogObserver_LogsEndpointAndStatus(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	o := geonames.NewSlogObserver(logger)
+	o.Observe(context.Background(), geonames.RequestInfo{
+		Endpoint:   "srtm3JSON",
+		StatusCode: 200,
+		Credits:    1,
+	})
+
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["endpoint"] != "srtm3JSON" || got["status"] != 200.0 || got["level"] != "DEBUG" {
+		t.Errorf("unexpected log record: %v", got)
+	}
+}
+
+func TestSlogObserver_HidesCredentialsOfTransportErrors(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.NotFoundHandler())
+	ts.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	c, err := geonames.New("SecretUser",
+		geonames.WithBaseURL(ts.URL),
+		geonames.WithToken("SECRETTOKEN"),
+		geonames.WithObserver(geonames.NewSlogObserver(logger)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetPostCode(context.Background(), "Castlebar", "IE"); err == nil {
+		t.Fatal("want error from unreachable server")
+	}
+	if !strings.Contains(buf.String(), "REDACTED") {
+		t.Errorf("want redacted request URL in log, got %s", buf.String())
+	}
+	for _, secret := range []string{"SecretUser", "SECRETTOKEN"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("want %s hidden, got log %s", secret, buf.String())
+		}
+	}
+}
+
+func TestExpvarObserver_CountsRequests(t *testing.T) {
+	t.Parallel()
+
+	m := new(expvar.Map).Init()
+	o := geonames.NewExpvarObserver(m)
+	o.Observe(context.Background(), geonames.RequestInfo{Endpoint: "searchJSON", StatusCode: 200, Credits: 1})
+	o.Observe(context.Background(), geonames.RequestInfo{Endpoint: "searchJSON", CacheHit: true})
+
+	for key, want := range map[string]string{
+		"requests":            "2",
+		"endpoint.searchJSON": "2",
+		"cache_hits":          "1",
+		"cache_misses":        "1",
+		"credits":             "1",
+		"status.200":          "1",
+	} {
+		v := m.Get(key)
+		if v == nil {
+			t.Errorf("%s: missing counter", key)
+			continue
+		}
+		if got := v.String(); got != want {
+			t.Errorf("%s: want %s, got %s", key, want, got)
+		}
+	}
+}
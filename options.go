@@ -0,0 +1,123 @@
+package geonames
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Option configures a Client created with New.
+type Option func(*Client) error
+
+// WithBaseURL sets the URL of the GeoNames Web Service,
+// for example a premium endpoint or a local test server.
+// The URL must be absolute and include a scheme.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) error {
+		u, err := url.Parse(baseURL)
+		if err != nil {
+			return fmt.Errorf("parsing base url: %w", err)
+		}
+		if u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid base url %q: scheme and host are required", baseURL)
+		}
+		c.BaseURL = baseURL
+		return nil
+	}
+}
+
+// WithHTTPClient sets the HTTP client used for sending requests.
+// Apply WithTimeout after it to override the client's timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) error {
+		if hc == nil {
+			return errors.New("nil http client")
+		}
+		c.HTTPClient = hc
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout for a single HTTP request.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d <= 0 {
+			return fmt.Errorf("invalid timeout: %v", d)
+		}
+		hc := *c.HTTPClient
+		hc.Timeout = d
+		c.HTTPClient = &hc
+		return nil
+	}
+}
+
+// WithToken sets the premium webservice token.
+func WithToken(token string) Option {
+	return func(c *Client) error {
+		if token == "" {
+			return errors.New("empty token")
+		}
+		c.Token = token
+		return nil
+	}
+}
+
+// WithLanguage sets the ISO-639 language code used for
+// place names returned by the Web Service.
+func WithLanguage(lang string) Option {
+	return func(c *Client) error {
+		if lang == "" {
+			return errors.New("empty language")
+		}
+		c.Language = lang
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) error {
+		if ua == "" {
+			return errors.New("empty user agent")
+		}
+		c.UserAgent = ua
+		return nil
+	}
+}
+
+// WithCache sets the cache for storing successful responses.
+func WithCache(cache Cache) Option {
+	return func(c *Client) error {
+		if cache == nil {
+			return errors.New("nil cache")
+		}
+		c.Cache = cache
+		return nil
+	}
+}
+
+// WithRateLimit limits the client to n requests per the given period.
+func WithRateLimit(n int, per time.Duration) Option {
+	return func(c *Client) error {
+		if n < 1 || per <= 0 {
+			return fmt.Errorf("invalid rate limit: %d per %v", n, per)
+		}
+		c.Limiter = NewRateLimiter(n, per)
+		return nil
+	}
+}
+
+// WithRetry makes the client retry failed requests up to the given
+// number of attempts, waiting backoff before the first retry and
+// doubling the wait after each subsequent one.
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(c *Client) error {
+		if attempts < 1 || backoff < 0 {
+			return fmt.Errorf("invalid retry policy: %d attempts, %v backoff", attempts, backoff)
+		}
+		c.Retry = RetryPolicy{MaxAttempts: attempts, Backoff: backoff}
+		return nil
+	}
+}
@@ -0,0 +1,194 @@
+package geonames_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qba73/geonames"
+)
+
+func TestNew_ErrorsOnEmptyUsername(t *testing.T) {
+	t.Parallel()
+
+	_, err := geonames.New("")
+	if err == nil {
+		t.Fatal("want error on empty username")
+	}
+}
+
+func TestNew_ErrorsOnInvalidBaseURL(t *testing.T) {
+	t.Parallel()
+
+	for _, u := range []string{"", "api.geonames.org", "://bogus", "http://"} {
+		_, err := geonames.New("DummyUser", geonames.WithBaseURL(u))
+		if err == nil {
+			t.Errorf("want error on base url %q", u)
+		}
+	}
+}
+
+func TestNew_ErrorsOnInvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := []geonames.Option{
+		geonames.WithTimeout(0),
+		geonames.WithHTTPClient(nil),
+		geonames.WithToken(""),
+		geonames.WithLanguage(""),
+		geonames.WithUserAgent(""),
+		geonames.WithCache(nil),
+		geonames.WithObserver(nil),
+		geonames.WithRateLimit(0, time.Second),
+		geonames.WithRetry(0, time.Second),
+	}
+	for i, opt := range opts {
+		if _, err := geonames.New("DummyUser", opt); err == nil {
+			t.Errorf("option %d: want error", i)
+		}
+	}
+}
+
+func TestNew_AppliesOptions(t *testing.T) {
+	t.Parallel()
+
+	hc := &http.Client{}
+	c, err := geonames.New("DummyUser",
+		geonames.WithBaseURL("https://secure.geonames.net"),
+		geonames.WithHTTPClient(hc),
+		geonames.WithTimeout(3*time.Second),
+		geonames.WithToken("secret"),
+		geonames.WithLanguage("de"),
+		geonames.WithUserAgent("test-agent"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.BaseURL != "https://secure.geonames.net" {
+		t.Errorf("want base url https://secure.geonames.net, got %q", c.BaseURL)
+	}
+	if c.HTTPClient.Timeout != 3*time.Second {
+		t.Errorf("want timeout 3s, got %v", c.HTTPClient.Timeout)
+	}
+	if hc.Timeout != 0 {
+		t.Errorf("want caller's http client unchanged, got timeout %v", hc.Timeout)
+	}
+	if c.Token != "secret" || c.Language != "de" || c.UserAgent != "test-agent" {
+		t.Errorf("options not applied: %+v", c)
+	}
+}
+
+func TestClient_SendsTokenLanguageAndUserAgent(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		verifyURIs("/postalCodeSearchJSON?country=IE&placename=Castlebar&username=DummyUser&token=secret&lang=ga", r.RequestURI, t)
+		if got := r.UserAgent(); got != "test-agent" {
+			t.Errorf("want user agent test-agent, got %q", got)
+		}
+		rw.Write([]byte(`{"postalCodes":[]}`))
+	}))
+	defer ts.Close()
+
+	c, err := geonames.New("DummyUser",
+		geonames.WithBaseURL(ts.URL),
+		geonames.WithToken("secret"),
+		geonames.WithLanguage("ga"),
+		geonames.WithUserAgent("test-agent"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetPostCode(context.Background(), "Castlebar", "IE"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_RetriesOnServerError(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		rw.Write([]byte(`{"postalCodes":[]}`))
+	}))
+	defer ts.Close()
+
+	c, err := geonames.New("DummyUser",
+		geonames.WithBaseURL(ts.URL),
+		geonames.WithRetry(3, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetPostCode(context.Background(), "Castlebar", "IE"); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("want 3 calls, got %d", calls)
+	}
+}
+
+func TestClient_DoesNotRetryOnClientError(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	c, err := geonames.New("DummyUser",
+		geonames.WithBaseURL(ts.URL),
+		geonames.WithRetry(3, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetPostCode(context.Background(), "Castlebar", "IE"); err == nil {
+		t.Fatal("want error on 403 response")
+	}
+	if calls != 1 {
+		t.Errorf("want 1 call, got %d", calls)
+	}
+}
+
+func TestClient_RetriesAfterTemporaryAPIError(t *testing.T) {
+	t.Parallel()
+
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			rw.Write([]byte(`{"status":{"message":"timeout","value":13}}`))
+			return
+		}
+		rw.Write([]byte(`{"postalCodes":[{"placeName":"Castlebar","countryCode":"IE","postalCode":"F23"}]}`))
+	}))
+	defer ts.Close()
+
+	c, err := geonames.New("DummyUser",
+		geonames.WithBaseURL(ts.URL),
+		geonames.WithRetry(3, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.GetPostCode(context.Background(), "Castlebar", "IE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("want 2 calls, got %d", calls)
+	}
+	if len(got) != 1 || got[0].PlaceName != "Castlebar" {
+		t.Errorf("want Castlebar, got %+v", got)
+	}
+}
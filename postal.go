@@ -2,21 +2,34 @@ package geonames
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/url"
 )
 
 type postalResponse struct {
-	PostalCodes []struct {
-		AdminCode1  string  `json:"adminCode1"`
-		Lng         float64 `json:"lng"`
-		CountryCode string  `json:"countryCode"`
-		PostalCode  string  `json:"postalCode"`
-		AdminName1  string  `json:"adminName1"`
-		ISO31662    string  `json:"ISO3166-2"`
-		PlaceName   string  `json:"placeName"`
-		Lat         float64 `json:"lat"`
-	} `json:"postalCodes"`
+	apiStatus
+	PostalCodes []postalCodeResult `json:"postalCodes"`
+}
+
+type postalCodeResult struct {
+	AdminCode1  string  `json:"adminCode1"`
+	Lng         float64 `json:"lng"`
+	CountryCode string  `json:"countryCode"`
+	PostalCode  string  `json:"postalCode"`
+	AdminName1  string  `json:"adminName1"`
+	ISO31662    string  `json:"ISO3166-2"`
+	PlaceName   string  `json:"placeName"`
+	Lat         float64 `json:"lat"`
+}
+
+func (r *postalResponse) decodeField(dec *json.Decoder, key string) (bool, error) {
+	if key == "postalCodes" {
+		return true, decodeArray(dec, func(pc postalCodeResult) {
+			r.PostalCodes = append(r.PostalCodes, pc)
+		})
+	}
+	return r.decodeStatus(dec, key)
 }
 
 type PostalCode struct {
@@ -75,5 +88,9 @@ func (c Client) buildPostalURL(placeName, countryCode string) (string, error) {
 
 // GetPostalCode takes place and country and returns postal codes.
 func GetPostCode(place, country string) ([]PostalCode, error) {
-	return ClientFromEnv.GetPostCode(context.Background(), place, country)
+	c, err := DefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPostCode(context.Background(), place, country)
 }
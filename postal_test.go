@@ -13,7 +13,7 @@ func TestGetPostalCodes_ReturnsSingleValueOnValidInput(t *testing.T) {
 	t.Parallel()
 
 	ts := newTestServer(
-		"testdata/response-geoname-postal-single.json",
+		"geonamestest/fixtures/postal-single.json",
 		"/postalCodeSearchJSON?country=IE&placename=Castlebar&username=DummyUser",
 		t,
 	)
@@ -55,7 +55,7 @@ func TestGetPostalCodes_ReturnsMultipleValuesOnValidInput(t *testing.T) {
 	t.Parallel()
 
 	ts := newTestServer(
-		"testdata/response-geoname-postal-multiple.json",
+		"geonamestest/fixtures/postal-multiple.json",
 		"/postalCodeSearchJSON?country=IE&placename=Dublin&username=DummyUser",
 		t,
 	)
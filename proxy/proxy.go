@@ -0,0 +1,209 @@
+// Package proxy serves the GeoNames JSON Web Service paths, such as
+// /searchJSON, by forwarding requests through a single geonames.Client.
+//
+// Services that call the proxy instead of api.geonames.org share the
+// cache, rate limit and account of that client and never see its
+// credentials.
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/qba73/geonames"
+)
+
+// Endpoints lists the Web Service paths served by default.
+var Endpoints = []string{
+	"astergdemJSON",
+	"childrenJSON",
+	"countryCodeJSON",
+	"countryInfoJSON",
+	"countrySubdivisionJSON",
+	"findNearbyJSON",
+	"findNearbyPlaceNameJSON",
+	"findNearbyPostalCodesJSON",
+	"findNearbyWikipediaJSON",
+	"getJSON",
+	"gtopo30JSON",
+	"hierarchyJSON",
+	"neighboursJSON",
+	"postalCodeLookupJSON",
+	"postalCodeSearchJSON",
+	"searchJSON",
+	"siblingsJSON",
+	"srtm1JSON",
+	"srtm3JSON",
+	"timezoneJSON",
+	"wikipediaSearchJSON",
+}
+
+// CallerHeader is the request header naming the calling service.
+const CallerHeader = "X-Geonames-Caller"
+
+// CallerStats counts the requests of one caller.
+type CallerStats struct {
+	Requests int64 `json:"requests"`
+	// Errors counts requests that were not answered successfully.
+	Errors int64 `json:"errors"`
+	// CacheHits counts requests answered from the client cache.
+	CacheHits int64 `json:"cache_hits"`
+	// Credits counts requests forwarded to the Web Service.
+	Credits int64 `json:"credits"`
+}
+
+// Proxy is an http.Handler serving the GeoNames JSON API.
+type Proxy struct {
+	client    geonames.Client
+	endpoints map[string]bool
+	// Logger, if set, logs failed requests.
+	Logger *slog.Logger
+
+	mu    sync.Mutex
+	stats map[string]*CallerStats
+}
+
+// New returns a Proxy forwarding requests for the given endpoints
+// through c, or for Endpoints if none are given. The client's own
+// observer, if any, keeps being notified.
+func New(c *geonames.Client, endpoints ...string) *Proxy {
+	if len(endpoints) == 0 {
+		endpoints = Endpoints
+	}
+	p := &Proxy{
+		client:    *c,
+		endpoints: make(map[string]bool, len(endpoints)),
+		stats:     make(map[string]*CallerStats),
+	}
+	for _, e := range endpoints {
+		p.endpoints[e] = true
+	}
+	observer := geonames.Observer(geonames.ObserverFunc(p.observe))
+	if c.Observer != nil {
+		observer = geonames.MultiObserver(c.Observer, observer)
+	}
+	p.client.Observer = observer
+	return p
+}
+
+type callerKey struct{}
+
+// caller identifies the service sending r: by the CallerHeader, else
+// by the username it would have used with GeoNames, else by its address.
+func caller(r *http.Request) string {
+	if c := r.Header.Get(CallerHeader); c != "" {
+		return c
+	}
+	if u := r.URL.Query().Get("username"); u != "" {
+		return u
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func (p *Proxy) callerStats(name string) *CallerStats {
+	s, ok := p.stats[name]
+	if !ok {
+		s = new(CallerStats)
+		p.stats[name] = s
+	}
+	return s
+}
+
+func (p *Proxy) observe(ctx context.Context, info geonames.RequestInfo) {
+	name, ok := ctx.Value(callerKey{}).(string)
+	if !ok {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s := p.callerStats(name)
+	s.Credits += int64(info.Credits)
+	if info.CacheHit {
+		s.CacheHits++
+	}
+}
+
+// Stats returns a copy of the usage counters of each caller.
+func (p *Proxy) Stats() map[string]CallerStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	res := make(map[string]CallerStats, len(p.stats))
+	for name, s := range p.stats {
+		res[name] = *s
+	}
+	return res
+}
+
+// ServeHTTP forwards GET requests for the served endpoints.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := caller(r)
+	p.mu.Lock()
+	p.callerStats(name).Requests++
+	p.mu.Unlock()
+
+	status, err := p.serve(w, r, name)
+	if err == nil {
+		return
+	}
+	p.mu.Lock()
+	p.callerStats(name).Errors++
+	p.mu.Unlock()
+	if p.Logger != nil {
+		p.Logger.Error("geonames proxy request", slog.String("caller", name), slog.String("path", r.URL.Path), slog.Any("error", err))
+	}
+	if status != 0 {
+		http.Error(w, http.StatusText(status), status)
+	}
+}
+
+// serve answers r. On failure it returns the error and the HTTP status
+// still to be written, which is zero if a response has already been written.
+func (p *Proxy) serve(w http.ResponseWriter, r *http.Request, name string) (int, error) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		return http.StatusMethodNotAllowed, errors.New("method not allowed")
+	}
+	endpoint := strings.TrimPrefix(r.URL.Path, "/")
+	if !p.endpoints[endpoint] {
+		return http.StatusNotFound, errors.New("unknown endpoint")
+	}
+
+	params := r.URL.Query()
+	params.Del("username")
+	params.Del("token")
+	ctx := context.WithValue(r.Context(), callerKey{}, name)
+	body, err := p.client.GetRaw(ctx, endpoint, params)
+
+	var ae *geonames.APIError
+	var he *geonames.HTTPError
+	switch {
+	case err == nil:
+	case errors.As(err, &ae):
+		// GeoNames reports these errors in the body of a 200 response.
+		body, _ = json.Marshal(map[string]*geonames.APIError{"status": ae})
+	case errors.As(err, &he):
+		return he.StatusCode, err
+	case errors.Is(err, context.Canceled):
+		return 0, err
+	default:
+		var ue *url.Error
+		if errors.As(err, &ue) || errors.Is(err, geonames.ErrResponseTooLarge) {
+			return http.StatusBadGateway, err
+		}
+		return http.StatusServiceUnavailable, err
+	}
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	w.Write(body)
+	return 0, err
+}
@@ -0,0 +1,196 @@
+package proxy_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/proxy"
+)
+
+// upstream is a test helper standing in for the GeoNames Web Service.
+// It records the query of every request it receives.
+type upstream struct {
+	mu      sync.Mutex
+	queries []string
+	body    string
+	status  int
+}
+
+func (u *upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.mu.Lock()
+	u.queries = append(u.queries, r.URL.Path+"?"+r.URL.RawQuery)
+	u.mu.Unlock()
+	if u.status != 0 {
+		w.WriteHeader(u.status)
+	}
+	fmt.Fprint(w, u.body)
+}
+
+func newProxy(t *testing.T, up *upstream, opts ...geonames.Option) (*proxy.Proxy, *httptest.Server) {
+	t.Helper()
+	upstreamServer := httptest.NewServer(up)
+	t.Cleanup(upstreamServer.Close)
+	opts = append([]geonames.Option{geonames.WithBaseURL(upstreamServer.URL), geonames.WithToken("ServerToken")}, opts...)
+	c, err := geonames.New("ServerUser", opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := proxy.New(c)
+	ts := httptest.NewServer(p)
+	t.Cleanup(ts.Close)
+	return p, ts
+}
+
+func get(t *testing.T, url string, header ...string) (int, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, string(body)
+}
+
+func TestProxy_ForwardsRequestsWithServerCredentials(t *testing.T) {
+	t.Parallel()
+
+	up := &upstream{body: `{"postalCodes":[{"postalCode":"F23","placeName":"Castlebar"}]}`}
+	p, ts := newProxy(t, up)
+
+	status, body := get(t, ts.URL+"/postalCodeSearchJSON?placename=Castlebar&country=IE&username=billing&token=stolen")
+	if status != http.StatusOK {
+		t.Fatalf("want status 200, got %d: %s", status, body)
+	}
+	if body != up.body {
+		t.Errorf("want upstream body %s, got %s", up.body, body)
+	}
+	want := []string{"/postalCodeSearchJSON?country=IE&placename=Castlebar&token=ServerToken&username=ServerUser"}
+	if !cmp.Equal(want, up.queries) {
+		t.Error(cmp.Diff(want, up.queries))
+	}
+	wantStats := map[string]proxy.CallerStats{"billing": {Requests: 1, Credits: 1}}
+	if got := p.Stats(); !cmp.Equal(wantStats, got) {
+		t.Error(cmp.Diff(wantStats, got))
+	}
+}
+
+func TestProxy_SharesClientCacheBetweenCallers(t *testing.T) {
+	t.Parallel()
+
+	up := &upstream{body: `{"srtm3":41,"lng":-9.29778,"lat":53.85583}`}
+	p, ts := newProxy(t, up, geonames.WithCache(geonames.NewMemoryCache(0)))
+
+	get(t, ts.URL+"/srtm3JSON?lat=53.85583&lng=-9.29778", proxy.CallerHeader, "maps")
+	_, body := get(t, ts.URL+"/srtm3JSON?lng=-9.29778&lat=53.85583", proxy.CallerHeader, "search")
+	if body != up.body {
+		t.Errorf("want cached body %s, got %s", up.body, body)
+	}
+	if len(up.queries) != 1 {
+		t.Errorf("want one upstream request, got %d", len(up.queries))
+	}
+	want := map[string]proxy.CallerStats{
+		"maps":   {Requests: 1, Credits: 1},
+		"search": {Requests: 1, CacheHits: 1},
+	}
+	if got := p.Stats(); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestProxy_PassesAPIErrorsThroughInStatusEnvelope(t *testing.T) {
+	t.Parallel()
+
+	up := &upstream{body: `{"status":{"message":"the hourly limit of 1000 credits has been exceeded","value":19}}`}
+	p, ts := newProxy(t, up)
+
+	status, body := get(t, ts.URL+"/searchJSON?q=Castlebar", proxy.CallerHeader, "maps")
+	if status != http.StatusOK {
+		t.Errorf("want status 200 like GeoNames, got %d", status)
+	}
+	want := `{"status":{"value":19,"message":"the hourly limit of 1000 credits has been exceeded"}}`
+	if body != want {
+		t.Errorf("want %s, got %s", want, body)
+	}
+	if got := p.Stats()["maps"]; got.Errors != 1 {
+		t.Errorf("want 1 error counted, got %+v", got)
+	}
+}
+
+func TestProxy_ReturnsUpstreamHTTPStatus(t *testing.T) {
+	t.Parallel()
+
+	_, ts := newProxy(t, &upstream{status: http.StatusServiceUnavailable, body: "down"})
+	if status, _ := get(t, ts.URL+"/searchJSON?q=Castlebar"); status != http.StatusServiceUnavailable {
+		t.Errorf("want status 503, got %d", status)
+	}
+}
+
+func TestProxy_RejectsUnknownEndpointsAndMethods(t *testing.T) {
+	t.Parallel()
+
+	up := &upstream{}
+	_, ts := newProxy(t, up)
+
+	if status, _ := get(t, ts.URL+"/search?q=Castlebar"); status != http.StatusNotFound {
+		t.Errorf("want status 404 for unknown endpoint, got %d", status)
+	}
+	res, err := http.Post(ts.URL+"/searchJSON", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("want status 405 for POST, got %d", res.StatusCode)
+	}
+	if len(up.queries) != 0 {
+		t.Errorf("want no upstream requests, got %v", up.queries)
+	}
+}
+
+func TestProxy_LogsUnreachableUpstreamWithoutCredentials(t *testing.T) {
+	t.Parallel()
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	c, err := geonames.New("ServerUser", geonames.WithBaseURL(down.URL), geonames.WithToken("ServerToken"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	p := proxy.New(c)
+	p.Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	ts := httptest.NewServer(p)
+	defer ts.Close()
+
+	if status, _ := get(t, ts.URL+"/searchJSON?q=Castlebar"); status != http.StatusBadGateway {
+		t.Errorf("want status 502, got %d", status)
+	}
+	if !strings.Contains(buf.String(), "REDACTED") {
+		t.Errorf("want redacted upstream URL in log, got %s", buf.String())
+	}
+	for _, secret := range []string{"ServerUser", "ServerToken"} {
+		if strings.Contains(buf.String(), secret) {
+			t.Errorf("want %s hidden, got log %s", secret, buf.String())
+		}
+	}
+}
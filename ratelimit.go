@@ -0,0 +1,80 @@
+package geonames
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Limiter controls the rate of outgoing requests.
+type Limiter interface {
+	// Wait blocks until a request may be sent or ctx is done.
+	Wait(ctx context.Context) error
+}
+
+// RateLimiter spaces requests evenly so that no more than
+// n requests are sent per period.
+type RateLimiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+	// freed holds future slots, in order, given back by waits
+	// that were cancelled while later slots were reserved.
+	freed []time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing n requests per period.
+func NewRateLimiter(n int, per time.Duration) *RateLimiter {
+	return &RateLimiter{interval: per / time.Duration(n)}
+}
+
+// Wait blocks until the next request slot is available. If ctx is
+// done first, the slot is given back for later callers.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	slot := l.reserve(time.Now())
+	d := time.Until(slot)
+	if d <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, d); err != nil {
+		l.release(slot)
+		return err
+	}
+	return nil
+}
+
+// reserve returns the earliest free slot not before now.
+func (l *RateLimiter) reserve(now time.Time) time.Time {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for len(l.freed) > 0 && l.freed[0].Before(now) {
+		l.freed = l.freed[1:]
+	}
+	if len(l.freed) > 0 {
+		slot := l.freed[0]
+		l.freed = l.freed[1:]
+		return slot
+	}
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	return slot
+}
+
+// release gives back a reserved slot that was not used.
+func (l *RateLimiter) release(slot time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.next.Equal(slot.Add(l.interval)) {
+		l.next = slot
+		return
+	}
+	i, _ := slices.BinarySearchFunc(l.freed, slot, time.Time.Compare)
+	l.freed = slices.Insert(l.freed, i, slot)
+}
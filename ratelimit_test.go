@@ -0,0 +1,97 @@
+package geonames_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/qba73/geonames"
+)
+
+func TestRateLimiter_SpacesRequests(t *testing.T) {
+	t.Parallel()
+
+	l := geonames.NewRateLimiter(10, 100*time.Millisecond)
+	start := time.Now()
+	for range 4 {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("want at least 30ms for 4 requests, got %v", elapsed)
+	}
+}
+
+func TestRateLimiter_StopsWaitingOnCancelledContext(t *testing.T) {
+	t.Parallel()
+
+	l := geonames.NewRateLimiter(1, time.Hour)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Error("want error on cancelled context")
+	}
+}
+
+func TestRateLimiter_GivesBackSlotOfCancelledWait(t *testing.T) {
+	t.Parallel()
+
+	l := geonames.NewRateLimiter(1, 100*time.Millisecond)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		err := l.Wait(ctx)
+		cancel()
+		if err == nil {
+			t.Fatal("want error on cancelled wait")
+		}
+	}
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("want next wait under one interval after cancelled waits, took %v", elapsed)
+	}
+}
+
+func TestRateLimiter_ReusesSlotFreedBehindOtherWaiters(t *testing.T) {
+	t.Parallel()
+
+	l := geonames.NewRateLimiter(1, 50*time.Millisecond)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// The cancelled wait holds the first slot while another
+	// caller reserves the second one.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() { cancelled <- l.Wait(ctx) }()
+	time.Sleep(5 * time.Millisecond)
+	second := make(chan time.Duration)
+	go func() {
+		start := time.Now()
+		l.Wait(context.Background())
+		second <- time.Since(start)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	cancel()
+	if err := <-cancelled; err == nil {
+		t.Fatal("want error on cancelled wait")
+	}
+
+	start := time.Now()
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 70*time.Millisecond {
+		t.Errorf("want the freed first slot reused, waited %v", elapsed)
+	}
+	<-second
+}
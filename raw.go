@@ -0,0 +1,48 @@
+package geonames
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// rawResponse keeps a response body as it was received.
+type rawResponse struct {
+	json.RawMessage
+}
+
+// apiError returns the error carried in the status envelope, if any.
+func (r *rawResponse) apiError() *APIError {
+	if !strings.Contains(string(r.RawMessage), `"status"`) {
+		return nil
+	}
+	var s apiStatus
+	if json.Unmarshal(r.RawMessage, &s) != nil {
+		return nil
+	}
+	return s.Status
+}
+
+// GetRaw calls the Web Service endpoint with the given name, such as
+// "searchJSON", and returns the JSON response body unchanged. The
+// username and token of the client replace any given in params.
+//
+// Like the typed methods, GetRaw goes through the cache, rate limiter,
+// retry policy and observer of the client, and returns an *APIError
+// for a response carrying an error status.
+func (c Client) GetRaw(ctx context.Context, endpoint string, params url.Values) ([]byte, error) {
+	q := make(url.Values, len(params)+1)
+	for k, v := range params {
+		q[k] = v
+	}
+	q.Del("token")
+	q.Set("username", c.UserName)
+
+	var raw rawResponse
+	if err := c.get(ctx, fmt.Sprintf("%s/%s?%s", c.BaseURL, endpoint, q.Encode()), &raw); err != nil {
+		return nil, err
+	}
+	return raw.RawMessage, nil
+}
@@ -0,0 +1,78 @@
+package geonames_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+)
+
+func TestGetRaw_ReturnsBodyAndReplacesCredentials(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(
+		"geonamestest/fixtures/search.json",
+		"/searchJSON?q=Castlebar&maxRows=3&username=ProxyUser&token=ProxyToken",
+		t,
+	)
+	defer ts.Close()
+
+	client, err := geonames.New("ProxyUser", geonames.WithBaseURL(ts.URL), geonames.WithToken("ProxyToken"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := url.Values{
+		"q":        {"Castlebar"},
+		"maxRows":  {"3"},
+		"username": {"CallerUser"},
+		"token":    {"CallerToken"},
+	}
+	got, err := client.GetRaw(context.Background(), "searchJSON", params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := os.ReadFile("geonamestest/fixtures/search.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(strings.TrimSpace(string(want)), string(got)) {
+		t.Error(cmp.Diff(strings.TrimSpace(string(want)), string(got)))
+	}
+	if params.Get("username") != "CallerUser" {
+		t.Error("want params of the caller left unchanged")
+	}
+}
+
+func TestGetRaw_ReturnsAPIErrorAndDoesNotCacheIt(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"status":{"message":"the hourly limit of 1000 credits has been exceeded","value":19}}`)
+	}))
+	defer ts.Close()
+
+	client, err := geonames.New("DummyUser", geonames.WithBaseURL(ts.URL), geonames.WithCache(geonames.NewMemoryCache(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		_, err := client.GetRaw(context.Background(), "searchJSON", url.Values{"q": {"Castlebar"}})
+		var ae *geonames.APIError
+		if !errors.As(err, &ae) || ae.Code != geonames.CodeHourlyLimitExceeded {
+			t.Fatalf("want hourly limit APIError, got %v", err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("want error responses not cached, got %d calls", calls)
+	}
+}
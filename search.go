@@ -0,0 +1,231 @@
+package geonames
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"strconv"
+)
+
+// MaxSearchRows is the number of results GeoNames makes available
+// for a single search, however many pages are requested.
+const MaxSearchRows = 5000
+
+// maxPageRows is the largest page size accepted by the search service.
+const maxPageRows = 1000
+
+type searchResponse struct {
+	apiStatus
+	TotalResultsCount int           `json:"totalResultsCount"`
+	Geonames          []placeResult `json:"geonames"`
+}
+
+type placeResult struct {
+	GeoNameID   int     `json:"geonameId"`
+	Name        string  `json:"name"`
+	ToponymName string  `json:"toponymName"`
+	Lat         float64 `json:"lat,string"`
+	Lng         float64 `json:"lng,string"`
+	CountryCode string  `json:"countryCode"`
+	CountryName string  `json:"countryName"`
+	AdminCode1  string  `json:"adminCode1"`
+	AdminName1  string  `json:"adminName1"`
+	Fcl         string  `json:"fcl"`
+	FclName     string  `json:"fclName"`
+	Fcode       string  `json:"fcode"`
+	FcodeName   string  `json:"fcodeName"`
+	Population  int     `json:"population"`
+	Distance    float64 `json:"distance,string,omitempty"`
+}
+
+func (r *searchResponse) decodeField(dec *json.Decoder, key string) (bool, error) {
+	switch key {
+	case "totalResultsCount":
+		return true, dec.Decode(&r.TotalResultsCount)
+	case "geonames":
+		return true, decodeArray(dec, func(p placeResult) {
+			r.Geonames = append(r.Geonames, p)
+		})
+	}
+	return r.decodeStatus(dec, key)
+}
+
+// Place represents a GeoNames toponym returned by the search services.
+type Place struct {
+	GeoNameID        int
+	Name             string
+	ToponymName      string
+	Position         Position
+	CountryCode      string
+	CountryName      string
+	AdminCode1       string
+	AdminName1       string
+	FeatureClass     string
+	FeatureClassName string
+	FeatureCode      string
+	FeatureCodeName  string
+	Population       int
+	// Distance is the distance in kilometers from the queried
+	// position. It is only set by the nearby services.
+	Distance float64
+}
+
+func (p placeResult) place() Place {
+	return Place{
+		GeoNameID:   p.GeoNameID,
+		Name:        p.Name,
+		ToponymName: p.ToponymName,
+		Position: Position{
+			Lat: p.Lat,
+			Lng: p.Lng,
+		},
+		CountryCode:      p.CountryCode,
+		CountryName:      p.CountryName,
+		AdminCode1:       p.AdminCode1,
+		AdminName1:       p.AdminName1,
+		FeatureClass:     p.Fcl,
+		FeatureClassName: p.FclName,
+		FeatureCode:      p.Fcode,
+		FeatureCodeName:  p.FcodeName,
+		Population:       p.Population,
+		Distance:         p.Distance,
+	}
+}
+
+// SearchQuery holds the parameters of a full text search.
+// Empty fields are not sent.
+type SearchQuery struct {
+	// Q searches over all attributes of a place.
+	Q string
+	// Name searches the place name only.
+	Name string
+	// NameEquals matches the exact place name.
+	NameEquals string
+	// NameStartsWith matches the beginning of the place name.
+	NameStartsWith string
+	// Country restricts results to the given ISO-3166 country codes.
+	Country []string
+	// AdminCode1 restricts results to a first-order administrative division.
+	AdminCode1 string
+	// FeatureClass restricts results to the given feature classes, e.g. "P".
+	FeatureClass []string
+	// FeatureCode restricts results to the given feature codes, e.g. "PPLC".
+	FeatureCode []string
+	// Fuzzy, between 0 and 1, enables fuzzy matching. Lower is fuzzier.
+	Fuzzy float64
+	// OrderBy is one of "population", "elevation" or "relevance".
+	OrderBy string
+	// MaxRows is the page size. GeoNames defaults to 100 and accepts up to 1000.
+	MaxRows int
+	// StartRow is the zero-based index of the first result.
+	StartRow int
+}
+
+// SearchResult is a single page of search results.
+type SearchResult struct {
+	// TotalResultsCount is the number of places matching the query,
+	// which may be more than can be paged through.
+	TotalResultsCount int
+	Places            []Place
+}
+
+// Search returns a single page of places matching the query.
+func (c Client) Search(ctx context.Context, q SearchQuery) (SearchResult, error) {
+	u, err := c.buildSearchURL(q)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	var sr searchResponse
+	if err := c.get(ctx, u, &sr); err != nil {
+		return SearchResult{}, err
+	}
+	res := SearchResult{TotalResultsCount: sr.TotalResultsCount}
+	for _, p := range sr.Geonames {
+		res.Places = append(res.Places, p.place())
+	}
+	return res, nil
+}
+
+// SearchAll returns an iterator over all places matching the query.
+//
+// Pages of q.MaxRows results are fetched as the iteration proceeds,
+// starting at q.StartRow. Iteration stops after maxResults places,
+// or at the GeoNames limit of MaxSearchRows if maxResults is zero or
+// larger. An error is yielded once and ends the iteration.
+func (c Client) SearchAll(ctx context.Context, q SearchQuery, maxResults int) iter.Seq2[Place, error] {
+	return func(yield func(Place, error) bool) {
+		end := MaxSearchRows
+		if maxResults > 0 {
+			end = min(end, q.StartRow+maxResults)
+		}
+		pageSize := q.MaxRows
+		if pageSize <= 0 {
+			pageSize = 100
+		}
+		pageSize = min(pageSize, maxPageRows)
+
+		for start := q.StartRow; start < end; {
+			page := q
+			page.StartRow = start
+			page.MaxRows = min(pageSize, end-start)
+			res, err := c.Search(ctx, page)
+			if err != nil {
+				yield(Place{}, err)
+				return
+			}
+			for _, p := range res.Places {
+				if !yield(p, nil) {
+					return
+				}
+			}
+			start += len(res.Places)
+			if len(res.Places) < page.MaxRows || start >= res.TotalResultsCount {
+				return
+			}
+		}
+	}
+}
+
+func (c Client) buildSearchURL(q SearchQuery) (string, error) {
+	params := url.Values{
+		"username": {c.UserName},
+	}
+	set := func(key, value string) {
+		if value != "" {
+			params.Set(key, value)
+		}
+	}
+	set("q", q.Q)
+	set("name", q.Name)
+	set("name_equals", q.NameEquals)
+	set("name_startsWith", q.NameStartsWith)
+	set("adminCode1", q.AdminCode1)
+	set("orderby", q.OrderBy)
+	for _, cc := range q.Country {
+		params.Add("country", cc)
+	}
+	for _, fc := range q.FeatureClass {
+		params.Add("featureClass", fc)
+	}
+	for _, fc := range q.FeatureCode {
+		params.Add("featureCode", fc)
+	}
+	if q.Fuzzy > 0 {
+		params.Set("fuzzy", strconv.FormatFloat(q.Fuzzy, 'f', -1, 64))
+	}
+	if q.MaxRows > 0 {
+		params.Set("maxRows", strconv.Itoa(q.MaxRows))
+	}
+	if q.StartRow > 0 {
+		params.Set("startRow", strconv.Itoa(q.StartRow))
+	}
+
+	u, err := url.Parse(fmt.Sprintf("%s/searchJSON", c.BaseURL))
+	if err != nil {
+		return "", fmt.Errorf("parsing search base url: %w", err)
+	}
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
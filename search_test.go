@@ -0,0 +1,192 @@
+package geonames_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+)
+
+func TestSearch_ReturnsPlacesAndTotalCountOnValidInput(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(
+		"geonamestest/fixtures/search.json",
+		"/searchJSON?q=Castlebar&country=IE&featureClass=P&featureClass=H&maxRows=3&username=DummyUser",
+		t,
+	)
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	got, err := client.Search(context.Background(), geonames.SearchQuery{
+		Q:            "Castlebar",
+		Country:      []string{"IE"},
+		FeatureClass: []string{"P", "H"},
+		MaxRows:      3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.TotalResultsCount != 3 {
+		t.Errorf("want total 3, got %d", got.TotalResultsCount)
+	}
+	if len(got.Places) != 3 {
+		t.Fatalf("want 3 places, got %d", len(got.Places))
+	}
+	want := geonames.Place{
+		GeoNameID:        2965654,
+		Name:             "Castlebar",
+		ToponymName:      "Castlebar",
+		Position:         geonames.Position{Lat: 53.85583, Lng: -9.29778},
+		CountryCode:      "IE",
+		CountryName:      "Ireland",
+		AdminCode1:       "C",
+		AdminName1:       "Connacht",
+		FeatureClass:     "P",
+		FeatureClassName: "city, village,...",
+		FeatureCode:      "PPLA2",
+		FeatureCodeName:  "seat of a second-order administrative division",
+		Population:       12068,
+	}
+	if !cmp.Equal(want, got.Places[0]) {
+		t.Error(cmp.Diff(want, got.Places[0]))
+	}
+}
+
+// newPagingServer serves total generated places,
+// honouring the startRow and maxRows parameters.
+func newPagingServer(total int, requests *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.URL.RawQuery)
+		start, _ := strconv.Atoi(r.URL.Query().Get("startRow"))
+		rows, _ := strconv.Atoi(r.URL.Query().Get("maxRows"))
+		var places []string
+		for i := start; i < min(start+rows, total); i++ {
+			places = append(places, fmt.Sprintf(`{"geonameId":%d,"name":"Place %d","lat":"1.5","lng":"2.5"}`, i+1, i+1))
+		}
+		fmt.Fprintf(rw, `{"totalResultsCount":%d,"geonames":[%s]}`, total, strings.Join(places, ","))
+	}))
+}
+
+func TestSearchAll_FetchesPagesUntilResultsRunOut(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	ts := newPagingServer(25, &requests)
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	var ids []int
+	for p, err := range client.SearchAll(context.Background(), geonames.SearchQuery{Q: "x", MaxRows: 10}, 0) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, p.GeoNameID)
+	}
+	if len(ids) != 25 || ids[0] != 1 || ids[24] != 25 {
+		t.Errorf("want places 1 to 25, got %v", ids)
+	}
+	if len(requests) != 3 {
+		t.Errorf("want 3 page requests, got %d: %v", len(requests), requests)
+	}
+}
+
+func TestSearchAll_StopsAtMaxResults(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	ts := newPagingServer(100, &requests)
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	var n int
+	for _, err := range client.SearchAll(context.Background(), geonames.SearchQuery{Q: "x", MaxRows: 10}, 15) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != 15 {
+		t.Errorf("want 15 places, got %d", n)
+	}
+	if len(requests) != 2 || !strings.Contains(requests[1], "maxRows=5") {
+		t.Errorf("want second page of 5 rows, got %v", requests)
+	}
+}
+
+func TestSearchAll_StopsAtGeoNamesRowLimit(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	ts := newPagingServer(20000, &requests)
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	var n int
+	for _, err := range client.SearchAll(context.Background(), geonames.SearchQuery{Q: "x", MaxRows: 1000}, 0) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		n++
+	}
+	if n != geonames.MaxSearchRows {
+		t.Errorf("want %d places, got %d", geonames.MaxSearchRows, n)
+	}
+}
+
+func TestSearchAll_FetchesNothingUntilIterated(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	ts := newPagingServer(100, &requests)
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	for range client.SearchAll(context.Background(), geonames.SearchQuery{Q: "x", MaxRows: 10}, 0) {
+		break
+	}
+	if len(requests) != 1 {
+		t.Errorf("want 1 request after breaking out early, got %d", len(requests))
+	}
+}
+
+func TestSearchAll_YieldsErrorAndStops(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		rw.Write([]byte(`{"status":{"message":"invalid parameter","value":14}}`))
+	}))
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	var errs int
+	for _, err := range client.SearchAll(context.Background(), geonames.SearchQuery{Q: "x"}, 0) {
+		var ae *geonames.APIError
+		if !errors.As(err, &ae) {
+			t.Fatalf("want *APIError, got %v", err)
+		}
+		errs++
+	}
+	if errs != 1 {
+		t.Errorf("want one error, got %d", errs)
+	}
+}
@@ -0,0 +1,496 @@
+// Package server serves a subset of the GeoNames JSON Web Service from
+// dump files loaded in memory, for environments that cannot reach
+// api.geonames.org.
+//
+// Responses follow the wire format of the Web Service closely enough
+// that a geonames.Client pointed at the server with WithBaseURL works
+// unchanged, including errors reported in the status envelope.
+package server
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/gazetteer"
+)
+
+// defaultPostalRows is the number of postal codes
+// returned when a query does not set maxRows.
+const defaultPostalRows = 10
+
+// Server is an http.Handler answering Web Service requests from Data.
+// Endpoints whose index is missing report CodeServiceNotImplemented.
+type Server struct {
+	// Data holds the indexes. The hierarchyJSON endpoint follows the
+	// Parents of Data.Tables; places without a parent link get their
+	// hierarchy from their country and admin codes.
+	Data *gazetteer.Offline
+	// Logger, if set, logs requests that fail with an error.
+	Logger *slog.Logger
+}
+
+// ServeHTTP answers GET requests for the served endpoints.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	var serve func(context.Context, url.Values) (any, error)
+	switch strings.TrimPrefix(r.URL.Path, "/") {
+	case "searchJSON":
+		serve = s.search
+	case "getJSON":
+		serve = s.get
+	case "hierarchyJSON":
+		serve = s.hierarchy
+	case "findNearbyJSON":
+		serve = s.findNearby
+	case "findNearbyPlaceNameJSON":
+		serve = s.findNearbyPlaceName
+	case "postalCodeSearchJSON":
+		serve = s.postalCodeSearch
+	case "countryInfoJSON":
+		serve = s.countryInfo
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	res, err := serve(r.Context(), r.URL.Query())
+	if err != nil {
+		ae, ok := err.(*geonames.APIError)
+		if !ok {
+			ae = &geonames.APIError{Code: geonames.CodeOtherError, Message: err.Error()}
+		}
+		if s.Logger != nil {
+			s.Logger.Error("geonames server request", slog.String("path", r.URL.Path), slog.Any("error", err))
+		}
+		// GeoNames reports these errors in the body of a 200 response.
+		res = map[string]*geonames.APIError{"status": ae}
+	}
+	w.Header().Set("Content-Type", "application/json;charset=UTF-8")
+	json.NewEncoder(w).Encode(res)
+}
+
+func invalidParameter(format string, args ...any) error {
+	return &geonames.APIError{Code: geonames.CodeInvalidParameter, Message: fmt.Sprintf(format, args...)}
+}
+
+func notImplemented(index string) error {
+	return &geonames.APIError{Code: geonames.CodeServiceNotImplemented, Message: "no " + index + " loaded"}
+}
+
+// intParam parses the integer parameter key, returning def if it is absent.
+func intParam(params url.Values, key string, def int) (int, error) {
+	v := params.Get(key)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, invalidParameter("invalid %s: %q", key, v)
+	}
+	return n, nil
+}
+
+// floatParam parses the number parameter key, returning def if it is absent.
+func floatParam(params url.Values, key string, def float64) (float64, error) {
+	v := params.Get(key)
+	if v == "" {
+		return def, nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, invalidParameter("invalid %s: %q", key, v)
+	}
+	return f, nil
+}
+
+func idParam(params url.Values) (int64, error) {
+	v := params.Get("geonameId")
+	if v == "" {
+		return 0, invalidParameter("missing parameter geonameId")
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, invalidParameter("invalid geonameId: %q", v)
+	}
+	return id, nil
+}
+
+func positionParams(params url.Values) (geonames.Position, error) {
+	if params.Get("lat") == "" || params.Get("lng") == "" {
+		return geonames.Position{}, invalidParameter("missing parameter lat or lng")
+	}
+	lat, err := floatParam(params, "lat", 0)
+	if err != nil {
+		return geonames.Position{}, err
+	}
+	lng, err := floatParam(params, "lng", 0)
+	if err != nil {
+		return geonames.Position{}, err
+	}
+	pos := geonames.Position{Lat: lat, Lng: lng}
+	if err := pos.Validate(); err != nil {
+		return geonames.Position{}, invalidParameter("%v", err)
+	}
+	return pos, nil
+}
+
+// place is a toponym as encoded by the search services.
+type place struct {
+	GeoNameID   int     `json:"geonameId"`
+	Name        string  `json:"name"`
+	ToponymName string  `json:"toponymName"`
+	Lat         float64 `json:"lat,string"`
+	Lng         float64 `json:"lng,string"`
+	CountryCode string  `json:"countryCode"`
+	CountryName string  `json:"countryName"`
+	AdminCode1  string  `json:"adminCode1"`
+	AdminName1  string  `json:"adminName1"`
+	Fcl         string  `json:"fcl"`
+	FclName     string  `json:"fclName"`
+	Fcode       string  `json:"fcode"`
+	FcodeName   string  `json:"fcodeName"`
+	Population  int     `json:"population"`
+	Distance    string  `json:"distance,omitempty"`
+}
+
+func newPlace(p geonames.Place) place {
+	res := place{
+		GeoNameID:   p.GeoNameID,
+		Name:        p.Name,
+		ToponymName: p.ToponymName,
+		Lat:         p.Position.Lat,
+		Lng:         p.Position.Lng,
+		CountryCode: p.CountryCode,
+		CountryName: p.CountryName,
+		AdminCode1:  p.AdminCode1,
+		AdminName1:  p.AdminName1,
+		Fcl:         p.FeatureClass,
+		FclName:     p.FeatureClassName,
+		Fcode:       p.FeatureCode,
+		FcodeName:   p.FeatureCodeName,
+		Population:  p.Population,
+	}
+	if p.Distance > 0 {
+		res.Distance = strconv.FormatFloat(p.Distance, 'f', 5, 64)
+	}
+	return res
+}
+
+func newPlaces(ps []geonames.Place) []place {
+	res := make([]place, 0, len(ps))
+	for _, p := range ps {
+		res = append(res, newPlace(p))
+	}
+	return res
+}
+
+type placesResponse struct {
+	TotalResultsCount *int    `json:"totalResultsCount,omitempty"`
+	Geonames          []place `json:"geonames"`
+}
+
+func (s *Server) search(ctx context.Context, params url.Values) (any, error) {
+	if s.Data.Names == nil {
+		return nil, notImplemented("name index")
+	}
+	q := geonames.SearchQuery{
+		Q:              params.Get("q"),
+		Name:           params.Get("name"),
+		NameEquals:     params.Get("name_equals"),
+		NameStartsWith: params.Get("name_startsWith"),
+		Country:        params["country"],
+		AdminCode1:     params.Get("adminCode1"),
+		FeatureClass:   params["featureClass"],
+		FeatureCode:    params["featureCode"],
+		OrderBy:        params.Get("orderby"),
+	}
+	if cmp.Or(q.Q, q.Name, q.NameEquals, q.NameStartsWith) == "" {
+		return nil, invalidParameter("missing parameter q, name, name_equals or name_startsWith")
+	}
+	var err error
+	if q.Fuzzy, err = floatParam(params, "fuzzy", 0); err != nil {
+		return nil, err
+	}
+	if q.MaxRows, err = intParam(params, "maxRows", 100); err != nil {
+		return nil, err
+	}
+	if q.MaxRows > 1000 {
+		return nil, &geonames.APIError{Code: geonames.CodeMaxRowsTooLarge, Message: "maxRows may not exceed 1000"}
+	}
+	if q.StartRow, err = intParam(params, "startRow", 0); err != nil {
+		return nil, err
+	}
+	res, err := s.Data.Search(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return placesResponse{TotalResultsCount: &res.TotalResultsCount, Geonames: newPlaces(res.Places)}, nil
+}
+
+// fullPlace is a toponym as encoded by getJSON.
+type fullPlace struct {
+	place
+	ASCIIName      string          `json:"asciiName"`
+	AdminCode2     string          `json:"adminCode2,omitempty"`
+	AdminName2     string          `json:"adminName2,omitempty"`
+	Elevation      int             `json:"elevation,omitempty"`
+	SRTM3          int             `json:"srtm3"`
+	Timezone       *timezone       `json:"timezone,omitempty"`
+	AlternateNames []alternateName `json:"alternateNames,omitempty"`
+}
+
+type timezone struct {
+	TimeZoneID string  `json:"timeZoneId"`
+	GMTOffset  float64 `json:"gmtOffset"`
+	DSTOffset  float64 `json:"dstOffset"`
+}
+
+type alternateName struct {
+	Name      string `json:"name"`
+	Lang      string `json:"lang,omitempty"`
+	Preferred bool   `json:"isPreferredName,omitempty"`
+}
+
+func (s *Server) get(_ context.Context, params url.Values) (any, error) {
+	if s.Data.Names == nil {
+		return nil, notImplemented("name index")
+	}
+	id, err := idParam(params)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := s.Data.Names.Place(id)
+	if !ok {
+		return nil, &geonames.APIError{Code: geonames.CodeRecordDoesNotExist, Message: fmt.Sprintf("the geoname feature does not exist (id %d)", id)}
+	}
+	gp, _ := s.Data.Place(id)
+	res := fullPlace{
+		place:      newPlace(gp),
+		ASCIIName:  p.ASCIIName,
+		AdminCode2: p.Admin2Code,
+		Elevation:  p.Elevation,
+		SRTM3:      p.DEM,
+	}
+	if p.Timezone != "" {
+		res.Timezone = &timezone{TimeZoneID: p.Timezone}
+	}
+	if t := s.Data.Tables; t != nil {
+		res.AdminName2 = t.Resolve(p).Admin2Name
+		if tz, ok := t.TimeZones[p.Timezone]; ok && res.Timezone != nil {
+			res.Timezone.GMTOffset = tz.GMTOffset
+			res.Timezone.DSTOffset = tz.DSTOffset
+		}
+	}
+	for _, a := range s.Data.Names.AlternateNames(id) {
+		res.AlternateNames = append(res.AlternateNames, alternateName{Name: a.Name, Lang: a.Language, Preferred: a.IsPreferred})
+	}
+	return res, nil
+}
+
+func (s *Server) hierarchy(_ context.Context, params url.Values) (any, error) {
+	if s.Data.Names == nil {
+		return nil, notImplemented("name index")
+	}
+	id, err := idParam(params)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := s.Data.Names.Place(id)
+	if !ok {
+		return nil, &geonames.APIError{Code: geonames.CodeRecordDoesNotExist, Message: fmt.Sprintf("the geoname feature does not exist (id %d)", id)}
+	}
+
+	// ids lists the ancestors of p from the top down, ending with p.
+	var ids []int64
+	t := s.Data.Tables
+	if t != nil {
+		ids = t.Ancestors(id)
+	}
+	if len(ids) == 0 && t != nil {
+		if c, ok := t.Countries[p.CountryCode]; ok {
+			ids = append(ids, c.GeoNameID)
+		}
+		if a, ok := t.Admin1[p.CountryCode+"."+p.Admin1Code]; ok {
+			ids = append(ids, a.GeoNameID)
+		}
+		if a, ok := t.Admin2[p.CountryCode+"."+p.Admin1Code+"."+p.Admin2Code]; ok {
+			ids = append(ids, a.GeoNameID)
+		}
+	}
+	ids = append(ids, id)
+
+	var res []place
+	for i, ancestor := range ids {
+		// A place may be its own admin division, e.g. an ADM2 record.
+		if ancestor == 0 || i > 0 && ancestor == ids[i-1] {
+			continue
+		}
+		// Ancestors missing from the loaded dumps are left out.
+		if gp, ok := s.Data.Place(ancestor); ok {
+			res = append(res, newPlace(gp))
+		}
+	}
+	return placesResponse{Geonames: res}, nil
+}
+
+func (s *Server) findNearby(ctx context.Context, params url.Values) (any, error) {
+	return s.nearby(ctx, params, geonames.NearbyQuery{
+		FeatureClass: params["featureClass"],
+		FeatureCode:  params["featureCode"],
+	}, 10)
+}
+
+// findNearbyPlaceName returns the nearest populated places.
+func (s *Server) findNearbyPlaceName(ctx context.Context, params url.Values) (any, error) {
+	return s.nearby(ctx, params, geonames.NearbyQuery{FeatureClass: []string{"P"}}, 1)
+}
+
+func (s *Server) nearby(ctx context.Context, params url.Values, q geonames.NearbyQuery, rows int) (any, error) {
+	if s.Data.Spatial == nil {
+		return nil, notImplemented("spatial index")
+	}
+	pos, err := positionParams(params)
+	if err != nil {
+		return nil, err
+	}
+	if q.Radius, err = floatParam(params, "radius", 0); err != nil {
+		return nil, err
+	}
+	if q.MaxRows, err = intParam(params, "maxRows", rows); err != nil {
+		return nil, err
+	}
+	places, err := s.Data.FindNearby(ctx, pos, q)
+	if err != nil {
+		return nil, err
+	}
+	return placesResponse{Geonames: newPlaces(places)}, nil
+}
+
+type postalCode struct {
+	PostalCode  string  `json:"postalCode"`
+	PlaceName   string  `json:"placeName"`
+	CountryCode string  `json:"countryCode"`
+	AdminCode1  string  `json:"adminCode1,omitempty"`
+	AdminName1  string  `json:"adminName1,omitempty"`
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+}
+
+func (s *Server) postalCodeSearch(ctx context.Context, params url.Values) (any, error) {
+	if s.Data.Postal == nil {
+		return nil, notImplemented("postal code index")
+	}
+	rows, err := intParam(params, "maxRows", defaultPostalRows)
+	if err != nil {
+		return nil, err
+	}
+	country := params.Get("country")
+	var found []geonames.PostalCode
+	switch {
+	case params.Get("postalcode") != "":
+		if country == "" {
+			return nil, invalidParameter("parameter country is required with postalcode")
+		}
+		found = s.Data.Postal.ByCode(country, params.Get("postalcode"))
+	case params.Get("placename") != "":
+		found, err = s.Data.Postal.GetPostCode(ctx, params.Get("placename"), country)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, invalidParameter("missing parameter postalcode or placename")
+	}
+
+	res := make([]postalCode, 0, min(rows, len(found)))
+	for _, pc := range found[:min(rows, len(found))] {
+		res = append(res, postalCode{
+			PostalCode:  pc.PostalCode,
+			PlaceName:   pc.PlaceName,
+			CountryCode: pc.CountryCode,
+			AdminCode1:  pc.AdminCode1,
+			AdminName1:  pc.AdminName1,
+			Lat:         pc.Position.Lat,
+			Lng:         pc.Position.Lng,
+		})
+	}
+	return map[string][]postalCode{"postalCodes": res}, nil
+}
+
+// continentNames are the names countryInfoJSON gives continent codes.
+var continentNames = map[string]string{
+	"AF": "Africa",
+	"AN": "Antarctica",
+	"AS": "Asia",
+	"EU": "Europe",
+	"NA": "North America",
+	"OC": "Oceania",
+	"SA": "South America",
+}
+
+type country struct {
+	CountryCode   string `json:"countryCode"`
+	CountryName   string `json:"countryName"`
+	ISOAlpha3     string `json:"isoAlpha3"`
+	ISONumeric    string `json:"isoNumeric"`
+	FIPSCode      string `json:"fipsCode"`
+	Capital       string `json:"capital"`
+	Continent     string `json:"continent"`
+	ContinentName string `json:"continentName"`
+	AreaInSqKm    string `json:"areaInSqKm"`
+	Population    string `json:"population"`
+	CurrencyCode  string `json:"currencyCode"`
+	Languages     string `json:"languages"`
+	GeoNameID     int64  `json:"geonameId"`
+}
+
+// countryInfo describes the requested countries, or all of them,
+// ordered by country code. The bounding box is not in the dumps and
+// is left out.
+func (s *Server) countryInfo(_ context.Context, params url.Values) (any, error) {
+	if s.Data.Tables == nil || len(s.Data.Tables.Countries) == 0 {
+		return nil, notImplemented("country table")
+	}
+	codes := params["country"]
+	if len(codes) == 0 {
+		for cc := range s.Data.Tables.Countries {
+			codes = append(codes, cc)
+		}
+		slices.Sort(codes)
+	}
+	res := make([]country, 0, len(codes))
+	for _, cc := range codes {
+		c, ok := s.Data.Tables.Countries[strings.ToUpper(cc)]
+		if !ok {
+			continue
+		}
+		res = append(res, country{
+			CountryCode:   c.ISO,
+			CountryName:   c.Name,
+			ISOAlpha3:     c.ISO3,
+			ISONumeric:    c.ISONumeric,
+			FIPSCode:      c.FIPS,
+			Capital:       c.Capital,
+			Continent:     c.Continent,
+			ContinentName: continentNames[c.Continent],
+			AreaInSqKm:    strconv.FormatFloat(c.Area, 'f', 1, 64),
+			Population:    strconv.FormatInt(c.Population, 10),
+			CurrencyCode:  c.CurrencyCode,
+			Languages:     strings.Join(c.Languages, ","),
+			GeoNameID:     c.GeoNameID,
+		})
+	}
+	return map[string][]country{"geonames": res}, nil
+}
@@ -0,0 +1,334 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/dump"
+	"github.com/qba73/geonames/gazetteer"
+	"github.com/qba73/geonames/server"
+)
+
+func loadData(t *testing.T) *gazetteer.Offline {
+	t.Helper()
+	f, err := os.Open("../dump/testdata/alternateNamesV2.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	names, err := gazetteer.NewLocalGazetteer(dump.Places("../dump/testdata/IE.txt"), dump.AlternateNames(f))
+	if err != nil {
+		t.Fatal(err)
+	}
+	spatial, err := gazetteer.NewSpatialIndex(dump.Places("../dump/testdata/IE.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	postal, err := gazetteer.LoadPostalIndex("../dump/testdata/postal-allCountries.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := dump.LoadTables("../dump/testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &gazetteer.Offline{Names: names, Postal: postal, Spatial: spatial, Tables: tables}
+}
+
+// newClient returns a client of an httptest server running s.
+func newClient(t *testing.T, s *server.Server) *geonames.Client {
+	t.Helper()
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	c, err := geonames.New("DummyUser", geonames.WithBaseURL(ts.URL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+var castlebar = geonames.Place{
+	GeoNameID:        2965654,
+	Name:             "Castlebar",
+	ToponymName:      "Castlebar",
+	Position:         geonames.Position{Lat: 53.85583, Lng: -9.29778},
+	CountryCode:      "IE",
+	CountryName:      "Ireland",
+	AdminCode1:       "C",
+	AdminName1:       "Connacht",
+	FeatureClass:     "P",
+	FeatureClassName: "city, village,...",
+	FeatureCode:      "PPLA2",
+	FeatureCodeName:  "seat of a second-order administrative division",
+	Population:       12068,
+}
+
+func TestServer_SearchAnswersClient(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t, &server.Server{Data: loadData(t)})
+	got, err := c.Search(context.Background(), geonames.SearchQuery{
+		Name:         "Caisleán an Bharraigh",
+		Country:      []string{"IE"},
+		FeatureClass: []string{"P"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geonames.SearchResult{TotalResultsCount: 1, Places: []geonames.Place{castlebar}}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_SearchPagesThroughResults(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t, &server.Server{Data: loadData(t)})
+	var names []string
+	for p, err := range c.SearchAll(context.Background(), geonames.SearchQuery{NameStartsWith: "c", MaxRows: 2}, 0) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, p.Name)
+	}
+	want := []string{"Mayo", "Cork", "Castlebar", "Westport", "Croagh Patrick"}
+	if !cmp.Equal(want, names) {
+		t.Error(cmp.Diff(want, names))
+	}
+}
+
+func TestServer_HierarchyFollowsParentsOfTables(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t, &server.Server{Data: loadData(t)})
+	got, err := c.GetHierarchy(context.Background(), 2965654)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Connacht (7521314) is linked but missing from the places dump.
+	want := []string{"Ireland", "Mayo", "Castlebar"}
+	if names := placeNames(got); !cmp.Equal(want, names) {
+		t.Error(cmp.Diff(want, names))
+	}
+}
+
+func TestServer_HierarchyFallsBackToAdminCodes(t *testing.T) {
+	t.Parallel()
+
+	// Westport has no administrative link in hierarchy.txt.
+	c := newClient(t, &server.Server{Data: loadData(t)})
+	got, err := c.GetHierarchy(context.Background(), 2963403)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"Ireland", "Mayo", "Westport"}
+	if names := placeNames(got); !cmp.Equal(want, names) {
+		t.Error(cmp.Diff(want, names))
+	}
+}
+
+func TestServer_FindNearbyReturnsDistanceInKilometers(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t, &server.Server{Data: loadData(t)})
+	got, err := c.FindNearby(context.Background(), geonames.Position{Lat: 53.85, Lng: -9.3}, geonames.NearbyQuery{
+		FeatureClass: []string{"P"},
+		MaxRows:      2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := placeNames(got); !cmp.Equal([]string{"Castlebar", "Westport"}, names) {
+		t.Fatalf("want Castlebar and Westport, got %v", names)
+	}
+	if d := got[0].Distance; d < 0.6 || d > 0.7 {
+		t.Errorf("want Castlebar about 0.65 km away, got %v", d)
+	}
+}
+
+func TestServer_FindNearbyPlaceNameReturnsNearestPopulatedPlace(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t, &server.Server{Data: loadData(t)})
+	// Croagh Patrick is nearer but is a mountain.
+	body, err := c.GetRaw(context.Background(), "findNearbyPlaceNameJSON", url.Values{"lat": {"53.76"}, "lng": {"-9.66"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res struct {
+		Geonames []struct {
+			Name     string `json:"name"`
+			Distance string `json:"distance"`
+		} `json:"geonames"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Geonames) != 1 || res.Geonames[0].Name != "Westport" || res.Geonames[0].Distance == "" {
+		t.Errorf("want Westport with a distance, got %s", body)
+	}
+}
+
+func TestServer_GetPostCodeAnswersClient(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t, &server.Server{Data: loadData(t)})
+	got, err := c.GetPostCode(context.Background(), "castlebar", "IE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []geonames.PostalCode{{
+		PlaceName:   "Castlebar",
+		AdminName1:  "Connacht",
+		Position:    geonames.Position{Lat: 53.85, Lng: -9.3},
+		CountryCode: "IE",
+		PostalCode:  "F23",
+		AdminCode1:  "C",
+	}}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_PostalCodeSearchFindsPostalCode(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t, &server.Server{Data: loadData(t)})
+	body, err := c.GetRaw(context.Background(), "postalCodeSearchJSON", url.Values{"postalcode": {"F28"}, "country": {"IE"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"postalCodes":[{"postalCode":"F28","placeName":"Westport","countryCode":"IE","adminCode1":"C","adminName1":"Connacht","lat":53.8,"lng":-9.5167}]}`
+	if got := string(body); got != want {
+		t.Errorf("want %s, got %s", want, got)
+	}
+}
+
+func TestServer_GetCountryInfoAnswersClient(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t, &server.Server{Data: loadData(t)})
+	got, err := c.GetCountryInfo(context.Background(), "IE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []geonames.Country{{
+		CountryCode:   "IE",
+		CountryName:   "Ireland",
+		ISOAlpha3:     "IRL",
+		ISONumeric:    "372",
+		FIPSCode:      "EI",
+		Capital:       "Dublin",
+		Continent:     "EU",
+		ContinentName: "Europe",
+		AreaInSqKm:    70280,
+		Population:    4853506,
+		CurrencyCode:  "EUR",
+		Languages:     []string{"en-IE", "ga-IE"},
+		GeoNameID:     2963597,
+	}}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestServer_GetReturnsFullRecord(t *testing.T) {
+	t.Parallel()
+
+	c := newClient(t, &server.Server{Data: loadData(t)})
+	body, err := c.GetRaw(context.Background(), "getJSON", url.Values{"geonameId": {"2965654"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Name           string `json:"name"`
+		ASCIIName      string `json:"asciiName"`
+		AdminName2     string `json:"adminName2"`
+		Timezone       struct{ TimeZoneID string }
+		AlternateNames []struct{ Name, Lang string }
+	}
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Castlebar" || got.ASCIIName != "Castlebar" || got.AdminName2 != "Mayo" || got.Timezone.TimeZoneID != "Europe/Dublin" {
+		t.Errorf("unexpected record %s", body)
+	}
+	want := []struct{ Name, Lang string }{{"Caisleán an Bharraigh", "ga"}, {"Castlebar", "en"}}
+	if !cmp.Equal(want, got.AlternateNames) {
+		t.Error(cmp.Diff(want, got.AlternateNames))
+	}
+}
+
+func TestServer_ReportsErrorsInStatusEnvelope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     *gazetteer.Offline
+		endpoint string
+		params   url.Values
+		code     int
+	}{
+		{name: "unknown id", endpoint: "getJSON", params: url.Values{"geonameId": {"1"}}, code: geonames.CodeRecordDoesNotExist},
+		{name: "missing id", endpoint: "hierarchyJSON", code: geonames.CodeInvalidParameter},
+		{name: "missing query", endpoint: "searchJSON", code: geonames.CodeInvalidParameter},
+		{name: "too many rows", endpoint: "searchJSON", params: url.Values{"q": {"mayo"}, "maxRows": {"1001"}}, code: geonames.CodeMaxRowsTooLarge},
+		{name: "invalid position", endpoint: "findNearbyJSON", params: url.Values{"lat": {"91"}, "lng": {"0"}}, code: geonames.CodeInvalidParameter},
+		{name: "missing index", data: &gazetteer.Offline{}, endpoint: "findNearbyJSON", params: url.Values{"lat": {"53"}, "lng": {"-9"}}, code: geonames.CodeServiceNotImplemented},
+	}
+	data := loadData(t)
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			s := &server.Server{Data: data}
+			if tc.data != nil {
+				s.Data = tc.data
+			}
+			_, err := newClient(t, s).GetRaw(context.Background(), tc.endpoint, tc.params)
+			var ae *geonames.APIError
+			if !errors.As(err, &ae) || ae.Code != tc.code {
+				t.Errorf("want API error %d, got %v", tc.code, err)
+			}
+		})
+	}
+}
+
+func TestServer_RejectsUnknownPathsAndMethods(t *testing.T) {
+	t.Parallel()
+
+	ts := httptest.NewServer(&server.Server{Data: loadData(t)})
+	defer ts.Close()
+	res, err := http.Get(ts.URL + "/wikipediaSearchJSON")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("want status 404 for unknown path, got %d", res.StatusCode)
+	}
+	res, err = http.Post(ts.URL+"/searchJSON", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("want status 405 for POST, got %d", res.StatusCode)
+	}
+}
+
+func placeNames(ps []geonames.Place) []string {
+	var res []string
+	for _, p := range ps {
+		res = append(res, p.Name)
+	}
+	return res
+}
@@ -0,0 +1,108 @@
+package sqlexport
+
+import (
+	"encoding/csv"
+	"fmt"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/qba73/geonames/dump"
+)
+
+// SchemaFile is the name of the script written by CSVWriter.WriteSchema.
+const SchemaFile = "schema.sql"
+
+// CSVWriter writes each table to a CSV file with a header row in a
+// directory, named after the table, e.g. places.csv. Loading CSV is
+// much faster than running INSERT statements for the full dumps.
+type CSVWriter struct {
+	Dir string
+}
+
+// NewCSVWriter returns a CSVWriter writing to dir, which must exist.
+func NewCSVWriter(dir string) *CSVWriter {
+	return &CSVWriter{Dir: dir}
+}
+
+// WriteSchema writes schema.sql, which creates the tables, imports
+// the CSV files found in the directory and then creates the indexes.
+// It is meant for the sqlite3 shell, run from the directory:
+//
+//	sqlite3 geonames.db < schema.sql
+//
+// Other databases can use the CREATE statements and their own bulk
+// loader instead of the sqlite3 .import commands.
+func (c *CSVWriter) WriteSchema() error {
+	var b strings.Builder
+	b.WriteString(Schema())
+	b.WriteString("\n")
+	for _, t := range tables {
+		name := t.name + ".csv"
+		if _, err := os.Stat(filepath.Join(c.Dir, name)); err != nil {
+			continue
+		}
+		fmt.Fprintf(&b, ".import --csv --skip 1 %s %s\n", name, t.name)
+	}
+	b.WriteString("\n")
+	b.WriteString(Indexes())
+	return os.WriteFile(filepath.Join(c.Dir, SchemaFile), []byte(b.String()), 0o644)
+}
+
+// WritePlaces writes places to places.csv and
+// returns the number of rows written.
+func (c *CSVWriter) WritePlaces(seq iter.Seq2[dump.Place, error]) (int, error) {
+	return writeCSV(c, placesTable, seq, placeRow)
+}
+
+// WriteAlternateNames writes alternate names to alternate_names.csv.
+func (c *CSVWriter) WriteAlternateNames(seq iter.Seq2[dump.AlternateName, error]) (int, error) {
+	return writeCSV(c, alternateNamesTable, seq, alternateNameRow)
+}
+
+// WriteAdminCodes writes administrative divisions to admin_codes.csv.
+func (c *CSVWriter) WriteAdminCodes(seq iter.Seq2[dump.AdminCode, error]) (int, error) {
+	return writeCSV(c, adminCodesTable, seq, adminCodeRow)
+}
+
+// WritePostalCodes writes postal codes to postal_codes.csv.
+func (c *CSVWriter) WritePostalCodes(seq iter.Seq2[dump.PostalCode, error]) (int, error) {
+	return writeCSV(c, postalCodesTable, seq, postalCodeRow)
+}
+
+// WriteHierarchy writes hierarchy links to hierarchy.csv.
+func (c *CSVWriter) WriteHierarchy(seq iter.Seq2[dump.HierarchyLink, error]) (int, error) {
+	return writeCSV(c, hierarchyTable, seq, hierarchyRow)
+}
+
+// writeCSV writes the rows of seq to the CSV file of table t,
+// replacing the file if it exists. NULL values are written as
+// empty fields, which sqlite3 imports as empty strings.
+func writeCSV[T any](c *CSVWriter, t table, seq iter.Seq2[T, error], row func(T) []any) (n int, err error) {
+	f, err := os.Create(filepath.Join(c.Dir, t.name+".csv"))
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+	w := csv.NewWriter(f)
+	if err := w.Write(t.columnNames()); err != nil {
+		return 0, err
+	}
+	record := make([]string, len(t.columns))
+	n, err = rows(seq, row, func(values []any) error {
+		for i, v := range values {
+			record[i], _ = text(v)
+		}
+		return w.Write(record)
+	})
+	w.Flush()
+	if err != nil {
+		return n, err
+	}
+	return n, w.Error()
+}
@@ -0,0 +1,136 @@
+package sqlexport
+
+import (
+	"bufio"
+	"io"
+	"iter"
+	"strings"
+	"time"
+
+	"github.com/qba73/geonames/dump"
+)
+
+// DefaultBatchSize is the number of rows per INSERT statement
+// written by a SQLWriter with a zero BatchSize.
+const DefaultBatchSize = 500
+
+// SQLWriter writes records as SQL statements. Each Write method
+// streams its records as multi-row INSERT statements inside a single
+// transaction, so memory use does not grow with the size of the dump.
+// A record error rolls the transaction back.
+type SQLWriter struct {
+	w *bufio.Writer
+	// BatchSize is the number of rows per INSERT statement.
+	BatchSize int
+}
+
+// NewSQLWriter returns a SQLWriter writing to w.
+func NewSQLWriter(w io.Writer) *SQLWriter {
+	return &SQLWriter{w: bufio.NewWriter(w), BatchSize: DefaultBatchSize}
+}
+
+// WriteSchema writes the statements creating every table.
+func (s *SQLWriter) WriteSchema() error {
+	return s.write(Schema())
+}
+
+// WriteIndexes writes the statements creating the indexes.
+func (s *SQLWriter) WriteIndexes() error {
+	return s.write(Indexes())
+}
+
+// WritePlaces writes places to the places table and
+// returns the number of rows written.
+func (s *SQLWriter) WritePlaces(seq iter.Seq2[dump.Place, error]) (int, error) {
+	return insert(s, placesTable, seq, placeRow)
+}
+
+// WriteAlternateNames writes alternate names to the alternate_names table.
+func (s *SQLWriter) WriteAlternateNames(seq iter.Seq2[dump.AlternateName, error]) (int, error) {
+	return insert(s, alternateNamesTable, seq, alternateNameRow)
+}
+
+// WriteAdminCodes writes first- and second-order administrative
+// divisions to the admin_codes table.
+func (s *SQLWriter) WriteAdminCodes(seq iter.Seq2[dump.AdminCode, error]) (int, error) {
+	return insert(s, adminCodesTable, seq, adminCodeRow)
+}
+
+// WritePostalCodes writes postal codes to the postal_codes table.
+func (s *SQLWriter) WritePostalCodes(seq iter.Seq2[dump.PostalCode, error]) (int, error) {
+	return insert(s, postalCodesTable, seq, postalCodeRow)
+}
+
+// WriteHierarchy writes hierarchy links to the hierarchy table.
+func (s *SQLWriter) WriteHierarchy(seq iter.Seq2[dump.HierarchyLink, error]) (int, error) {
+	return insert(s, hierarchyTable, seq, hierarchyRow)
+}
+
+func (s *SQLWriter) write(str string) error {
+	if _, err := s.w.WriteString(str); err != nil {
+		return err
+	}
+	return s.w.Flush()
+}
+
+// insert writes the rows of seq as batched INSERT statements in a
+// transaction. If seq yields an error, the open statement is ended and
+// the transaction rolled back, so that the script still parses.
+func insert[T any](s *SQLWriter, t table, seq iter.Seq2[T, error], row func(T) []any) (int, error) {
+	size := s.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	head := "INSERT INTO " + t.name + " (" + strings.Join(t.columnNames(), ", ") + ") VALUES\n"
+	if _, err := s.w.WriteString("BEGIN;\n"); err != nil {
+		return 0, err
+	}
+	inBatch := 0
+	n, err := rows(seq, row, func(values []any) error {
+		if inBatch == 0 {
+			s.w.WriteString(head)
+		} else {
+			s.w.WriteString(",\n")
+		}
+		s.w.WriteString("(")
+		for i, v := range values {
+			if i > 0 {
+				s.w.WriteString(", ")
+			}
+			s.w.WriteString(literal(v))
+		}
+		s.w.WriteString(")")
+		if inBatch++; inBatch == size {
+			inBatch = 0
+			_, err := s.w.WriteString(";\n")
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		if inBatch > 0 {
+			s.w.WriteString(";\n")
+		}
+		s.w.WriteString("ROLLBACK;\n")
+		s.w.Flush()
+		return n, err
+	}
+	if inBatch > 0 {
+		s.w.WriteString(";\n")
+	}
+	s.w.WriteString("COMMIT;\n")
+	return n, s.w.Flush()
+}
+
+// literal formats v as a SQL literal.
+func literal(v any) string {
+	s, ok := text(v)
+	if !ok {
+		return "NULL"
+	}
+	switch v.(type) {
+	case string, time.Time:
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	}
+	return s
+}
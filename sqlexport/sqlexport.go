@@ -0,0 +1,280 @@
+// Package sqlexport writes the records parsed by package dump as a
+// normalized SQL database, either as a script of SQL statements or as
+// CSV files with a script importing them.
+//
+// The SQL uses only types and syntax understood by SQLite as well as
+// most other databases, so the output can be loaded with the sqlite3
+// shell without cgo or a database driver in the exporting program:
+//
+//	sqlite3 geonames.db < geonames.sql
+//
+// Relations between tables are by GeoNames ID; foreign keys are not
+// declared so that a subset of the dumps can be exported.
+package sqlexport
+
+import (
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qba73/geonames/dump"
+)
+
+// table describes an exported table.
+type table struct {
+	name    string
+	columns []column
+	// primaryKey, if set, is the primary key constraint.
+	primaryKey string
+	indexes    []index
+}
+
+type column struct {
+	name, typ string
+}
+
+type index struct {
+	name    string
+	columns string
+}
+
+var placesTable = table{
+	name: "places",
+	columns: []column{
+		{"geonameid", "INTEGER NOT NULL"},
+		{"name", "TEXT NOT NULL"},
+		{"asciiname", "TEXT"},
+		{"latitude", "REAL NOT NULL"},
+		{"longitude", "REAL NOT NULL"},
+		{"feature_class", "TEXT"},
+		{"feature_code", "TEXT"},
+		{"country_code", "TEXT"},
+		{"cc2", "TEXT"},
+		{"admin1_code", "TEXT"},
+		{"admin2_code", "TEXT"},
+		{"admin3_code", "TEXT"},
+		{"admin4_code", "TEXT"},
+		{"population", "INTEGER"},
+		{"elevation", "INTEGER"},
+		{"dem", "INTEGER"},
+		{"timezone", "TEXT"},
+		{"modified", "TEXT"},
+	},
+	primaryKey: "geonameid",
+	indexes: []index{
+		{"places_name", "name"},
+		{"places_asciiname", "asciiname"},
+		{"places_admin", "country_code, admin1_code, admin2_code"},
+		{"places_latlng", "latitude, longitude"},
+	},
+}
+
+func placeRow(p dump.Place) []any {
+	return []any{
+		p.GeoNameID, p.Name, p.ASCIIName, p.Position.Lat, p.Position.Lng,
+		p.FeatureClass, p.FeatureCode, p.CountryCode, strings.Join(p.CC2, ","),
+		p.Admin1Code, p.Admin2Code, p.Admin3Code, p.Admin4Code,
+		p.Population, p.Elevation, p.DEM, p.Timezone, p.Modified,
+	}
+}
+
+var alternateNamesTable = table{
+	name: "alternate_names",
+	columns: []column{
+		{"alternatenameid", "INTEGER NOT NULL"},
+		{"geonameid", "INTEGER NOT NULL"},
+		{"isolanguage", "TEXT"},
+		{"name", "TEXT NOT NULL"},
+		{"is_preferred", "INTEGER NOT NULL"},
+		{"is_short", "INTEGER NOT NULL"},
+		{"is_colloquial", "INTEGER NOT NULL"},
+		{"is_historic", "INTEGER NOT NULL"},
+		{"used_from", "TEXT"},
+		{"used_to", "TEXT"},
+	},
+	primaryKey: "alternatenameid",
+	indexes: []index{
+		{"alternate_names_geonameid", "geonameid"},
+		{"alternate_names_name", "name"},
+	},
+}
+
+func alternateNameRow(a dump.AlternateName) []any {
+	return []any{
+		a.ID, a.GeoNameID, a.Language, a.Name,
+		a.IsPreferred, a.IsShort, a.IsColloquial, a.IsHistoric, a.From, a.To,
+	}
+}
+
+var adminCodesTable = table{
+	name: "admin_codes",
+	columns: []column{
+		{"code", "TEXT NOT NULL"},
+		{"country_code", "TEXT NOT NULL"},
+		{"admin1_code", "TEXT NOT NULL"},
+		{"admin2_code", "TEXT"},
+		{"name", "TEXT"},
+		{"asciiname", "TEXT"},
+		{"geonameid", "INTEGER"},
+	},
+	primaryKey: "code",
+	indexes: []index{
+		{"admin_codes_admin", "country_code, admin1_code, admin2_code"},
+		{"admin_codes_geonameid", "geonameid"},
+	},
+}
+
+func adminCodeRow(a dump.AdminCode) []any {
+	return []any{a.Code, a.CountryCode, a.Admin1Code, a.Admin2Code, a.Name, a.ASCIIName, a.GeoNameID}
+}
+
+var postalCodesTable = table{
+	name: "postal_codes",
+	columns: []column{
+		{"country_code", "TEXT NOT NULL"},
+		{"postal_code", "TEXT NOT NULL"},
+		{"place_name", "TEXT"},
+		{"admin_name1", "TEXT"},
+		{"admin_code1", "TEXT"},
+		{"admin_name2", "TEXT"},
+		{"admin_code2", "TEXT"},
+		{"admin_name3", "TEXT"},
+		{"admin_code3", "TEXT"},
+		{"latitude", "REAL"},
+		{"longitude", "REAL"},
+		{"accuracy", "INTEGER"},
+	},
+	indexes: []index{
+		{"postal_codes_code", "country_code, postal_code"},
+		{"postal_codes_place_name", "place_name"},
+		{"postal_codes_admin", "country_code, admin_code1, admin_code2"},
+		{"postal_codes_latlng", "latitude, longitude"},
+	},
+}
+
+func postalCodeRow(pc dump.PostalCode) []any {
+	return []any{
+		pc.CountryCode, pc.PostalCode, pc.PlaceName,
+		pc.AdminName1, pc.AdminCode1, pc.AdminName2, pc.AdminCode2, pc.AdminName3, pc.AdminCode3,
+		pc.Position.Lat, pc.Position.Lng, pc.Accuracy,
+	}
+}
+
+var hierarchyTable = table{
+	name: "hierarchy",
+	columns: []column{
+		{"parent_id", "INTEGER NOT NULL"},
+		{"child_id", "INTEGER NOT NULL"},
+		{"type", "TEXT"},
+	},
+	indexes: []index{
+		{"hierarchy_parent", "parent_id"},
+		{"hierarchy_child", "child_id"},
+	},
+}
+
+func hierarchyRow(h dump.HierarchyLink) []any {
+	return []any{h.ParentID, h.ChildID, h.Type}
+}
+
+// tables lists every exported table in creation order.
+var tables = []table{placesTable, alternateNamesTable, adminCodesTable, postalCodesTable, hierarchyTable}
+
+func (t table) columnNames() []string {
+	names := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+func (t table) create() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS %s (\n", t.name)
+	for i, c := range t.columns {
+		if i > 0 {
+			b.WriteString(",\n")
+		}
+		fmt.Fprintf(&b, "  %s %s", c.name, c.typ)
+	}
+	if t.primaryKey != "" {
+		fmt.Fprintf(&b, ",\n  PRIMARY KEY (%s)", t.primaryKey)
+	}
+	b.WriteString("\n);\n")
+	return b.String()
+}
+
+func (t table) createIndexes() string {
+	var b strings.Builder
+	for _, idx := range t.indexes {
+		fmt.Fprintf(&b, "CREATE INDEX IF NOT EXISTS %s ON %s (%s);\n", idx.name, t.name, idx.columns)
+	}
+	return b.String()
+}
+
+// Schema returns the statements creating every table.
+func Schema() string {
+	var b strings.Builder
+	for i, t := range tables {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		b.WriteString(t.create())
+	}
+	return b.String()
+}
+
+// Indexes returns the statements creating the indexes on names,
+// country and admin codes, and coordinates. Creating them after
+// loading the data is faster than maintaining them during the load.
+func Indexes() string {
+	var b strings.Builder
+	for _, t := range tables {
+		b.WriteString(t.createIndexes())
+	}
+	return b.String()
+}
+
+// text formats a value as it is stored: booleans as 0 or 1 and times
+// as dates. It reports false for values stored as NULL.
+func text(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		if v {
+			return "1", true
+		}
+		return "0", true
+	case time.Time:
+		if v.IsZero() {
+			return "", false
+		}
+		return v.Format(time.DateOnly), true
+	}
+	panic(fmt.Sprintf("sqlexport: unsupported value type %T", v))
+}
+
+// rows calls write with the row of every record of seq,
+// stopping at the first error. It returns the number of rows written.
+func rows[T any](seq iter.Seq2[T, error], row func(T) []any, write func([]any) error) (int, error) {
+	n := 0
+	for v, err := range seq {
+		if err != nil {
+			return n, err
+		}
+		if err := write(row(v)); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
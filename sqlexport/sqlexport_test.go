@@ -0,0 +1,214 @@
+package sqlexport_test
+
+import (
+	"encoding/csv"
+	"errors"
+	"iter"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+	"github.com/qba73/geonames/dump"
+	"github.com/qba73/geonames/sqlexport"
+)
+
+// seq is a test helper yielding the given records without errors.
+func seq[T any](records ...T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for _, r := range records {
+			if !yield(r, nil) {
+				return
+			}
+		}
+	}
+}
+
+var castlebar = dump.Place{
+	GeoNameID:    2965654,
+	Name:         "Castlebar",
+	ASCIIName:    "Castlebar",
+	Position:     geonames.Position{Lat: 53.85583, Lng: -9.29778},
+	FeatureClass: "P",
+	FeatureCode:  "PPLA2",
+	CountryCode:  "IE",
+	Admin1Code:   "C",
+	Admin2Code:   "20",
+	Population:   12068,
+	DEM:          41,
+	Timezone:     "Europe/Dublin",
+	Modified:     time.Date(2020, 4, 18, 0, 0, 0, 0, time.UTC),
+}
+
+func TestSQLWriter_WritesBatchedInsertsInTransaction(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	w := sqlexport.NewSQLWriter(&b)
+	w.BatchSize = 2
+	n, err := w.WriteHierarchy(seq(
+		dump.HierarchyLink{ParentID: 2963597, ChildID: 7521314, Type: "ADM"},
+		dump.HierarchyLink{ParentID: 7521314, ChildID: 2962666, Type: "ADM"},
+		dump.HierarchyLink{ParentID: 2962666, ChildID: 2965654},
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("want 3 rows, got %d", n)
+	}
+	want := `BEGIN;
+INSERT INTO hierarchy (parent_id, child_id, type) VALUES
+(2963597, 7521314, 'ADM'),
+(7521314, 2962666, 'ADM');
+INSERT INTO hierarchy (parent_id, child_id, type) VALUES
+(2962666, 2965654, '');
+COMMIT;
+`
+	if got := b.String(); want != got {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestSQLWriter_QuotesTextAndWritesNullForUnknownDates(t *testing.T) {
+	t.Parallel()
+
+	p := castlebar
+	p.Name = "Caisleán an Bharraigh's"
+	p.CC2 = []string{"GB", "NI"}
+	unknown := castlebar
+	unknown.Modified = time.Time{}
+
+	var b strings.Builder
+	if _, err := sqlexport.NewSQLWriter(&b).WritePlaces(seq(p, unknown)); err != nil {
+		t.Fatal(err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"(2965654, 'Caisleán an Bharraigh''s', 'Castlebar', 53.85583, -9.29778, 'P', 'PPLA2', 'IE', 'GB,NI', 'C', '20', '', '', 12068, 0, 41, 'Europe/Dublin', '2020-04-18')",
+		"12068, 0, 41, 'Europe/Dublin', NULL)",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want output containing %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestSQLWriter_StopsAtFirstRecordError(t *testing.T) {
+	t.Parallel()
+
+	boom := errors.New("boom")
+	records := func(yield func(dump.AlternateName, error) bool) {
+		if !yield(dump.AlternateName{ID: 1, GeoNameID: 2, Name: "x"}, nil) {
+			return
+		}
+		yield(dump.AlternateName{}, boom)
+	}
+	var b strings.Builder
+	n, err := sqlexport.NewSQLWriter(&b).WriteAlternateNames(records)
+	if !errors.Is(err, boom) {
+		t.Errorf("want record error, got %v", err)
+	}
+	if n != 1 {
+		t.Errorf("want 1 row written, got %d", n)
+	}
+	if strings.Contains(b.String(), "COMMIT") {
+		t.Error("want transaction left uncommitted")
+	}
+	out := b.String()
+	if !strings.HasSuffix(out, "ROLLBACK;\n") {
+		t.Fatalf("want transaction rolled back, got:\n%s", out)
+	}
+	// Every statement is complete: the partial batch is ended before the rollback.
+	stmts := strings.Split(strings.TrimSuffix(out, ";\n"), ";\n")
+	want := []string{"BEGIN", "INSERT INTO alternate_names", "ROLLBACK"}
+	if len(stmts) != len(want) {
+		t.Fatalf("want %d statements, got %d:\n%s", len(want), len(stmts), out)
+	}
+	for i, stmt := range stmts {
+		if !strings.HasPrefix(stmt, want[i]) || strings.Count(stmt, "(") != strings.Count(stmt, ")") {
+			t.Errorf("statement %d: want complete %s, got %q", i, want[i], stmt)
+		}
+	}
+}
+
+func TestSchema_CreatesTablesAndIndexes(t *testing.T) {
+	t.Parallel()
+
+	schema := sqlexport.Schema()
+	for _, table := range []string{"places", "alternate_names", "admin_codes", "postal_codes", "hierarchy"} {
+		if !strings.Contains(schema, "CREATE TABLE IF NOT EXISTS "+table+" (") {
+			t.Errorf("want table %s in schema", table)
+		}
+	}
+	indexes := sqlexport.Indexes()
+	for _, want := range []string{
+		"ON places (name);",
+		"ON places (country_code, admin1_code, admin2_code);",
+		"ON places (latitude, longitude);",
+		"ON alternate_names (name);",
+		"ON postal_codes (country_code, postal_code);",
+	} {
+		if !strings.Contains(indexes, want) {
+			t.Errorf("want index %q", want)
+		}
+	}
+}
+
+func TestCSVWriter_WritesTablesAndImportScript(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	w := sqlexport.NewCSVWriter(dir)
+	if _, err := w.WritePlaces(seq(castlebar)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteAdminCodes(seq(dump.AdminCode{
+		Code: "IE.C", CountryCode: "IE", Admin1Code: "C", Name: "Connacht", ASCIIName: "Connacht", GeoNameID: 7521314,
+	})); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.WriteSchema(); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "places.csv"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{
+		{"geonameid", "name", "asciiname", "latitude", "longitude", "feature_class", "feature_code", "country_code", "cc2", "admin1_code", "admin2_code", "admin3_code", "admin4_code", "population", "elevation", "dem", "timezone", "modified"},
+		{"2965654", "Castlebar", "Castlebar", "53.85583", "-9.29778", "P", "PPLA2", "IE", "", "C", "20", "", "", "12068", "0", "41", "Europe/Dublin", "2020-04-18"},
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	schema, err := os.ReadFile(filepath.Join(dir, sqlexport.SchemaFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(schema)
+	for _, want := range []string{
+		".import --csv --skip 1 places.csv places\n",
+		".import --csv --skip 1 admin_codes.csv admin_codes\n",
+	} {
+		if !strings.Contains(s, want) {
+			t.Errorf("want schema containing %q", want)
+		}
+	}
+	if strings.Contains(s, "postal_codes.csv") {
+		t.Error("want no import of tables not written")
+	}
+	if strings.Index(s, ".import") > strings.Index(s, "CREATE INDEX") {
+		t.Error("want indexes created after import")
+	}
+}
@@ -0,0 +1,79 @@
+package geonames
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+type timezoneResp struct {
+	apiStatus
+	TimezoneID  string  `json:"timezoneId"`
+	CountryCode string  `json:"countryCode"`
+	CountryName string  `json:"countryName"`
+	Lat         float64 `json:"lat"`
+	Lng         float64 `json:"lng"`
+	GMTOffset   float64 `json:"gmtOffset"`
+	DSTOffset   float64 `json:"dstOffset"`
+	RawOffset   float64 `json:"rawOffset"`
+	Time        string  `json:"time"`
+	Sunrise     string  `json:"sunrise"`
+	Sunset      string  `json:"sunset"`
+}
+
+// Timezone describes the time zone at a position. Offsets are
+// in hours from UTC; times are local, formatted "2006-01-02 15:04".
+type Timezone struct {
+	TimezoneID  string
+	CountryCode string
+	CountryName string
+	Position    Position
+	// GMTOffset is the offset on 1 January.
+	GMTOffset float64
+	// DSTOffset is the offset on 1 July.
+	DSTOffset float64
+	// RawOffset is the offset without daylight saving time.
+	RawOffset float64
+	Time      string
+	Sunrise   string
+	Sunset    string
+}
+
+func (c Client) buildTimezoneURL(pos Position) (string, error) {
+	params := url.Values{
+		"lat":      {strconv.FormatFloat(pos.Lat, 'f', -1, 64)},
+		"lng":      {strconv.FormatFloat(pos.Lng, 'f', -1, 64)},
+		"username": {c.UserName},
+	}
+	u, err := url.Parse(fmt.Sprintf("%s/timezoneJSON", c.BaseURL))
+	if err != nil {
+		return "", fmt.Errorf("parsing timezone base url: %w", err)
+	}
+	u.RawQuery = params.Encode()
+	return u.String(), nil
+}
+
+// GetTimezone returns the time zone at the given position.
+func (c Client) GetTimezone(ctx context.Context, pos Position) (Timezone, error) {
+	u, err := c.buildTimezoneURL(pos)
+	if err != nil {
+		return Timezone{}, err
+	}
+	var tr timezoneResp
+	if err := c.get(ctx, u, &tr); err != nil {
+		return Timezone{}, err
+	}
+	return Timezone{
+		TimezoneID:  tr.TimezoneID,
+		CountryCode: tr.CountryCode,
+		CountryName: tr.CountryName,
+		Position:    Position{Lat: tr.Lat, Lng: tr.Lng},
+		GMTOffset:   tr.GMTOffset,
+		DSTOffset:   tr.DSTOffset,
+		RawOffset:   tr.RawOffset,
+		Time:        tr.Time,
+		Sunrise:     tr.Sunrise,
+		Sunset:      tr.Sunset,
+	}, nil
+}
@@ -0,0 +1,43 @@
+package geonames_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/qba73/geonames"
+)
+
+func TestGetTimezone_ReturnsTimezoneOnValidInput(t *testing.T) {
+	t.Parallel()
+
+	ts := newTestServer(
+		"geonamestest/fixtures/timezone.json",
+		"/timezoneJSON?lat=53.85583&lng=-9.29778&username=DummyUser",
+		t,
+	)
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	got, err := client.GetTimezone(context.Background(), geonames.Position{Lat: 53.85583, Lng: -9.29778})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := geonames.Timezone{
+		TimezoneID:  "Europe/Dublin",
+		CountryCode: "IE",
+		CountryName: "Ireland",
+		Position:    geonames.Position{Lat: 53.85583, Lng: -9.29778},
+		GMTOffset:   0,
+		DSTOffset:   1,
+		RawOffset:   0,
+		Time:        "2026-10-19 14:02",
+		Sunrise:     "2026-10-19 08:11",
+		Sunset:      "2026-10-19 18:35",
+	}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
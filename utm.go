@@ -0,0 +1,293 @@
+package geonames
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// utmBands are the latitude bands of UTM and MGRS, 8° tall
+// from 80°S, except for X which covers 72°N to 84°N.
+const utmBands = "CDEFGHJKLMNPQRSTUVWX"
+
+// UTM transverse Mercator constants on the WGS-84 ellipsoid.
+const (
+	utmScale         = 0.9996
+	utmFalseEasting  = 500000.0
+	utmFalseNorthing = 10000000.0
+	wgs84E2          = wgs84F * (2 - wgs84F)
+	wgs84EP2         = wgs84E2 / (1 - wgs84E2)
+)
+
+// UTM is a position in the Universal Transverse Mercator system.
+type UTM struct {
+	// Zone is the longitude zone, 1 to 60.
+	Zone int
+	// Band is the latitude band letter, 'C' to 'X'. Bands
+	// from 'N' up are in the northern hemisphere.
+	Band byte
+	// Easting and Northing are in meters.
+	Easting, Northing float64
+}
+
+// String writes u as, for example, "29U 467000 5966990",
+// rounded to the meter.
+func (u UTM) String() string {
+	return fmt.Sprintf("%d%c %.0f %.0f", u.Zone, u.Band, u.Easting, u.Northing)
+}
+
+// north reports whether u is in the northern hemisphere.
+func (u UTM) north() bool {
+	return u.Band >= 'N'
+}
+
+// centralMeridian returns the central meridian of a UTM zone in degrees.
+func centralMeridian(zone int) float64 {
+	return float64(zone-1)*6 - 180 + 3
+}
+
+// utmZone returns the zone and band of p, including the
+// exceptions around Norway and Svalbard.
+func utmZone(p Position) (int, byte) {
+	lng := normalizeLng(p.Lng)
+	zone := int((lng+180)/6) + 1
+	if zone > 60 {
+		zone = 60
+	}
+	band := utmBands[min(int((p.Lat+80)/8), len(utmBands)-1)]
+	switch {
+	case band == 'V' && lng >= 3 && lng < 12:
+		zone = 32
+	case band == 'X' && lng >= 0 && lng < 9:
+		zone = 31
+	case band == 'X' && lng >= 9 && lng < 21:
+		zone = 33
+	case band == 'X' && lng >= 21 && lng < 33:
+		zone = 35
+	case band == 'X' && lng >= 33 && lng < 42:
+		zone = 37
+	}
+	return zone, band
+}
+
+// UTM converts p to UTM coordinates. UTM is defined between 80°S and
+// 84°N; the polar regions use UPS, which is not supported.
+func (p Position) UTM() (UTM, error) {
+	if err := p.Validate(); err != nil {
+		return UTM{}, err
+	}
+	if p.Lat < -80 || p.Lat > 84 {
+		return UTM{}, fmt.Errorf("latitude %v outside the UTM area: %w", p.Lat, ErrInvalidPosition)
+	}
+	zone, band := utmZone(p)
+	e, n := transverseMercator(radians(p.Lat), radians(normalizeLng(p.Lng-centralMeridian(zone))))
+	if p.Lat < 0 {
+		n += utmFalseNorthing
+	}
+	return UTM{Zone: zone, Band: band, Easting: e + utmFalseEasting, Northing: n}, nil
+}
+
+// meridianArc returns the distance in meters along a meridian
+// from the equator to latitude phi.
+func meridianArc(phi float64) float64 {
+	e2, e4, e6 := wgs84E2, wgs84E2*wgs84E2, wgs84E2*wgs84E2*wgs84E2
+	return wgs84A * ((1-e2/4-3*e4/64-5*e6/256)*phi -
+		(3*e2/8+3*e4/32+45*e6/1024)*math.Sin(2*phi) +
+		(15*e4/256+45*e6/1024)*math.Sin(4*phi) -
+		(35*e6/3072)*math.Sin(6*phi))
+}
+
+// transverseMercator projects latitude phi at longitude lambda from
+// the central meridian, both in radians, to scaled easting and
+// northing in meters before the false origins are added.
+func transverseMercator(phi, lambda float64) (float64, float64) {
+	sinPhi, cosPhi := math.Sincos(phi)
+	n := wgs84A / math.Sqrt(1-wgs84E2*sinPhi*sinPhi)
+	t := math.Tan(phi) * math.Tan(phi)
+	c := wgs84EP2 * cosPhi * cosPhi
+	a := cosPhi * lambda
+	x := utmScale * n * (a + (1-t+c)*a*a*a/6 + (5-18*t+t*t+72*c-58*wgs84EP2)*math.Pow(a, 5)/120)
+	y := utmScale * (meridianArc(phi) + n*math.Tan(phi)*(a*a/2+(5-t+9*c+4*c*c)*math.Pow(a, 4)/24+
+		(61-58*t+t*t+600*c-330*wgs84EP2)*math.Pow(a, 6)/720))
+	return x, y
+}
+
+// Position converts u to latitude and longitude.
+func (u UTM) Position() (Position, error) {
+	if u.Zone < 1 || u.Zone > 60 || !strings.ContainsRune(utmBands, rune(u.Band)) {
+		return Position{}, fmt.Errorf("invalid UTM zone %d%c", u.Zone, u.Band)
+	}
+	x := u.Easting - utmFalseEasting
+	y := u.Northing
+	if !u.north() {
+		y -= utmFalseNorthing
+	}
+
+	e2 := wgs84E2
+	m := y / utmScale
+	mu := m / (wgs84A * (1 - e2/4 - 3*e2*e2/64 - 5*e2*e2*e2/256))
+	e1 := (1 - math.Sqrt(1-e2)) / (1 + math.Sqrt(1-e2))
+	phi1 := mu + (3*e1/2-27*math.Pow(e1, 3)/32)*math.Sin(2*mu) +
+		(21*e1*e1/16-55*math.Pow(e1, 4)/32)*math.Sin(4*mu) +
+		(151*math.Pow(e1, 3)/96)*math.Sin(6*mu) +
+		(1097*math.Pow(e1, 4)/512)*math.Sin(8*mu)
+
+	sinPhi1, cosPhi1 := math.Sincos(phi1)
+	n1 := wgs84A / math.Sqrt(1-e2*sinPhi1*sinPhi1)
+	t1 := math.Tan(phi1) * math.Tan(phi1)
+	c1 := wgs84EP2 * cosPhi1 * cosPhi1
+	r1 := wgs84A * (1 - e2) / math.Pow(1-e2*sinPhi1*sinPhi1, 1.5)
+	d := x / (n1 * utmScale)
+	phi := phi1 - (n1*math.Tan(phi1)/r1)*(d*d/2-
+		(5+3*t1+10*c1-4*c1*c1-9*wgs84EP2)*math.Pow(d, 4)/24+
+		(61+90*t1+298*c1+45*t1*t1-252*wgs84EP2-3*c1*c1)*math.Pow(d, 6)/720)
+	lambda := (d - (1+2*t1+c1)*math.Pow(d, 3)/6 +
+		(5-2*c1+28*t1-3*c1*c1+8*wgs84EP2+24*t1*t1)*math.Pow(d, 5)/120) / cosPhi1
+
+	p := Position{Lat: degrees(phi), Lng: normalizeLng(centralMeridian(u.Zone) + degrees(lambda))}
+	if err := p.Validate(); err != nil {
+		return Position{}, err
+	}
+	return p, nil
+}
+
+// ParseUTM parses a UTM coordinate written as zone and band followed
+// by easting and northing in meters, e.g. "29U 467000 5966990" or
+// "29U 467000mE 5966990mN".
+func ParseUTM(s string) (UTM, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 4 {
+		// The band may be separated from the zone.
+		fields = []string{fields[0] + fields[1], fields[2], fields[3]}
+	}
+	if len(fields) != 3 {
+		return UTM{}, fmt.Errorf("parsing UTM %q: want zone, easting and northing", s)
+	}
+	zone, band, err := parseZone(fields[0])
+	if err != nil {
+		return UTM{}, fmt.Errorf("parsing UTM %q: %w", s, err)
+	}
+	u := UTM{Zone: zone, Band: band}
+	for i, v := range []*float64{&u.Easting, &u.Northing} {
+		f := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(fields[i+1], "E"), "N"), "m")
+		if *v, err = strconv.ParseFloat(f, 64); err != nil || *v < 0 {
+			return UTM{}, fmt.Errorf("parsing UTM %q: invalid meters %q", s, fields[i+1])
+		}
+	}
+	return u, nil
+}
+
+// parseZone parses a zone number followed by a band letter, e.g. "29U".
+func parseZone(s string) (int, byte, error) {
+	i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) })
+	if i <= 0 || i > 2 {
+		return 0, 0, fmt.Errorf("invalid zone %q", s)
+	}
+	zone, _ := strconv.Atoi(s[:i])
+	band := unicode.ToUpper(rune(s[i]))
+	if zone < 1 || zone > 60 || !strings.ContainsRune(utmBands, band) {
+		return 0, 0, fmt.Errorf("invalid zone %q", s[:i+1])
+	}
+	return zone, byte(band), nil
+}
+
+// MGRS letters of the 100 km squares. Column letters cycle through
+// three sets by zone; row letters repeat every 2,000 km and start
+// at F in even zones.
+const (
+	mgrsColumns = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	mgrsRows    = "ABCDEFGHJKLMNPQRSTUV"
+)
+
+// MGRS returns the Military Grid Reference System reference of p
+// with the given number of digits for each of easting and northing,
+// clamped to 0..5, e.g. "18SUJ2348306479" with 5 digits (1 m).
+// Like UTM, MGRS does not cover the polar regions.
+func (p Position) MGRS(digits int) (string, error) {
+	u, err := p.UTM()
+	if err != nil {
+		return "", err
+	}
+	digits = min(max(digits, 0), 5)
+	col := int(u.Easting / 100000)
+	row := int(math.Mod(u.Northing, 2000000) / 100000)
+	colLetter := mgrsColumns[(u.Zone-1)%3*8+col-1]
+	rowLetter := mgrsRows[(row+(1-u.Zone%2)*5)%len(mgrsRows)]
+	// Truncate, not round, so the reference names the square holding p.
+	scale := math.Pow(10, float64(5-digits))
+	e := int(math.Mod(u.Easting, 100000) / scale)
+	n := int(math.Mod(u.Northing, 100000) / scale)
+	if digits == 0 {
+		return fmt.Sprintf("%d%c%c%c", u.Zone, u.Band, colLetter, rowLetter), nil
+	}
+	return fmt.Sprintf("%d%c%c%c%0*d%0*d", u.Zone, u.Band, colLetter, rowLetter, digits, e, digits, n), nil
+}
+
+// ParseMGRS parses a Military Grid Reference System reference, with or
+// without spaces, e.g. "18SUJ2348306479" or "18S UJ 234 064". It
+// returns the center of the referenced grid square.
+func ParseMGRS(s string) (Position, error) {
+	ref := strings.ToUpper(strings.Join(strings.Fields(s), ""))
+	i := strings.IndexFunc(ref, func(r rune) bool { return !unicode.IsDigit(r) })
+	if i < 0 || len(ref) < i+3 {
+		return Position{}, fmt.Errorf("parsing MGRS %q: too short", s)
+	}
+	zone, band, err := parseZone(ref[:i+1])
+	if err != nil {
+		return Position{}, fmt.Errorf("parsing MGRS %q: %w", s, err)
+	}
+	set := (zone - 1) % 3 * 8
+	col := strings.IndexByte(mgrsColumns[set:set+8], ref[i+1]) + 1
+	row := strings.IndexByte(mgrsRows, ref[i+2])
+	if col == 0 || row < 0 {
+		return Position{}, fmt.Errorf("parsing MGRS %q: invalid square %q", s, ref[i+1:i+3])
+	}
+	row = (row - (1-zone%2)*5 + len(mgrsRows)) % len(mgrsRows)
+
+	digits := ref[i+3:]
+	if len(digits)%2 != 0 || len(digits) > 10 || strings.ContainsFunc(digits, func(r rune) bool { return !unicode.IsDigit(r) }) {
+		return Position{}, fmt.Errorf("parsing MGRS %q: invalid digits %q", s, digits)
+	}
+	half := len(digits) / 2
+	size := math.Pow(10, float64(5-half))
+	var e, n float64
+	if half > 0 {
+		ev, _ := strconv.Atoi(digits[:half])
+		nv, _ := strconv.Atoi(digits[half:])
+		e, n = float64(ev)*size, float64(nv)*size
+	}
+
+	// The row letters repeat every 2,000 km; pick the lowest
+	// 100 km square reaching into the latitude band.
+	square := float64(row) * 100000
+	for minNorthing := bandMinNorthing(zone, band); square+100000 <= minNorthing; {
+		square += 2000000
+	}
+	u := UTM{
+		Zone:     zone,
+		Band:     band,
+		Easting:  float64(col)*100000 + e + size/2,
+		Northing: square + n + size/2,
+	}
+	return u.Position()
+}
+
+// bandMinNorthing returns the smallest northing in meters
+// of the southern edge of a latitude band in a zone.
+func bandMinNorthing(zone int, band byte) float64 {
+	lat := float64(strings.IndexByte(utmBands, band))*8 - 80
+	res := math.Inf(1)
+	// Parallels bow towards the pole away from the central meridian,
+	// so the southern edge is lowest at the meridian in the north and
+	// at the zone boundary in the south.
+	for _, dLng := range []float64{0, 3} {
+		_, n := transverseMercator(radians(lat), radians(dLng))
+		if lat < 0 {
+			n += utmFalseNorthing
+		}
+		res = math.Min(res, n)
+	}
+	return res
+}
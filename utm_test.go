@@ -0,0 +1,180 @@
+package geonames_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/qba73/geonames"
+)
+
+// washingtonMonument is 38°53′22.08″N 77°2′6.86″W, the example
+// position of the MGRS article of Wikipedia, 18SUJ2348306479.
+var washingtonMonument = geonames.Position{Lat: 38 + 53.0/60 + 22.08/3600, Lng: -(77 + 2.0/60 + 6.86/3600)}
+
+func TestPositionUTM_ConvertsToZoneAndMeters(t *testing.T) {
+	t.Parallel()
+
+	u, err := washingtonMonument.UTM()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Zone != 18 || u.Band != 'S' || !near(u.Easting, 323483, 2) || !near(u.Northing, 4306479, 2) {
+		t.Errorf("want 18S 323483 4306479, got %v", u)
+	}
+	if got := u.String(); got != "18S 323483 4306479" {
+		t.Errorf("want 18S 323483 4306479, got %q", got)
+	}
+}
+
+func TestPositionUTM_RoundTrips(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []geonames.Position{
+		castlebar,
+		dublin,
+		{Lat: -33.8568, Lng: 151.2153},
+		{Lat: -79.9, Lng: -179.9},
+		{Lat: 0, Lng: 0},
+		{Lat: 60.39, Lng: 5.32},  // Bergen, zone 32V
+		{Lat: 78.22, Lng: 15.65}, // Longyearbyen, zone 33X
+		{Lat: 83.9, Lng: 179.99},
+	} {
+		u, err := p.UTM()
+		if err != nil {
+			t.Errorf("%v: %v", p, err)
+			continue
+		}
+		back, err := u.Position()
+		if err != nil {
+			t.Errorf("%v: %v", u, err)
+			continue
+		}
+		if !near(back.Lat, p.Lat, 1e-6) || !near(back.Lng, p.Lng, 1e-6) {
+			t.Errorf("%v: round trip through %v gives %v", p, u, back)
+		}
+	}
+}
+
+func TestPositionUTM_UsesNorwayAndSvalbardZones(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		p    geonames.Position
+		zone int
+	}{
+		{geonames.Position{Lat: 60.39, Lng: 5.32}, 32},
+		{geonames.Position{Lat: 78.22, Lng: 15.65}, 33},
+		{geonames.Position{Lat: 78.22, Lng: 8}, 31},
+	} {
+		u, err := tc.p.UTM()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Zone != tc.zone {
+			t.Errorf("%v: want zone %d, got %d", tc.p, tc.zone, u.Zone)
+		}
+	}
+}
+
+func TestPositionUTM_RejectsPolarRegions(t *testing.T) {
+	t.Parallel()
+
+	if _, err := (geonames.Position{Lat: 85, Lng: 0}).UTM(); !errors.Is(err, geonames.ErrInvalidPosition) {
+		t.Errorf("want ErrInvalidPosition, got %v", err)
+	}
+}
+
+func TestParseUTM_AcceptsCommonNotations(t *testing.T) {
+	t.Parallel()
+
+	want := geonames.UTM{Zone: 29, Band: 'U', Easting: 467000, Northing: 5966990}
+	for _, in := range []string{"29U 467000 5966990", "29 U 467000 5966990", "29u 467000mE 5966990mN"} {
+		got, err := geonames.ParseUTM(in)
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+		} else if got != want {
+			t.Errorf("%q: want %v, got %v", in, want, got)
+		}
+	}
+	for _, in := range []string{"", "29 467000 5966990", "61U 467000 5966990", "29U east north"} {
+		if _, err := geonames.ParseUTM(in); err == nil {
+			t.Errorf("%q: want error", in)
+		}
+	}
+}
+
+func TestPositionMGRS_MatchesReference(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		digits int
+		want   string
+	}{
+		{0, "18SUJ"},
+		{1, "18SUJ20"},
+		{3, "18SUJ234064"},
+	}
+	for _, tc := range tests {
+		got, err := washingtonMonument.MGRS(tc.digits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("%d digits: want %q, got %q", tc.digits, tc.want, got)
+		}
+	}
+}
+
+func TestParseMGRS_ReturnsCenterOfSquare(t *testing.T) {
+	t.Parallel()
+
+	got, err := geonames.ParseMGRS("18S UJ 23483 06479")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := got.DistanceTo(washingtonMonument); d > 3 {
+		t.Errorf("want within 3 m of the monument, got %v, %.1f m away", got, d)
+	}
+	got, err = geonames.ParseMGRS("18SUJ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := got.DistanceTo(washingtonMonument); d > 71000 {
+		t.Errorf("want center of the 100 km square, got %v, %.0f m away", got, d)
+	}
+	for _, in := range []string{"", "18S", "18SIJ", "18SUJ123", "18SUJ12a4"} {
+		if _, err := geonames.ParseMGRS(in); err == nil {
+			t.Errorf("%q: want error", in)
+		}
+	}
+}
+
+func TestPositionMGRS_RoundTrips(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []geonames.Position{
+		castlebar,
+		{Lat: -33.8568, Lng: 151.2153},
+		{Lat: -54.8, Lng: -68.3},
+		{Lat: 0.0001, Lng: 33},
+		{Lat: -0.0001, Lng: 33},
+		{Lat: 60.39, Lng: 5.32},
+		{Lat: 78.22, Lng: 15.65},
+		{Lat: 47.9, Lng: -122.2},
+		{Lat: 72.1, Lng: -40},
+	} {
+		ref, err := p.MGRS(5)
+		if err != nil {
+			t.Errorf("%v: %v", p, err)
+			continue
+		}
+		back, err := geonames.ParseMGRS(ref)
+		if err != nil {
+			t.Errorf("%v: %q: %v", p, ref, err)
+			continue
+		}
+		if d := back.DistanceTo(p); d > 1.5 {
+			t.Errorf("%v: round trip through %q is %.1f m off", p, ref, d)
+		}
+	}
+}
@@ -2,25 +2,39 @@ package geonames
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"iter"
 	"net/url"
 	"strconv"
 )
 
 type wikipediaResponse struct {
-	Geonames []struct {
-		Summary      string  `json:"summary"`
-		Elevation    int     `json:"elevation"`
-		GeoNameID    int     `json:"geoNameId,omitempty"`
-		Lng          float64 `json:"lng"`
-		CountryCode  string  `json:"countryCode"`
-		Rank         int     `json:"rank"`
-		Lang         string  `json:"lang"`
-		Title        string  `json:"title"`
-		Lat          float64 `json:"lat"`
-		WikipediaURL string  `json:"wikipediaUrl"`
-		Feature      string  `json:"feature,omitempty"`
-	} `json:"geonames"`
+	apiStatus
+	Geonames []wikipediaResult `json:"geonames"`
+}
+
+type wikipediaResult struct {
+	Summary      string  `json:"summary"`
+	Elevation    int     `json:"elevation"`
+	GeoNameID    int     `json:"geoNameId,omitempty"`
+	Lng          float64 `json:"lng"`
+	CountryCode  string  `json:"countryCode"`
+	Rank         int     `json:"rank"`
+	Lang         string  `json:"lang"`
+	Title        string  `json:"title"`
+	Lat          float64 `json:"lat"`
+	WikipediaURL string  `json:"wikipediaUrl"`
+	Feature      string  `json:"feature,omitempty"`
+}
+
+func (r *wikipediaResponse) decodeField(dec *json.Decoder, key string) (bool, error) {
+	if key == "geonames" {
+		return true, decodeArray(dec, func(g wikipediaResult) {
+			r.Geonames = append(r.Geonames, g)
+		})
+	}
+	return r.decodeStatus(dec, key)
 }
 
 // Geoname represents a name for a place retrieved from Wikipedia.
@@ -74,6 +88,35 @@ func (c Client) GetPlace(ctx context.Context, name, country string, maxResults i
 	return geonames, nil
 }
 
+// maxWikipediaRows is the largest number of results
+// the Wikipedia search service returns.
+const maxWikipediaRows = 500
+
+// SearchWikipediaAll returns an iterator over Wikipedia articles
+// matching the place name and country code.
+//
+// The Wikipedia search service does not page its results, so a single
+// request for up to maxResults articles is sent when iteration starts.
+// A maxResults of zero or more than 500 is capped at 500. An error is
+// yielded once and ends the iteration.
+func (c Client) SearchWikipediaAll(ctx context.Context, name, country string, maxResults int) iter.Seq2[Geoname, error] {
+	return func(yield func(Geoname, error) bool) {
+		if maxResults <= 0 || maxResults > maxWikipediaRows {
+			maxResults = maxWikipediaRows
+		}
+		geonames, err := c.GetPlace(ctx, name, country, maxResults)
+		if err != nil {
+			yield(Geoname{}, err)
+			return
+		}
+		for _, g := range geonames {
+			if !yield(g, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (c Client) buildWikiURL(place, country string, maxResults int) (string, error) {
 	params := url.Values{
 		"q":           []string{place},
@@ -94,5 +137,9 @@ func (c Client) buildWikiURL(place, country string, maxResults int) (string, err
 // GetPlace takes place name, country and max results and returns
 // a list of names associated with the place.
 func GetPlace(name, country string, maxResults int) ([]Geoname, error) {
-	return ClientFromEnv.GetPlace(context.Background(), name, country, maxResults)
+	c, err := DefaultClient()
+	if err != nil {
+		return nil, err
+	}
+	return c.GetPlace(context.Background(), name, country, maxResults)
 }
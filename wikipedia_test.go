@@ -12,7 +12,7 @@ import (
 func TestGetPlace_RetrievesSingleGeoNameOnValidInput(t *testing.T) {
 	t.Parallel()
 
-	testFile := "testdata/response-geoname-wikipedia-single.json"
+	testFile := "geonamestest/fixtures/wikipedia-single.json"
 	wantReqURI := "/wikipediaSearchJSON?q=Castlebar&title=Castlebar&countryCode=IE&maxRows=1&username=DummyUser"
 	ts := newTestServer(testFile, wantReqURI, t)
 	defer ts.Close()
@@ -50,3 +50,26 @@ func TestGetPlace_RetrievesSingleGeoNameOnValidInput(t *testing.T) {
 		t.Error(cmp.Diff(want, got))
 	}
 }
+
+func TestSearchWikipediaAll_YieldsEachGeoname(t *testing.T) {
+	t.Parallel()
+
+	testFile := "geonamestest/fixtures/wikipedia.json"
+	wantReqURI := "/wikipediaSearchJSON?q=Dublin&title=Dublin&countryCode=IE&maxRows=500&username=DummyUser"
+	ts := newTestServer(testFile, wantReqURI, t)
+	defer ts.Close()
+
+	client := geonames.NewClient("DummyUser")
+	client.BaseURL = ts.URL
+
+	var titles []string
+	for g, err := range client.SearchWikipediaAll(context.Background(), "Dublin", "IE", 0) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		titles = append(titles, g.Title)
+	}
+	if len(titles) == 0 {
+		t.Fatal("want geonames, got none")
+	}
+}